@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"github.com/kstsm/pvz-service/config"
+	"github.com/kstsm/pvz-service/database"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/cache"
+	"github.com/kstsm/pvz-service/internal/logger"
+	"github.com/kstsm/pvz-service/internal/repository"
+	"github.com/kstsm/pvz-service/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// newPVZListCache создаёт кэш списка ПВЗ по конфигурации. Для CACHE_BACKEND=none
+// возвращает nil. Возвращаемая функция освобождает соединение с Redis.
+func newPVZListCache(ctx context.Context, cfg config.Cache) (cache.Cache, func()) {
+	switch cfg.Backend {
+	case "memory":
+		return cache.NewLRU(cfg.LRUSize), func() {}
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		if err := client.Ping(ctx).Err(); err != nil {
+			logger.Fatal("Не удалось подключиться к Redis", "addr", cfg.RedisAddr, "error", err)
+		}
+
+		return cache.NewRedis(client), func() { client.Close() }
+	default:
+		return nil, func() {}
+	}
+}
+
+// newCLIService собирает сервис для команд администрирования. Кэши списка ПВЗ
+// и смен пароля подключаются только общие, в Redis: так изменения из командной
+// строки сбрасывают их и у работающих серверов.
+func newCLIService(ctx context.Context, cfg config.Config) (*service.Service, func()) {
+	conn := database.InitPostgres(ctx, cfg.Postgres)
+	repo := repository.NewRepository(conn,
+		repository.WithTxRetry(cfg.Postgres.TxRetryAttempts, cfg.Postgres.TxRetryBackoff))
+
+	opts := []service.Option{
+		service.WithTokens(auth.NewTokens(cfg.JWT.JWTSecret)),
+		service.WithPasswordPolicy(passwordPolicy(cfg.Auth)),
+	}
+	closeCache := func() {}
+	if cfg.Cache.Backend == "redis" {
+		var pvzListCache cache.Cache
+		pvzListCache, closeCache = newPVZListCache(ctx, cfg.Cache)
+		opts = append(opts, service.WithPVZListCache(pvzListCache, cfg.Cache.TTL))
+		if cfg.Auth.RevocationCacheTTL > 0 {
+			opts = append(opts, service.WithPasswordChangeCache(pvzListCache, cfg.Auth.RevocationCacheTTL))
+		}
+	}
+
+	return service.NewService(repo, opts...), func() {
+		closeCache()
+		conn.Close()
+	}
+}
+
+func passwordPolicy(cfg config.Auth) auth.PasswordPolicy {
+	return auth.PasswordPolicy{
+		MinLength:        cfg.PasswordMinLength,
+		RequireLetter:    cfg.PasswordRequireLetter,
+		RequireDigit:     cfg.PasswordRequireDigit,
+		RequireMixedCase: cfg.PasswordRequireMixedCase,
+		RequireSpecial:   cfg.PasswordRequireSpecial,
+	}
+}
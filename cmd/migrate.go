@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/kstsm/pvz-service/database"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/migrations"
+)
+
+func runMigrate(args []string) error {
+	cfg, fs, err := parseFlags("migrate", args, nil)
+	if err != nil {
+		return err
+	}
+	if err = noArgs(fs); err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	conn := database.InitPostgres(ctx, cfg.Postgres.Migrator())
+	defer conn.Close()
+
+	applied, err := database.Migrate(tenant.WithSystem(ctx), conn, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	// Роль приложения создаётся владельцем схемы. Если владелец не задан
+	// отдельно, сервер работает под той же ролью и создавать нечего.
+	if cfg.Postgres.MigrateUsername != "" && cfg.Postgres.MigrateUsername != cfg.Postgres.Username {
+		if err = database.EnsureAppLogin(ctx, conn, cfg.Postgres.Username, cfg.Postgres.Password); err != nil {
+			return err
+		}
+		fmt.Println("Роль приложения", cfg.Postgres.Username, "готова")
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("Схема актуальна, новых миграций нет")
+		return nil
+	}
+	for _, version := range applied {
+		fmt.Println("Применена миграция", version)
+	}
+
+	return nil
+}
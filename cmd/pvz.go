@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/kstsm/pvz-service/internal/handler"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/spf13/pflag"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pvzCSVColumns — колонки CSV для импорта ПВЗ. Обязательна только city,
+// порядок колонок задаётся строкой заголовка.
+var pvzCSVColumns = []string{"city", "address", "latitude", "longitude", "timezone"}
+
+type pvzImportRow struct {
+	line   int
+	city   string
+	update models.UpdatePVZRequest
+}
+
+func runPVZImport(args []string) error {
+	var tenantSlug string
+	cfg, fs, err := parseFlags("pvz import", args, func(fs *pflag.FlagSet) {
+		fs.StringVar(&tenantSlug, "tenant", "", "арендатор (по умолчанию default)")
+	})
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("ожидается один аргумент: путь к CSV-файлу")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Файл проверяется целиком до записи в базу, чтобы ошибка в середине
+	// не оставила импорт выполненным наполовину.
+	rows, err := parsePVZCSV(file)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	svc, closeService := newCLIService(ctx, cfg)
+	defer closeService()
+
+	t, err := svc.GetTenant(ctx, tenantSlug)
+	if err != nil {
+		return err
+	}
+	ctx = tenant.WithID(ctx, t.ID)
+
+	// Все ПВЗ заводятся в одной транзакции: при ошибке база не меняется
+	// и файл можно исправить и импортировать заново.
+	items := make([]models.PVZImportItem, len(rows))
+	for i, row := range rows {
+		items[i] = models.PVZImportItem{City: row.city, Params: row.update}
+	}
+	imported, err := svc.ImportPVZ(ctx, items)
+	if err != nil {
+		return fmt.Errorf("CSV не импортирован, база не изменена: %w", err)
+	}
+
+	fmt.Printf("Импортировано ПВЗ: %d\n", len(imported))
+
+	return nil
+}
+
+// parsePVZCSV читает и проверяет CSV с ПВЗ и возвращает все ошибки сразу
+// с номерами строк.
+func parsePVZCSV(r io.Reader) ([]pvzImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать заголовок CSV: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(pvzCSVColumns, name) {
+			return nil, fmt.Errorf("неизвестная колонка %q, допустимые: %v", name, pvzCSVColumns)
+		}
+		index[name] = i
+	}
+	if _, ok := index["city"]; !ok {
+		return nil, errors.New("в CSV нет обязательной колонки city")
+	}
+
+	var rows []pvzImportRow
+	var errs []error
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		row, err := parsePVZRecord(record, index)
+		if err == nil {
+			err = handler.ValidatePVZ(row.city, row.update)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("строка %d: %w", line, err))
+			continue
+		}
+
+		row.line = line
+		rows = append(rows, row)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("CSV не импортирован:\n%w", errors.Join(errs...))
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("в CSV нет ни одного ПВЗ")
+	}
+
+	return rows, nil
+}
+
+func parsePVZRecord(record []string, index map[string]int) (pvzImportRow, error) {
+	field := func(name string) string {
+		if i, ok := index[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row := pvzImportRow{city: field("city")}
+
+	if address := field("address"); address != "" {
+		row.update.Address = &address
+	}
+	if timezone := field("timezone"); timezone != "" {
+		row.update.Timezone = &timezone
+	}
+
+	for _, coordinate := range []struct {
+		name string
+		dst  **float64
+	}{
+		{"latitude", &row.update.Latitude},
+		{"longitude", &row.update.Longitude},
+	} {
+		raw := field(coordinate.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return pvzImportRow{}, fmt.Errorf("некорректное значение %s: %q", coordinate.name, raw)
+		}
+		*coordinate.dst = &value
+	}
+
+	return row, nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParsePVZCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    int
+		wantErr []string
+	}{
+		{
+			name: "Только город",
+			csv:  "city\nМосква\nКазань\n",
+			want: 2,
+		},
+		{
+			name: "Все колонки в произвольном порядке",
+			csv: "timezone,city,latitude,longitude,address\n" +
+				"Europe/Moscow,Москва,55.75,37.61,\"Тверская, 1\"\n" +
+				",Санкт-Петербург,,,\n",
+			want: 2,
+		},
+		{
+			name:    "Нет колонки city",
+			csv:     "address\nТверская, 1\n",
+			wantErr: []string{"city"},
+		},
+		{
+			name:    "Неизвестная колонка",
+			csv:     "city,phone\nМосква,123\n",
+			wantErr: []string{"phone"},
+		},
+		{
+			name: "Ошибки перечисляются с номерами строк",
+			csv: "city,latitude,longitude,timezone\n" +
+				"Москва,55.75,37.61,Europe/Moscow\n" +
+				"Тула,,,\n" +
+				"Казань,abc,49.1,\n" +
+				"Казань,55.79,,\n" +
+				"Москва,,,Mars/Olympus\n",
+			wantErr: []string{"строка 3:", "строка 4:", "строка 5:", "строка 6:"},
+		},
+		{
+			name:    "Часовой пояс сервера",
+			csv:     "city,timezone\nМосква,Local\n",
+			wantErr: []string{"строка 2:", "Local"},
+		},
+		{
+			name:    "Пустой файл",
+			csv:     "city\n",
+			wantErr: []string{"нет ни одного ПВЗ"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parsePVZCSV(strings.NewReader(tt.csv))
+			if len(tt.wantErr) > 0 {
+				require.Error(t, err)
+				for _, want := range tt.wantErr {
+					assert.Contains(t, err.Error(), want)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, rows, tt.want)
+		})
+	}
+}
+
+func TestParsePVZCSVFields(t *testing.T) {
+	rows, err := parsePVZCSV(strings.NewReader("city,address,latitude,longitude,timezone\n" +
+		"Москва,\"Тверская, 1\",55.75,37.61,Europe/Moscow\n"))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	row := rows[0]
+	assert.Equal(t, 2, row.line)
+	assert.Equal(t, "Москва", row.city)
+	assert.Equal(t, "Тверская, 1", *row.update.Address)
+	assert.Equal(t, 55.75, *row.update.Latitude)
+	assert.Equal(t, 37.61, *row.update.Longitude)
+	assert.Equal(t, "Europe/Moscow", *row.update.Timezone)
+}
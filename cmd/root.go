@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kstsm/pvz-service/config"
+	"github.com/kstsm/pvz-service/internal/logger"
+	"github.com/spf13/pflag"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+const usage = `Использование: pvz-service [команда] [флаги]
+
+Команды:
+  serve                       запустить сервер (по умолчанию)
+  migrate                     применить миграции базы данных
+  user create                 создать пользователя, например первого модератора
+  user set-password           задать пользователю новый пароль
+  pvz import <файл.csv>       завести ПВЗ из CSV-файла
+  token mint                  выпустить JWT для локальной проверки API
+  config print                вывести действующую конфигурацию со скрытыми секретами
+
+Параметры конфигурации задаются файлом (--config, по умолчанию .env),
+переменными окружения или флагами; флаг важнее переменной, переменная — файла.
+Флаги команды: pvz-service <команда> --help.
+`
+
+// Execute разбирает аргументы командной строки и выполняет команду.
+func Execute(args []string) error {
+	command, rest := "serve", args
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, rest = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		cfg, _, err := parseFlags("serve", rest, nil)
+		if err != nil {
+			return err
+		}
+		return Serve(cfg)
+	case "migrate":
+		return runMigrate(rest)
+	case "user":
+		return subcommand("user", rest, map[string]func([]string) error{
+			"create":       runUserCreate,
+			"set-password": runUserSetPassword,
+		})
+	case "pvz":
+		return subcommand("pvz", rest, map[string]func([]string) error{
+			"import": runPVZImport,
+		})
+	case "token":
+		return subcommand("token", rest, map[string]func([]string) error{
+			"mint": runTokenMint,
+		})
+	case "config":
+		return subcommand("config", rest, map[string]func([]string) error{
+			"print": runConfigPrint,
+		})
+	case "help":
+		fmt.Print(usage)
+		return nil
+	default:
+		return fmt.Errorf("неизвестная команда %q\n\n%s", command, usage)
+	}
+}
+
+func subcommand(command string, args []string, subcommands map[string]func([]string) error) error {
+	if len(args) > 0 {
+		if run, ok := subcommands[args[0]]; ok {
+			return run(args[1:])
+		}
+	}
+
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, command+" "+name)
+	}
+	slices.Sort(names)
+	return fmt.Errorf("ожидается подкоманда: %s", strings.Join(names, ", "))
+}
+
+// parseFlags разбирает флаги команды вместе с параметрами конфигурации и
+// собирает по ним конфигурацию. define добавляет флаги самой команды.
+func parseFlags(name string, args []string, define func(fs *pflag.FlagSet)) (config.Config, *pflag.FlagSet, error) {
+	commandFlags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	if define != nil {
+		define(commandFlags)
+	}
+	configFlags := config.Flags(name)
+
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fs.AddFlagSet(commandFlags)
+	fs.AddFlagSet(configFlags)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			fmt.Printf("Использование: pvz-service %s [флаги]\n", name)
+			if commandFlags.HasFlags() {
+				fmt.Printf("\nФлаги команды:\n%s", commandFlags.FlagUsages())
+			}
+			fmt.Printf("\nПараметры конфигурации:\n%s", configFlags.FlagUsages())
+			os.Exit(0)
+		}
+		return config.Config{}, nil, err
+	}
+
+	cfg, err := config.Load(fs)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+	if err = logger.Setup(cfg.Log); err != nil {
+		return config.Config{}, nil, err
+	}
+
+	return cfg, fs, nil
+}
+
+// noArgs проверяет, что после флагов не осталось лишних аргументов.
+func noArgs(fs *pflag.FlagSet) error {
+	if fs.NArg() > 0 {
+		return fmt.Errorf("лишние аргументы: %v", fs.Args())
+	}
+	return nil
+}
+
+// signalContext отменяется по SIGINT или SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// readPassword возвращает пароль из флага, а если он не задан — первую
+// строку стандартного ввода, чтобы пароль не оставался в истории команд.
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("не удалось прочитать пароль: %w", err)
+	}
+	password = strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("пароль не задан: укажите --password или передайте его на стандартный ввод")
+	}
+
+	return password, nil
+}
+
+func runConfigPrint(args []string) error {
+	cfg, fs, err := parseFlags("config print", args, nil)
+	if err != nil {
+		return err
+	}
+	if err = noArgs(fs); err != nil {
+		return err
+	}
+
+	return cfg.Print(os.Stdout)
+}
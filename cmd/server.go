@@ -2,58 +2,164 @@ package cmd
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"github.com/gookit/slog"
 	"github.com/kstsm/pvz-service/config"
 	"github.com/kstsm/pvz-service/database"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/cache"
 	"github.com/kstsm/pvz-service/internal/handler"
+	"github.com/kstsm/pvz-service/internal/health"
+	"github.com/kstsm/pvz-service/internal/lifecycle"
+	"github.com/kstsm/pvz-service/internal/mailer"
 	"github.com/kstsm/pvz-service/internal/repository"
+	"github.com/kstsm/pvz-service/internal/scheduler"
 	"github.com/kstsm/pvz-service/internal/service"
+	"github.com/kstsm/pvz-service/internal/storage"
+	"github.com/kstsm/pvz-service/internal/telemetry"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/migrations"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 )
 
-var cfg = config.Config
-
-func Run() {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+// Serve запускает HTTP-сервер и фоновые задачи до получения SIGINT или SIGTERM.
+// При завершении /readyz сразу начинает отвечать 503, затем после паузы
+// SRV_DRAIN_DELAY останавливаются по очереди HTTP-сервер, фоновые задачи,
+// кэш и пул соединений, каждый в пределах своего таймаута.
+func Serve(cfg config.Config) error {
+	ctx, stop := signalContext()
 	defer stop()
 
-	conn := database.InitPostgres(ctx)
-	defer conn.Close()
+	app := lifecycle.New(cfg.Server.DrainDelay)
 
-	repo := repository.NewRepository(conn)
-	svc := service.NewService(repo)
-	router := handler.NewHandler(ctx, svc)
+	shutdownTracing, err := telemetry.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("не удалось настроить трассировку: %w", err)
+	}
+	app.Add(lifecycle.Component{Name: "tracing", Stop: shutdownTracing, StopTimeout: cfg.Server.ShutdownTimeout})
 
-	srv := &http.Server{
-		Addr:    fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
-		Handler: router.NewRouter(),
+	conn := database.InitPostgres(ctx, cfg.Postgres)
+	app.Add(closeComponent("postgres", conn.Close, cfg.Postgres.CloseTimeout))
+
+	// Под ролью, которая обходит row-level security, арендаторы увидели бы
+	// данные друг друга.
+	if err = database.CheckAppLogin(ctx, conn); err != nil {
+		return err
 	}
 
-	errChan := make(chan error, 1)
+	repoOpts := []repository.Option{
+		repository.WithTxRetry(cfg.Postgres.TxRetryAttempts, cfg.Postgres.TxRetryBackoff),
+	}
+	if replica := database.InitReplica(ctx, cfg.Postgres); replica != nil {
+		app.Add(closeComponent("postgres-replica", replica.Close, cfg.Postgres.CloseTimeout))
+		repoOpts = append(repoOpts, repository.WithReplica(replica))
+	}
+
+	blobs, err := storage.NewLocalStorage(cfg.Storage.LocalDir)
+	if err != nil {
+		return fmt.Errorf("не удалось подготовить хранилище вложений: %w", err)
+	}
 
-	go func() {
-		slog.Info("Запуск сервера", "host", cfg.Server.Host, "port", cfg.Server.Port)
-		errChan <- srv.ListenAndServe()
-	}()
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		return fmt.Errorf("не удалось подготовить отправку писем: %w", err)
+	}
+
+	opts := []service.Option{
+		service.WithBlobStorage(blobs),
+		service.WithStoragePeriods(cfg.Expiry.StoragePeriods),
+		service.WithMaxReceptionDuration(cfg.Reception.MaxDuration),
+		service.WithReopenGracePeriod(cfg.Reception.ReopenGracePeriod),
+		service.WithPasswordPolicy(passwordPolicy(cfg.Auth)),
+		service.WithMailer(mail),
+		service.WithUserTokenTTL(cfg.Auth.EmailVerificationTTL, cfg.Auth.PasswordResetTTL),
+	}
 
-	select {
-	case <-ctx.Done():
-		slog.Info("Завершаем сервер...")
-	case err := <-errChan:
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Fatal("Ошибка при запуске сервера", "error", err)
+	pvzListCache, closeCache := newPVZListCache(ctx, cfg.Cache)
+	app.Add(closeComponent("cache", closeCache, 0))
+	if pvzListCache != nil {
+		opts = append(opts, service.WithPVZListCache(pvzListCache, cfg.Cache.TTL))
+	}
+	if pvzListCache != nil && cfg.Auth.RevocationCacheTTL > 0 {
+		// В памяти у смен пароля свой LRU, чтобы записи о пользователях
+		// не вытесняли списки ПВЗ.
+		passwordChanges := pvzListCache
+		if cfg.Cache.Backend == "memory" {
+			passwordChanges = cache.NewLRU(cfg.Cache.LRUSize)
 		}
+		opts = append(opts, service.WithPasswordChangeCache(passwordChanges, cfg.Auth.RevocationCacheTTL))
+	}
+
+	tokens := auth.NewTokens(cfg.JWT.JWTSecret)
+	opts = append(opts, service.WithTokens(tokens))
+
+	repo := repository.NewRepository(conn, repoOpts...)
+	svc := service.NewService(repo, opts...)
+	// Письма, начатые в фоне, дописываются до закрытия базы.
+	app.Add(lifecycle.Component{Name: "mail", Stop: svc.WaitBackground, StopTimeout: cfg.Server.ShutdownTimeout})
+
+	// Фоновые задачи обрабатывают данные всех арендаторов.
+	jobs := scheduler.New(
+		scheduler.Job{
+			Name:     "expire-products",
+			Interval: cfg.Expiry.CheckInterval,
+			Run: func(ctx context.Context) error {
+				_, err := svc.ExpireProducts(tenant.WithSystem(ctx))
+				return err
+			},
+		},
+		scheduler.Job{
+			Name:     "flag-stale-receptions",
+			Interval: cfg.Reception.StaleCheckInterval,
+			Run: func(ctx context.Context) error {
+				_, err := svc.FlagStaleReceptions(tenant.WithSystem(ctx))
+				return err
+			},
+		},
+	)
+	app.Add(lifecycle.Component{
+		Name: "scheduler",
+		Start: func(ctx context.Context) error {
+			jobs.Start(ctx)
+			return nil
+		},
+		Stop:        jobs.Stop,
+		StopTimeout: cfg.Scheduler.ShutdownTimeout,
+	})
+
+	readiness := health.NewReadiness(
+		health.Check{Name: "postgres", Run: conn.Ping},
+		health.Check{Name: "migrations", Run: func(ctx context.Context) error {
+			pending, err := database.PendingMigrations(ctx, conn, migrations.FS)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("не применены миграции: %v", pending)
+			}
+			return nil
+		}},
+	)
+	app.OnShutdown(readiness.Drain)
+
+	router := handler.NewHandler(svc, tokens, readiness)
+	srv := &http.Server{
+		Addr:    fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
+		Handler: router.NewRouter(),
 	}
+	app.Add(lifecycle.HTTPServer(srv, cfg.Server.ShutdownTimeout, app.Fail))
+
+	return app.Run(ctx)
+}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		slog.Error("Ошибка при завершении сервера", "error", err)
+// closeComponent превращает функцию закрытия без контекста в компонент с таймаутом.
+func closeComponent(name string, closeFn func(), timeout time.Duration) lifecycle.Component {
+	return lifecycle.Component{
+		Name: name,
+		Stop: func(context.Context) error {
+			closeFn()
+			return nil
+		},
+		StopTimeout: timeout,
 	}
 }
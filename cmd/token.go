@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/spf13/pflag"
+	"slices"
+)
+
+// runTokenMint выпускает токен без обращения к базе, если арендатор не задан
+// или задан арендатор по умолчанию.
+func runTokenMint(args []string) error {
+	var role, tenantSlug, rawUserID string
+	cfg, fs, err := parseFlags("token mint", args, func(fs *pflag.FlagSet) {
+		fs.StringVar(&role, "role", "", "роль: employee, moderator или client")
+		fs.StringVar(&tenantSlug, "tenant", "", "арендатор (по умолчанию default)")
+		fs.StringVar(&rawUserID, "user-id", "", "ID пользователя (по умолчанию случайный)")
+	})
+	if err != nil {
+		return err
+	}
+	if err = noArgs(fs); err != nil {
+		return err
+	}
+
+	if !slices.Contains(userRoles, role) {
+		return fmt.Errorf("недопустимая роль %q, допустимые: %v", role, userRoles)
+	}
+
+	userID := uuid.New()
+	if rawUserID != "" {
+		if userID, err = uuid.Parse(rawUserID); err != nil {
+			return fmt.Errorf("некорректный --user-id: %w", err)
+		}
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	tenantID := tenant.DefaultID
+	if tenantSlug != "" && tenantSlug != tenant.DefaultSlug {
+
+		svc, closeService := newCLIService(ctx, cfg)
+		defer closeService()
+
+		t, err := svc.GetTenant(ctx, tenantSlug)
+		if err != nil {
+			return err
+		}
+		tenantID = t.ID
+	}
+
+	token, err := auth.NewTokens(cfg.JWT.JWTSecret).Generate(ctx, userID, role, tenantID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(token)
+
+	return nil
+}
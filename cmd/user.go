@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/spf13/pflag"
+	"slices"
+)
+
+var userRoles = []string{"employee", "moderator", "client"}
+
+func runUserCreate(args []string) error {
+	var email, password, role, tenantSlug string
+	cfg, fs, err := parseFlags("user create", args, func(fs *pflag.FlagSet) {
+		fs.StringVar(&email, "email", "", "email пользователя")
+		fs.StringVar(&password, "password", "", "пароль; если не задан, читается со стандартного ввода")
+		fs.StringVar(&role, "role", "moderator", "роль: employee, moderator или client")
+		fs.StringVar(&tenantSlug, "tenant", "", "арендатор (по умолчанию default)")
+	})
+	if err != nil {
+		return err
+	}
+	if err = noArgs(fs); err != nil {
+		return err
+	}
+
+	if email == "" {
+		return errors.New("не задан --email")
+	}
+	if !slices.Contains(userRoles, role) {
+		return fmt.Errorf("недопустимая роль %q, допустимые: %v", role, userRoles)
+	}
+	if password, err = readPassword(password); err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	svc, closeService := newCLIService(ctx, cfg)
+	defer closeService()
+
+	user, err := svc.CreateUser(ctx, models.UserRegisterReq{
+		Email:    email,
+		Password: password,
+		Role:     role,
+		Tenant:   tenantSlug,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Создан пользователь %s (%s) с ID %s\n", user.Email, user.Role, user.ID)
+
+	return nil
+}
+
+func runUserSetPassword(args []string) error {
+	var email, password, tenantSlug string
+	cfg, fs, err := parseFlags("user set-password", args, func(fs *pflag.FlagSet) {
+		fs.StringVar(&email, "email", "", "email пользователя")
+		fs.StringVar(&password, "password", "", "новый пароль; если не задан, читается со стандартного ввода")
+		fs.StringVar(&tenantSlug, "tenant", "", "арендатор (по умолчанию default)")
+	})
+	if err != nil {
+		return err
+	}
+	if err = noArgs(fs); err != nil {
+		return err
+	}
+
+	if email == "" {
+		return errors.New("не задан --email")
+	}
+	if password, err = readPassword(password); err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	svc, closeService := newCLIService(ctx, cfg)
+	defer closeService()
+
+	if err = svc.SetUserPassword(ctx, tenantSlug, email, password); err != nil {
+		return err
+	}
+
+	fmt.Printf("Пароль пользователя %s изменён\n", email)
+
+	return nil
+}
@@ -1,57 +1,160 @@
 package config
 
 import (
-	"github.com/gookit/slog"
-	"github.com/spf13/viper"
+	"time"
 )
 
-var Config config
+// Config — типизированная конфигурация сервиса. Собирается функцией Load
+// и передаётся компонентам явно.
+type Config struct {
+	Server    Server
+	Log       Log
+	Postgres  Postgres
+	JWT       JWT
+	Auth      Auth
+	Mail      Mail
+	Scheduler Scheduler
+	Expiry    Expiry
+	Reception Reception
+	Storage   Storage
+	Cache     Cache
+	Tracing   Tracing
 
-type config struct {
-	Server   Server
-	Postgres Postgres
-	JWT      JWT
+	// values — значения параметров в том виде, в каком они заданы, для Print.
+	values map[string]string
+}
+
+// Log задаёт уровень и формат логов.
+type Log struct {
+	Level  string
+	Format string
 }
 
 type Server struct {
 	Host string
 	Port string
+	// DrainDelay — сколько /readyz отвечает 503 до остановки сервера,
+	// чтобы балансировщик успел перестать направлять запросы.
+	DrainDelay      time.Duration
+	ShutdownTimeout time.Duration
 }
 
 type Postgres struct {
-	Username string
-	Password string
-	Host     string
-	Port     string
-	DBName   string
-	SSLMode  string
+	// Username — роль входа приложения. Она не должна обходить row-level
+	// security, поэтому схему создаёт и меняет владелец MigrateUsername.
+	Username        string
+	Password        string
+	MigrateUsername string
+	MigratePassword string
+	Host            string
+	Port            string
+	DBName          string
+	SSLMode         string
+	// Пул соединений; нулевые значения оставляют настройки pgx по умолчанию.
+	MaxConns         int32
+	MinConns         int32
+	MaxConnLifetime  time.Duration
+	MaxConnIdleTime  time.Duration
+	StatementTimeout time.Duration
+	// Подключение при запуске повторяется ConnectAttempts раз с паузой от
+	// ConnectBackoff, удваивающейся до ConnectMaxBackoff.
+	ConnectAttempts   int
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
+	// Транзакции, прерванные конфликтом сериализации, взаимоблокировкой или
+	// обрывом соединения, повторяются до TxRetryAttempts раз.
+	TxRetryAttempts int
+	TxRetryBackoff  time.Duration
+	// CloseTimeout — сколько при остановке ждать, пока в пул вернутся
+	// соединения с незавершёнными транзакциями.
+	CloseTimeout time.Duration
+	// ReplicaDSN — строка подключения к реплике для чтения. Пустая строка
+	// означает, что все запросы идут в основную базу.
+	ReplicaDSN string
 }
 
 type JWT struct {
 	JWTSecret string
 }
 
-func init() {
-	viper.SetConfigFile(".env")
+// Auth задаёт требования к паролям, срок действия одноразовых кодов из писем
+// и время, на которое кэшируется момент смены пароля при проверке токенов.
+type Auth struct {
+	PasswordMinLength        int
+	PasswordRequireLetter    bool
+	PasswordRequireDigit     bool
+	PasswordRequireMixedCase bool
+	PasswordRequireSpecial   bool
+	PasswordResetTTL         time.Duration
+	EmailVerificationTTL     time.Duration
+	RevocationCacheTTL       time.Duration
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		slog.Errorf("Ошибка при чтении конфигурации: %s", err)
-	}
+// Mail задаёт отправку писем: smtp — через почтовый сервер SMTPAddr,
+// log — только получатель и тема в лог сервиса, file — файлами .eml
+// в каталог FileDir. log и file предназначены для локального запуска.
+type Mail struct {
+	Driver       string
+	From         string
+	FileDir      string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// Scheduler задаёт, сколько при остановке ждать завершения текущих запусков
+// фоновых задач, прежде чем отменить их.
+type Scheduler struct {
+	ShutdownTimeout time.Duration
+}
+
+// Expiry задаёт сроки хранения товаров в ПВЗ по типам и период проверки.
+type Expiry struct {
+	CheckInterval  time.Duration
+	StoragePeriods map[string]time.Duration
+}
 
-	Config = config{
-		Server: Server{
-			Host: viper.GetString("SRV_HOST"),
-			Port: viper.GetString("SRV_PORT"),
-		},
-		Postgres: Postgres{
-			Username: viper.GetString("POSTGRES_USER"),
-			Password: viper.GetString("POSTGRES_PASSWORD"),
-			Host:     viper.GetString("POSTGRES_HOST"),
-			Port:     viper.GetString("POSTGRES_PORT"),
-			DBName:   viper.GetString("POSTGRES_DB"),
-		},
-		JWT: JWT{
-			JWTSecret: viper.GetString("SECRET_KEY"),
-		},
+// Reception задаёт допустимую длительность приёмки, период поиска зависших приёмок
+// и время после закрытия, в течение которого приёмку можно переоткрыть.
+type Reception struct {
+	MaxDuration        time.Duration
+	StaleCheckInterval time.Duration
+	ReopenGracePeriod  time.Duration
+}
+
+// Storage задаёт каталог, в котором хранятся фотографии товаров.
+type Storage struct {
+	LocalDir string
+}
+
+// Cache задаёт кэш списка ПВЗ: memory — LRU в памяти процесса, redis — общий
+// кэш для всех экземпляров, none — без кэша.
+type Cache struct {
+	Backend       string
+	LRUSize       int
+	TTL           time.Duration
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+// Tracing задаёт экспорт трассировки OpenTelemetry: otlp — в коллектор по
+// OTLP/HTTP, stdout — в стандартный вывод для отладки, none — без экспорта.
+type Tracing struct {
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	SampleRatio  float64
+	ServiceName  string
+}
+
+// Migrator возвращает параметры подключения для команды migrate: под
+// владельцем схемы, если он задан, иначе под ролью приложения.
+func (p Postgres) Migrator() Postgres {
+	if p.MigrateUsername == "" {
+		return p
 	}
+
+	p.Username, p.Password = p.MigrateUsername, p.MigratePassword
+	return p
 }
@@ -0,0 +1,313 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultFile читается, если путь к файлу конфигурации не задан явно
+// и файл существует.
+const DefaultFile = ".env"
+
+// setting описывает один параметр конфигурации. Параметр задаётся ключом
+// в файле конфигурации, одноимённой переменной окружения или флагом
+// --key-in-lower-case; флаг важнее переменной окружения, а та — файла.
+type setting struct {
+	key    string
+	def    any
+	usage  string
+	secret bool
+}
+
+var settings = []setting{
+	{key: "SRV_HOST", def: "0.0.0.0", usage: "адрес HTTP-сервера"},
+	{key: "SRV_PORT", def: "8080", usage: "порт HTTP-сервера"},
+	{key: "SRV_DRAIN_DELAY", def: "5s", usage: "пауза между снятием готовности и остановкой сервера"},
+	{key: "SRV_SHUTDOWN_TIMEOUT", def: "5s", usage: "время на завершение текущих запросов при остановке"},
+
+	{key: "LOG_LEVEL", def: "info", usage: "уровень логирования: debug, info, warn, error"},
+	{key: "LOG_FORMAT", def: "json", usage: "формат логов: json или text"},
+
+	{key: "POSTGRES_HOST", usage: "хост Postgres"},
+	{key: "POSTGRES_PORT", def: "5432", usage: "порт Postgres"},
+	{key: "POSTGRES_USER", usage: "пользователь Postgres"},
+	{key: "POSTGRES_PASSWORD", usage: "пароль Postgres", secret: true},
+	{key: "POSTGRES_DB", usage: "база данных Postgres"},
+	{key: "POSTGRES_MIGRATE_USER", usage: "владелец схемы для команды migrate; пусто — POSTGRES_USER"},
+	{key: "POSTGRES_MIGRATE_PASSWORD", usage: "пароль владельца схемы", secret: true},
+	{key: "POSTGRES_SSLMODE", def: "prefer", usage: "режим TLS: disable, allow, prefer, require, verify-ca, verify-full"},
+	{key: "POSTGRES_MAX_CONNS", def: 10, usage: "максимум соединений в пуле"},
+	{key: "POSTGRES_MIN_CONNS", def: 0, usage: "минимум соединений в пуле"},
+	{key: "POSTGRES_MAX_CONN_LIFETIME", def: "1h", usage: "время жизни соединения"},
+	{key: "POSTGRES_MAX_CONN_IDLE_TIME", def: "30m", usage: "время простоя соединения до закрытия"},
+	{key: "POSTGRES_STATEMENT_TIMEOUT", def: "30s", usage: "таймаут запроса, 0 — без таймаута"},
+	{key: "POSTGRES_CONNECT_ATTEMPTS", def: 5, usage: "попытки подключения при запуске"},
+	{key: "POSTGRES_CONNECT_BACKOFF", def: "1s", usage: "начальная пауза между попытками подключения"},
+	{key: "POSTGRES_CONNECT_MAX_BACKOFF", def: "30s", usage: "максимальная пауза между попытками подключения"},
+	{key: "POSTGRES_TX_RETRY_ATTEMPTS", def: 3, usage: "попытки транзакции при временных ошибках"},
+	{key: "POSTGRES_TX_RETRY_BACKOFF", def: "50ms", usage: "начальная пауза между попытками транзакции"},
+	{key: "POSTGRES_CLOSE_TIMEOUT", def: "10s", usage: "время на возврат соединений в пул при остановке"},
+	{key: "POSTGRES_REPLICA_DSN", usage: "строка подключения к реплике для чтения", secret: true},
+
+	{key: "SECRET_KEY", usage: "ключ подписи JWT", secret: true},
+
+	{key: "PASSWORD_MIN_LENGTH", def: 8, usage: "минимальная длина пароля"},
+	{key: "PASSWORD_REQUIRE_LETTER", def: true, usage: "пароль должен содержать букву"},
+	{key: "PASSWORD_REQUIRE_DIGIT", def: true, usage: "пароль должен содержать цифру"},
+	{key: "PASSWORD_REQUIRE_MIXED_CASE", def: false, usage: "пароль должен содержать строчные и заглавные буквы"},
+	{key: "PASSWORD_REQUIRE_SPECIAL", def: false, usage: "пароль должен содержать спецсимвол"},
+	{key: "PASSWORD_RESET_TTL", def: "1h", usage: "срок действия кода сброса пароля"},
+	{key: "EMAIL_VERIFICATION_TTL", def: "24h", usage: "срок действия кода подтверждения email"},
+	{key: "TOKEN_REVOCATION_CACHE_TTL", def: "10s", usage: "время кэширования момента смены пароля для проверки токенов; 0 — без кэша"},
+
+	{key: "MAIL_DRIVER", def: "smtp", usage: "отправка писем: smtp; log и file — для локального запуска"},
+	{key: "MAIL_FROM", def: "no-reply@pvz-service.local", usage: "адрес отправителя писем"},
+	{key: "MAIL_SMTP_ADDR", usage: "адрес почтового сервера host:port для MAIL_DRIVER=smtp"},
+	{key: "MAIL_SMTP_USERNAME", usage: "логин почтового сервера; пусто — без авторизации"},
+	{key: "MAIL_SMTP_PASSWORD", usage: "пароль почтового сервера", secret: true},
+	{key: "MAIL_FILE_DIR", def: "./data/mail", usage: "каталог писем для MAIL_DRIVER=file"},
+
+	{key: "SCHEDULER_SHUTDOWN_TIMEOUT", def: "30s", usage: "время на завершение текущих фоновых задач при остановке"},
+
+	{key: "EXPIRY_CHECK_INTERVAL", def: "1h", usage: "период проверки сроков хранения, 0 — не проверять"},
+	{key: "STORAGE_PERIOD_ELECTRONICS", def: "168h", usage: "срок хранения электроники"},
+	{key: "STORAGE_PERIOD_CLOTHES", def: "168h", usage: "срок хранения одежды"},
+	{key: "STORAGE_PERIOD_SHOES", def: "168h", usage: "срок хранения обуви"},
+
+	{key: "RECEPTION_MAX_DURATION", def: "12h", usage: "длительность приёмки до пометки зависшей, 0 — не отслеживать"},
+	{key: "RECEPTION_STALE_CHECK_INTERVAL", def: "10m", usage: "период поиска зависших приёмок, 0 — не искать"},
+	{key: "RECEPTION_REOPEN_GRACE_PERIOD", def: "30m", usage: "время после закрытия, когда приёмку можно переоткрыть"},
+
+	{key: "STORAGE_LOCAL_DIR", def: "./data/attachments", usage: "каталог фотографий товаров"},
+
+	{key: "CACHE_BACKEND", def: "memory", usage: "кэш списка ПВЗ: memory, redis или none"},
+	{key: "CACHE_LRU_SIZE", def: 1024, usage: "размер LRU-кэша в записях"},
+	{key: "CACHE_TTL", def: "30s", usage: "время жизни записи кэша"},
+	{key: "REDIS_ADDR", usage: "адрес Redis"},
+	{key: "REDIS_PASSWORD", usage: "пароль Redis", secret: true},
+	{key: "REDIS_DB", def: 0, usage: "номер базы Redis"},
+
+	{key: "TRACING_EXPORTER", def: "none", usage: "экспорт трассировки: otlp, stdout или none"},
+	{key: "TRACING_OTLP_ENDPOINT", def: "localhost:4318", usage: "адрес OTLP/HTTP-коллектора"},
+	{key: "TRACING_OTLP_INSECURE", def: false, usage: "отправлять трассировку в коллектор без TLS"},
+	{key: "TRACING_SAMPLE_RATIO", def: 1.0, usage: "доля трассируемых запросов от 0 до 1"},
+	{key: "TRACING_SERVICE_NAME", def: "pvz-service", usage: "имя сервиса в трассировке"},
+}
+
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// Flags возвращает набор флагов со всеми параметрами конфигурации и флагом
+// --config. Его разбирает вызывающий и передаёт в Load.
+func Flags(name string) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fs.String("config", "", "файл конфигурации (по умолчанию "+DefaultFile+", если есть)")
+	for _, s := range settings {
+		fs.String(flagName(s.key), "", s.usage)
+	}
+
+	return fs
+}
+
+// Load собирает конфигурацию из значений по умолчанию, файла, переменных
+// окружения и разобранных флагов fs и проверяет её. fs может быть nil.
+func Load(fs *pflag.FlagSet) (Config, error) {
+	v := viper.New()
+	for _, s := range settings {
+		if s.def != nil {
+			v.SetDefault(s.key, s.def)
+		}
+		if err := v.BindEnv(s.key); err != nil {
+			return Config{}, err
+		}
+	}
+
+	file := DefaultFile
+	explicit := false
+	if fs != nil {
+		for _, s := range settings {
+			if flag := fs.Lookup(flagName(s.key)); flag != nil && flag.Changed {
+				v.Set(s.key, flag.Value.String())
+			}
+		}
+		if flag := fs.Lookup("config"); flag != nil && flag.Changed {
+			file, explicit = flag.Value.String(), true
+		}
+	}
+
+	if _, err := os.Stat(file); err == nil || explicit {
+		v.SetConfigFile(file)
+		if err = v.ReadInConfig(); err != nil {
+			return Config{}, fmt.Errorf("ошибка при чтении файла конфигурации %s: %w", file, err)
+		}
+	}
+
+	cfg, err := decode(v)
+	if err != nil {
+		return Config{}, err
+	}
+	if err = cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// decoder переводит значения viper в типы схемы и копит ошибки разбора,
+// чтобы сообщить обо всех некорректных параметрах сразу.
+type decoder struct {
+	v    *viper.Viper
+	errs []error
+}
+
+func (d *decoder) string(key string) string {
+	return d.v.GetString(key)
+}
+
+func (d *decoder) int(key string) int {
+	n, err := cast.ToIntE(d.v.Get(key))
+	if err != nil {
+		d.errs = append(d.errs, fmt.Errorf("%s: ожидается целое число, получено %q", key, d.v.GetString(key)))
+	}
+	return n
+}
+
+func (d *decoder) bool(key string) bool {
+	b, err := cast.ToBoolE(d.v.Get(key))
+	if err != nil {
+		d.errs = append(d.errs, fmt.Errorf("%s: ожидается true или false, получено %q", key, d.v.GetString(key)))
+	}
+	return b
+}
+
+func (d *decoder) float(key string) float64 {
+	f, err := cast.ToFloat64E(d.v.Get(key))
+	if err != nil {
+		d.errs = append(d.errs, fmt.Errorf("%s: ожидается число, получено %q", key, d.v.GetString(key)))
+	}
+	return f
+}
+
+func (d *decoder) duration(key string) time.Duration {
+	value := d.v.Get(key)
+	if s, ok := value.(string); ok && s == "" {
+		return 0
+	}
+
+	dur, err := cast.ToDurationE(value)
+	if err != nil {
+		d.errs = append(d.errs, fmt.Errorf("%s: ожидается длительность вида 30s или 1h, получено %q", key, d.v.GetString(key)))
+	}
+	return dur
+}
+
+func decode(v *viper.Viper) (Config, error) {
+	d := &decoder{v: v}
+
+	cfg := Config{
+		Server: Server{
+			Host:            d.string("SRV_HOST"),
+			Port:            d.string("SRV_PORT"),
+			DrainDelay:      d.duration("SRV_DRAIN_DELAY"),
+			ShutdownTimeout: d.duration("SRV_SHUTDOWN_TIMEOUT"),
+		},
+		Log: Log{
+			Level:  d.string("LOG_LEVEL"),
+			Format: d.string("LOG_FORMAT"),
+		},
+		Postgres: Postgres{
+			Username:          d.string("POSTGRES_USER"),
+			Password:          d.string("POSTGRES_PASSWORD"),
+			MigrateUsername:   d.string("POSTGRES_MIGRATE_USER"),
+			MigratePassword:   d.string("POSTGRES_MIGRATE_PASSWORD"),
+			Host:              d.string("POSTGRES_HOST"),
+			Port:              d.string("POSTGRES_PORT"),
+			DBName:            d.string("POSTGRES_DB"),
+			SSLMode:           d.string("POSTGRES_SSLMODE"),
+			MaxConns:          int32(d.int("POSTGRES_MAX_CONNS")),
+			MinConns:          int32(d.int("POSTGRES_MIN_CONNS")),
+			MaxConnLifetime:   d.duration("POSTGRES_MAX_CONN_LIFETIME"),
+			MaxConnIdleTime:   d.duration("POSTGRES_MAX_CONN_IDLE_TIME"),
+			StatementTimeout:  d.duration("POSTGRES_STATEMENT_TIMEOUT"),
+			ConnectAttempts:   d.int("POSTGRES_CONNECT_ATTEMPTS"),
+			ConnectBackoff:    d.duration("POSTGRES_CONNECT_BACKOFF"),
+			ConnectMaxBackoff: d.duration("POSTGRES_CONNECT_MAX_BACKOFF"),
+			TxRetryAttempts:   d.int("POSTGRES_TX_RETRY_ATTEMPTS"),
+			TxRetryBackoff:    d.duration("POSTGRES_TX_RETRY_BACKOFF"),
+			CloseTimeout:      d.duration("POSTGRES_CLOSE_TIMEOUT"),
+			ReplicaDSN:        d.string("POSTGRES_REPLICA_DSN"),
+		},
+		JWT: JWT{
+			JWTSecret: d.string("SECRET_KEY"),
+		},
+		Auth: Auth{
+			PasswordMinLength:        d.int("PASSWORD_MIN_LENGTH"),
+			PasswordRequireLetter:    d.bool("PASSWORD_REQUIRE_LETTER"),
+			PasswordRequireDigit:     d.bool("PASSWORD_REQUIRE_DIGIT"),
+			PasswordRequireMixedCase: d.bool("PASSWORD_REQUIRE_MIXED_CASE"),
+			PasswordRequireSpecial:   d.bool("PASSWORD_REQUIRE_SPECIAL"),
+			PasswordResetTTL:         d.duration("PASSWORD_RESET_TTL"),
+			EmailVerificationTTL:     d.duration("EMAIL_VERIFICATION_TTL"),
+			RevocationCacheTTL:       d.duration("TOKEN_REVOCATION_CACHE_TTL"),
+		},
+		Mail: Mail{
+			Driver:       d.string("MAIL_DRIVER"),
+			From:         d.string("MAIL_FROM"),
+			FileDir:      d.string("MAIL_FILE_DIR"),
+			SMTPAddr:     d.string("MAIL_SMTP_ADDR"),
+			SMTPUsername: d.string("MAIL_SMTP_USERNAME"),
+			SMTPPassword: d.string("MAIL_SMTP_PASSWORD"),
+		},
+		Scheduler: Scheduler{
+			ShutdownTimeout: d.duration("SCHEDULER_SHUTDOWN_TIMEOUT"),
+		},
+		Expiry: Expiry{
+			CheckInterval: d.duration("EXPIRY_CHECK_INTERVAL"),
+			StoragePeriods: map[string]time.Duration{
+				"электроника": d.duration("STORAGE_PERIOD_ELECTRONICS"),
+				"одежда":      d.duration("STORAGE_PERIOD_CLOTHES"),
+				"обувь":       d.duration("STORAGE_PERIOD_SHOES"),
+			},
+		},
+		Reception: Reception{
+			MaxDuration:        d.duration("RECEPTION_MAX_DURATION"),
+			StaleCheckInterval: d.duration("RECEPTION_STALE_CHECK_INTERVAL"),
+			ReopenGracePeriod:  d.duration("RECEPTION_REOPEN_GRACE_PERIOD"),
+		},
+		Storage: Storage{
+			LocalDir: d.string("STORAGE_LOCAL_DIR"),
+		},
+		Cache: Cache{
+			Backend:       d.string("CACHE_BACKEND"),
+			LRUSize:       d.int("CACHE_LRU_SIZE"),
+			TTL:           d.duration("CACHE_TTL"),
+			RedisAddr:     d.string("REDIS_ADDR"),
+			RedisPassword: d.string("REDIS_PASSWORD"),
+			RedisDB:       d.int("REDIS_DB"),
+		},
+		Tracing: Tracing{
+			Exporter:     d.string("TRACING_EXPORTER"),
+			OTLPEndpoint: d.string("TRACING_OTLP_ENDPOINT"),
+			OTLPInsecure: d.bool("TRACING_OTLP_INSECURE"),
+			SampleRatio:  d.float("TRACING_SAMPLE_RATIO"),
+			ServiceName:  d.string("TRACING_SERVICE_NAME"),
+		},
+		values: make(map[string]string, len(settings)),
+	}
+	for _, s := range settings {
+		cfg.values[s.key] = v.GetString(s.key)
+	}
+
+	if len(d.errs) > 0 {
+		return Config{}, fmt.Errorf("некорректная конфигурация:\n%w", errors.Join(d.errs...))
+	}
+
+	return cfg, nil
+}
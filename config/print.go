@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+)
+
+const redacted = "***"
+
+// Print выводит действующую конфигурацию в формате файла .env, скрывая
+// секреты. Вывод можно сохранить и передать обратно через --config.
+func (c Config) Print(w io.Writer) error {
+	for _, s := range settings {
+		value := c.values[s.key]
+		if s.secret && value != "" {
+			value = redact(value)
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", s.key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// redact оставляет от строки подключения всё, кроме пароля, а остальные
+// секреты скрывает целиком.
+func redact(value string) string {
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return u.Redacted()
+	}
+
+	return redacted
+}
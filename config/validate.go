@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные
+// проблемы одной ошибкой.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port <= 65535, "SRV_PORT: ожидается порт от 1 до 65535, получено %q", c.Server.Port)
+	check(c.Server.DrainDelay >= 0, "SRV_DRAIN_DELAY: не может быть отрицательным")
+	check(c.Server.ShutdownTimeout > 0, "SRV_SHUTDOWN_TIMEOUT: должно быть больше 0")
+
+	check(slices.Contains(logLevels, c.Log.Level), "LOG_LEVEL: допустимые значения %v, получено %q", logLevels, c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "LOG_FORMAT: допустимые значения json, text, получено %q", c.Log.Format)
+
+	check(c.Postgres.Host != "", "POSTGRES_HOST: не задан")
+	check(c.Postgres.Username != "", "POSTGRES_USER: не задан")
+	check(c.Postgres.DBName != "", "POSTGRES_DB: не задан")
+	port, err = strconv.Atoi(c.Postgres.Port)
+	check(err == nil && port > 0 && port <= 65535, "POSTGRES_PORT: ожидается порт от 1 до 65535, получено %q", c.Postgres.Port)
+	check(slices.Contains(sslModes, c.Postgres.SSLMode), "POSTGRES_SSLMODE: допустимые значения %v, получено %q", sslModes, c.Postgres.SSLMode)
+	check(c.Postgres.MaxConns > 0, "POSTGRES_MAX_CONNS: должно быть больше 0")
+	check(c.Postgres.MinConns >= 0 && c.Postgres.MinConns <= c.Postgres.MaxConns,
+		"POSTGRES_MIN_CONNS: должно быть от 0 до POSTGRES_MAX_CONNS")
+	check(c.Postgres.StatementTimeout >= 0, "POSTGRES_STATEMENT_TIMEOUT: не может быть отрицательным")
+	check(c.Postgres.ConnectAttempts > 0, "POSTGRES_CONNECT_ATTEMPTS: должно быть больше 0")
+	check(c.Postgres.ConnectBackoff > 0, "POSTGRES_CONNECT_BACKOFF: должно быть больше 0")
+	check(c.Postgres.ConnectMaxBackoff >= c.Postgres.ConnectBackoff,
+		"POSTGRES_CONNECT_MAX_BACKOFF: не может быть меньше POSTGRES_CONNECT_BACKOFF")
+	check(c.Postgres.TxRetryAttempts > 0, "POSTGRES_TX_RETRY_ATTEMPTS: должно быть больше 0")
+	check(c.Postgres.TxRetryBackoff >= 0, "POSTGRES_TX_RETRY_BACKOFF: не может быть отрицательным")
+	check(c.Postgres.CloseTimeout > 0, "POSTGRES_CLOSE_TIMEOUT: должно быть больше 0")
+
+	check(c.JWT.JWTSecret != "", "SECRET_KEY: не задан")
+
+	// bcrypt учитывает только первые 72 байта пароля.
+	check(c.Auth.PasswordMinLength > 0 && c.Auth.PasswordMinLength <= 72, "PASSWORD_MIN_LENGTH: должно быть от 1 до 72")
+	check(c.Auth.PasswordResetTTL > 0, "PASSWORD_RESET_TTL: должно быть больше 0")
+	check(c.Auth.EmailVerificationTTL > 0, "EMAIL_VERIFICATION_TTL: должно быть больше 0")
+	check(c.Auth.RevocationCacheTTL >= 0, "TOKEN_REVOCATION_CACHE_TTL: не может быть отрицательным")
+
+	switch c.Mail.Driver {
+	case "smtp":
+		check(c.Mail.SMTPAddr != "", "MAIL_SMTP_ADDR: обязателен для MAIL_DRIVER=smtp")
+	case "file":
+		check(c.Mail.FileDir != "", "MAIL_FILE_DIR: обязателен для MAIL_DRIVER=file")
+	case "log":
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_DRIVER: допустимые значения smtp, log, file, получено %q", c.Mail.Driver))
+	}
+	check(c.Mail.From != "", "MAIL_FROM: не задан")
+
+	check(c.Scheduler.ShutdownTimeout > 0, "SCHEDULER_SHUTDOWN_TIMEOUT: должно быть больше 0")
+
+	check(c.Expiry.CheckInterval >= 0, "EXPIRY_CHECK_INTERVAL: не может быть отрицательным")
+	for productType, period := range c.Expiry.StoragePeriods {
+		check(period > 0, "срок хранения для типа %q должен быть больше 0", productType)
+	}
+	check(c.Reception.MaxDuration >= 0, "RECEPTION_MAX_DURATION: не может быть отрицательным")
+	check(c.Reception.StaleCheckInterval >= 0, "RECEPTION_STALE_CHECK_INTERVAL: не может быть отрицательным")
+	check(c.Reception.ReopenGracePeriod >= 0, "RECEPTION_REOPEN_GRACE_PERIOD: не может быть отрицательным")
+
+	check(c.Storage.LocalDir != "", "STORAGE_LOCAL_DIR: не задан")
+
+	switch c.Cache.Backend {
+	case "memory":
+		check(c.Cache.LRUSize > 0, "CACHE_LRU_SIZE: должно быть больше 0")
+	case "redis":
+		check(c.Cache.RedisAddr != "", "REDIS_ADDR: обязателен для CACHE_BACKEND=redis")
+		check(c.Cache.RedisDB >= 0, "REDIS_DB: не может быть отрицательным")
+	case "none":
+	default:
+		errs = append(errs, fmt.Errorf("CACHE_BACKEND: допустимые значения memory, redis, none, получено %q", c.Cache.Backend))
+	}
+	if c.Cache.Backend != "none" {
+		check(c.Cache.TTL > 0, "CACHE_TTL: должно быть больше 0")
+	}
+
+	switch c.Tracing.Exporter {
+	case "otlp":
+		check(c.Tracing.OTLPEndpoint != "", "TRACING_OTLP_ENDPOINT: обязателен для TRACING_EXPORTER=otlp")
+	case "stdout", "none":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER: допустимые значения otlp, stdout, none, получено %q", c.Tracing.Exporter))
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO: должно быть от 0 до 1")
+	check(c.Tracing.ServiceName != "", "TRACING_SERVICE_NAME: не задан")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("некорректная конфигурация:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -5,43 +5,131 @@ import (
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kstsm/pvz-service/config"
-	"log"
+	"github.com/kstsm/pvz-service/internal/logger"
+	"github.com/kstsm/pvz-service/internal/telemetry"
+	"github.com/kstsm/pvz-service/internal/tenant"
 	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 type Repository struct {
 	dg *pgxpool.Pool
 }
 
-var cfg = config.Config
-
-func InitPostgres(ctx context.Context) *pgxpool.Pool {
-	dsn := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s",
-		cfg.Postgres.Username,
-		cfg.Postgres.Password,
-		cfg.Postgres.Host,
-		cfg.Postgres.Port,
-		cfg.Postgres.DBName,
-	)
+func InitPostgres(ctx context.Context, cfg config.Postgres) *pgxpool.Pool {
+	dsn := (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Path:     cfg.DBName,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}).String()
 
 	slog.Info(
-		"Подключение к базе данных", "host", cfg.Postgres.Host,
-		"port", cfg.Postgres.Port, "db", cfg.Postgres.DBName,
+		"Подключение к базе данных", "host", cfg.Host,
+		"port", cfg.Port, "db", cfg.DBName, "sslmode", cfg.SSLMode,
 	)
 
-	pool, err := pgxpool.New(ctx, dsn)
+	poolConfig, err := newPoolConfig(dsn, cfg)
+	if err != nil {
+		logger.Fatal("Некорректные параметры подключения к базе данных", "error", err)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
+		logger.Fatal("Не удалось подключиться к базе данных", "error", err)
 	}
 
-	fmt.Println("Успешное подключение к базе данных")
+	if err = pingWithRetry(ctx, pool, cfg); err != nil {
+		logger.Fatal("Ошибка проверки соединения с БД", "error", err)
+	}
+	slog.Info("База данных доступна")
+
+	return pool
+}
 
-	err = pool.Ping(ctx)
+// InitReplica подключается к реплике для чтения, если она настроена. Недоступная
+// при запуске реплика не останавливает сервис: репозиторий читает из основной
+// базы, пока реплика не начнёт отвечать.
+func InitReplica(ctx context.Context, cfg config.Postgres) *pgxpool.Pool {
+	if cfg.ReplicaDSN == "" {
+		return nil
+	}
+
+	poolConfig, err := newPoolConfig(cfg.ReplicaDSN, cfg)
+	if err != nil {
+		logger.Fatal("Некорректные параметры подключения к реплике", "error", err)
+	}
+
+	slog.Info("Подключение к реплике", "host", poolConfig.ConnConfig.Host,
+		"port", poolConfig.ConnConfig.Port, "db", poolConfig.ConnConfig.Database)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatalf("Ошибка проверки соединения с БД: %v", err)
+		logger.Fatal("Не удалось подключиться к реплике", "error", err)
+	}
+
+	if err = pool.Ping(ctx); err != nil {
+		slog.Warn("Реплика недоступна, чтение пойдёт в основную базу", "error", err)
 	}
-	fmt.Println("База данных доступна")
 
 	return pool
 }
+
+// newPoolConfig применяет к dsn настройки пула и таймаут запросов из конфигурации.
+// Нулевые значения оставляют настройки pgx по умолчанию.
+func newPoolConfig(dsn string, cfg config.Postgres) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.BeforeAcquire = tenant.BeforeAcquire
+	poolConfig.ConnConfig.Tracer = telemetry.QueryTracer{}
+
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolConfig.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] =
+			strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
+
+// pingWithRetry ждёт, пока база начнёт отвечать, повторяя проверку с
+// удваивающейся паузой не дольше ConnectMaxBackoff.
+func pingWithRetry(ctx context.Context, pool *pgxpool.Pool, cfg config.Postgres) error {
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err := pool.Ping(ctx)
+		if err == nil || attempt >= cfg.ConnectAttempts {
+			return err
+		}
+
+		slog.Warn("База данных недоступна, повторим подключение", "attempt", attempt,
+			"backoff", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if cfg.ConnectMaxBackoff > 0 && backoff > cfg.ConnectMaxBackoff {
+			backoff = cfg.ConnectMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// baselineMigration — начальная схема. Docker применяет её сам при создании
+// базы, поэтому в такой базе она считается уже выполненной. Файл не меняется:
+// все последующие изменения схемы — отдельные миграции 002_..., 003_....
+const baselineMigration = "init.sql"
+
+const (
+	queryCreateSchemaMigrations = `
+		CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`
+
+	querySchemaMigrationsExists = `SELECT to_regclass('public.schema_migrations') IS NOT NULL`
+
+	// Начальную схему выдают её собственные таблицы: базы, созданные до
+	// появления schema_migrations, ничего, кроме них, могут и не содержать.
+	queryBaselineApplied = `SELECT to_regclass('public.pvz') IS NOT NULL AND to_regclass('public.users') IS NOT NULL`
+
+	queryAppliedMigrations = `SELECT version FROM schema_migrations`
+
+	queryRecordMigration = `INSERT INTO schema_migrations (version) VALUES ($1)`
+)
+
+// Migrate применяет к базе ещё не выполненные миграции из fsys в порядке
+// migrationFiles, каждую в своей транзакции. Возвращает применённые миграции.
+func Migrate(ctx context.Context, pool *pgxpool.Pool, fsys fs.FS) ([]string, error) {
+	files, err := migrationFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracked bool
+	if err = pool.QueryRow(ctx, querySchemaMigrationsExists).Scan(&tracked); err != nil {
+		return nil, fmt.Errorf("не удалось проверить таблицу миграций: %w", err)
+	}
+	if _, err = pool.Exec(ctx, queryCreateSchemaMigrations); err != nil {
+		return nil, fmt.Errorf("не удалось создать таблицу миграций: %w", err)
+	}
+
+	if !tracked {
+		var initialized bool
+		if err = pool.QueryRow(ctx, queryBaselineApplied).Scan(&initialized); err != nil {
+			return nil, fmt.Errorf("не удалось проверить начальную схему: %w", err)
+		}
+		if initialized {
+			if _, err = pool.Exec(ctx, queryRecordMigration, baselineMigration); err != nil {
+				return nil, fmt.Errorf("не удалось отметить начальную схему: %w", err)
+			}
+			slog.Info("Начальная схема уже создана, миграция отмечена выполненной", "version", baselineMigration)
+		}
+	}
+
+	rows, err := pool.Query(ctx, queryAppliedMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить выполненные миграции: %w", err)
+	}
+	done, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить выполненные миграции: %w", err)
+	}
+
+	applied := []string{}
+	for _, file := range files {
+		if slices.Contains(done, file) {
+			continue
+		}
+
+		sql, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return applied, err
+		}
+		if err = applyMigration(ctx, pool, file, string(sql)); err != nil {
+			return applied, err
+		}
+
+		slog.Info("Миграция применена", "version", file)
+		applied = append(applied, file)
+	}
+
+	return applied, nil
+}
+
+// PendingMigrations возвращает миграции из fsys, ещё не применённые к базе.
+// В отличие от Migrate ничего не изменяет в базе.
+func PendingMigrations(ctx context.Context, pool *pgxpool.Pool, fsys fs.FS) ([]string, error) {
+	files, err := migrationFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracked bool
+	if err = pool.QueryRow(ctx, querySchemaMigrationsExists).Scan(&tracked); err != nil {
+		return nil, fmt.Errorf("не удалось проверить таблицу миграций: %w", err)
+	}
+
+	var done []string
+	if tracked {
+		rows, err := pool.Query(ctx, queryAppliedMigrations)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось получить выполненные миграции: %w", err)
+		}
+		if done, err = pgx.CollectRows(rows, pgx.RowTo[string]); err != nil {
+			return nil, fmt.Errorf("не удалось получить выполненные миграции: %w", err)
+		}
+	} else {
+		var initialized bool
+		if err = pool.QueryRow(ctx, queryBaselineApplied).Scan(&initialized); err != nil {
+			return nil, fmt.Errorf("не удалось проверить начальную схему: %w", err)
+		}
+		if initialized {
+			done = []string{baselineMigration}
+		}
+	}
+
+	pending := []string{}
+	for _, file := range files {
+		if !slices.Contains(done, file) {
+			pending = append(pending, file)
+		}
+	}
+
+	return pending, nil
+}
+
+// migrationFiles возвращает миграции из fsys: сначала начальную схему, затем
+// остальные в порядке имён (002_..., 003_...).
+func migrationFiles(fsys fs.FS) ([]string, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(files, func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == baselineMigration:
+			return -1
+		case b == baselineMigration:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	return files, nil
+}
+
+func applyMigration(ctx context.Context, pool *pgxpool.Pool, version, sql string) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("ошибка в миграции %s: %w", version, err)
+	}
+	if _, err = tx.Exec(ctx, queryRecordMigration, version); err != nil {
+		return fmt.Errorf("не удалось отметить миграцию %s: %w", version, err)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,22 @@
+package database
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"015_user_auth_flows.sql": {},
+		"init.sql":                {},
+		"002_pvz_details.sql":     {},
+		"migrations.go":           {},
+	}
+
+	files, err := migrationFiles(fsys)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"init.sql", "002_pvz_details.sql", "015_user_auth_flows.sql"}, files)
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AppRole — группа, которой миграция 017_app_role.sql выдаёт права на данные.
+// Сервер подключается ролью входа из этой группы.
+const AppRole = "pvz_app"
+
+const (
+	queryRoleBypassesRLS = `
+		SELECT rolsuper OR rolbypassrls
+		FROM pg_roles
+		WHERE rolname = $1`
+
+	queryCurrentRoleBypassesRLS = `
+		SELECT current_user, rolsuper OR rolbypassrls
+		FROM pg_roles
+		WHERE rolname = current_user`
+
+	// CREATE ROLE и ALTER ROLE не принимают параметры, поэтому имя и пароль
+	// подставляет format с экранированием.
+	queryAppLoginStatements = `
+		SELECT CASE
+			WHEN EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)
+				THEN format('ALTER ROLE %I WITH LOGIN PASSWORD %L', $1::text, NULLIF($2::text, ''))
+			ELSE format('CREATE ROLE %I WITH LOGIN NOSUPERUSER NOBYPASSRLS PASSWORD %L', $1::text, NULLIF($2::text, ''))
+		END,
+		format('GRANT %I TO %I', $3::text, $1::text)`
+)
+
+// EnsureAppLogin создаёт роль входа приложения с паролем password или
+// обновляет пароль существующей и включает её в AppRole. Выполняется под
+// владельцем схемы после миграций. Роль, которая обходит row-level security,
+// не подходит: её атрибуты не меняются, а возвращается ошибка.
+func EnsureAppLogin(ctx context.Context, pool *pgxpool.Pool, username, password string) error {
+	var bypass bool
+	err := pool.QueryRow(ctx, queryRoleBypassesRLS, username).Scan(&bypass)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("не удалось проверить роль %s: %w", username, err)
+	}
+	if bypass {
+		return fmt.Errorf("роль %s — суперпользователь или обходит row-level security, укажите в POSTGRES_USER отдельную роль", username)
+	}
+
+	var upsertRole, grantGroup string
+	if err = pool.QueryRow(ctx, queryAppLoginStatements, username, password, AppRole).Scan(&upsertRole, &grantGroup); err != nil {
+		return fmt.Errorf("не удалось подготовить роль %s: %w", username, err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, upsertRole); err != nil {
+		return fmt.Errorf("не удалось создать роль %s: %w", username, err)
+	}
+	if _, err = tx.Exec(ctx, grantGroup); err != nil {
+		return fmt.Errorf("не удалось включить роль %s в %s: %w", username, AppRole, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CheckAppLogin возвращает ошибку, если пул подключён ролью, для которой
+// политики row-level security не действуют.
+func CheckAppLogin(ctx context.Context, pool *pgxpool.Pool) error {
+	var username string
+	var bypass bool
+	err := pool.QueryRow(ctx, queryCurrentRoleBypassesRLS).Scan(&username, &bypass)
+	if err != nil {
+		return fmt.Errorf("не удалось проверить роль подключения: %w", err)
+	}
+	if bypass {
+		return fmt.Errorf("роль %s — суперпользователь или обходит row-level security: подключите сервер ролью из группы %s, "+
+			"а владельца схемы укажите в POSTGRES_MIGRATE_USER", username, AppRole)
+	}
+
+	return nil
+}
@@ -10,4 +10,31 @@ var (
 	ErrReceptionAlreadyClosed     = errors.New("приемка уже закрыта или не найдена")
 	ErrNoProductToDelete          = errors.New("нет товаров для удаления")
 	ErrReceptionAlreadyInProgress = errors.New("невозможно создать приёмку: предыдущая не закрыта")
+	ErrReceptionNotFound          = errors.New("приёмка не найдена")
+	ErrPVZNotFound                = errors.New("ПВЗ не найден")
+	ErrUserNotFound               = errors.New("пользователь не найден")
+	ErrProductNotFound            = errors.New("товар не найден")
+	ErrProductAlreadyReleased     = errors.New("товар уже выдан или возвращён")
+	ErrProductNotReady            = errors.New("товар ещё не принят: приёмка не закрыта")
+	ErrProductExpired             = errors.New("срок хранения товара истёк: его можно только вернуть отправителю")
+	ErrLockNotAcquired            = errors.New("задача уже выполняется другим экземпляром сервиса")
+	ErrReceptionHasDiscrepancies  = errors.New("приёмка расходится с манифестом: закрытие требует подтверждения расхождений")
+	ErrDiscrepancyReportNotFound  = errors.New("отчёт о расхождениях не найден")
+	ErrReceptionNotClosed         = errors.New("приёмка не закрыта")
+	ErrReopenWindowExpired        = errors.New("время, в течение которого приёмку можно переоткрыть, истекло")
+	ErrNewerReceptionExists       = errors.New("в ПВЗ уже есть более новая приёмка")
+	ErrReceptionProductsReleased  = errors.New("часть товаров приёмки уже выдана, возвращена или просрочена")
+	ErrAttachmentNotFound         = errors.New("вложение не найдено")
+	ErrAttachmentsDisabled        = errors.New("хранилище вложений не настроено")
+	ErrProductNotDeleted          = errors.New("товар не удалён")
+	ErrPVZHasOpenReception        = errors.New("в ПВЗ есть открытая приёмка")
+	ErrPVZHasStock                = errors.New("в ПВЗ остались невыданные товары")
+	ErrUserNotEmployee            = errors.New("пользователь не является сотрудником ПВЗ")
+	ErrEmployeeNotAssigned        = errors.New("сотрудник не закреплён за этим ПВЗ")
+	ErrTenantNotFound             = errors.New("арендатор не найден")
+	ErrPVZNotActive               = errors.New("ПВЗ не принимает товары: он временно закрыт или выведен из эксплуатации")
+	ErrWeakPassword               = errors.New("пароль не соответствует требованиям")
+	ErrEmailNotVerified           = errors.New("email не подтверждён")
+	ErrInvalidUserToken           = errors.New("код недействителен или устарел")
+	ErrTokenRevoked               = errors.New("токен отозван сменой пароля")
 )
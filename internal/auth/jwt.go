@@ -1,70 +1,131 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/gookit/slog"
-	"github.com/kstsm/pvz-service/config"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"math"
 	"time"
 )
 
 const tokenExpiry = time.Hour * 24
 
-func GenerateToken(role string) (string, error) {
-	secretKey := []byte(config.Config.JWT.JWTSecret)
+// Tokens выпускает и проверяет JWT, подписанные общим секретом.
+type Tokens struct {
+	secret []byte
+}
+
+func NewTokens(secret string) *Tokens {
+	return &Tokens{secret: []byte(secret)}
+}
+
+func (t *Tokens) Generate(ctx context.Context, userID uuid.UUID, role string, tenantID uuid.UUID) (string, error) {
+	secretKey := t.secret
+	now := time.Now()
 
+	// iat пишется с миллисекундами: отзыв сравнивает его с моментом смены
+	// пароля, а секундной точности не хватает для входа сразу после смены.
 	claims := jwt.MapClaims{
-		"role": role,
-		"exp":  time.Now().Add(tokenExpiry).Unix(),
+		"user_id":   userID.String(),
+		"role":      role,
+		"tenant_id": tenantID.String(),
+		"iat":       float64(now.UnixMilli()) / 1000,
+		"exp":       now.Add(tokenExpiry).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString(secretKey)
 	if err != nil {
-		slog.Error("Ошибка при подписании токена: %v", err)
+		slog.ErrorContext(ctx, "Ошибка при подписании токена", "error", err)
 		return "", err
 	}
 
 	return tokenString, nil
 }
 
-func ValidateToken(tokenString string) (string, error) {
-	secretKey := []byte(config.Config.JWT.JWTSecret)
+func (t *Tokens) Validate(ctx context.Context, tokenString string) (models.TokenClaims, error) {
+	secretKey := t.secret
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			slog.Error("Ошибка: неверный метод подписи", "alg", token.Header["alg"])
+			slog.ErrorContext(ctx, "Ошибка: неверный метод подписи", "alg", token.Header["alg"])
 			return nil, errors.New("неверный метод подписи")
 		}
 		return secretKey, nil
 	})
 	if err != nil {
-		slog.Error("Ошибка при валидации токена", "error", err)
-		return "", err
+		slog.ErrorContext(ctx, "Ошибка при валидации токена", "error", err)
+		return models.TokenClaims{}, err
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		slog.Error("Неверный токен или повреждённые данные")
-		return "", errors.New("неверный токен")
+		slog.ErrorContext(ctx, "Неверный токен или повреждённые данные")
+		return models.TokenClaims{}, errors.New("неверный токен")
 	}
 
 	expFloat, ok := claims["exp"].(float64)
 	if !ok {
-		slog.Error("Поле exp отсутствует или неверного типа")
-		return "", errors.New("поле exp отсутствует или неверного типа")
+		slog.ErrorContext(ctx, "Поле exp отсутствует или неверного типа")
+		return models.TokenClaims{}, errors.New("поле exp отсутствует или неверного типа")
 	}
 	if time.Now().Unix() > int64(expFloat) {
-		slog.Error("Токен истёк")
-		return "", errors.New("токен истёк")
+		slog.ErrorContext(ctx, "Токен истёк")
+		return models.TokenClaims{}, errors.New("токен истёк")
 	}
 
 	role, ok := claims["role"].(string)
 	if !ok {
-		slog.Error("Поле role отсутствует или неверного типа")
-		return "", errors.New("поле role отсутствует или неверного типа")
+		slog.ErrorContext(ctx, "Поле role отсутствует или неверного типа")
+		return models.TokenClaims{}, errors.New("поле role отсутствует или неверного типа")
+	}
+
+	// Токены, выпущенные до появления user_id, остаются валидными:
+	// идентификатор пользователя в них просто не заполнен.
+	var userID uuid.UUID
+	if rawUserID, exists := claims["user_id"]; exists {
+		userIDStr, ok := rawUserID.(string)
+		if !ok {
+			slog.ErrorContext(ctx, "Поле user_id неверного типа")
+			return models.TokenClaims{}, errors.New("поле user_id неверного типа")
+		}
+		userID, err = uuid.Parse(userIDStr)
+		if err != nil {
+			slog.ErrorContext(ctx, "Поле user_id не является UUID", "error", err)
+			return models.TokenClaims{}, errors.New("поле user_id не является UUID")
+		}
+	}
+
+	// Токены, выпущенные до появления арендаторов, относятся к арендатору по умолчанию.
+	tenantID := tenant.DefaultID
+	if rawTenantID, exists := claims["tenant_id"]; exists {
+		tenantIDStr, ok := rawTenantID.(string)
+		if !ok {
+			slog.ErrorContext(ctx, "Поле tenant_id неверного типа")
+			return models.TokenClaims{}, errors.New("поле tenant_id неверного типа")
+		}
+		tenantID, err = uuid.Parse(tenantIDStr)
+		if err != nil {
+			slog.ErrorContext(ctx, "Поле tenant_id не является UUID", "error", err)
+			return models.TokenClaims{}, errors.New("поле tenant_id не является UUID")
+		}
+	}
+
+	// У токенов, выпущенных до появления iat, момент выпуска не известен.
+	var issuedAt time.Time
+	if rawIssuedAt, exists := claims["iat"]; exists {
+		iatFloat, ok := rawIssuedAt.(float64)
+		if !ok {
+			slog.ErrorContext(ctx, "Поле iat неверного типа")
+			return models.TokenClaims{}, errors.New("поле iat неверного типа")
+		}
+		issuedAt = time.UnixMilli(int64(math.Round(iatFloat * 1000)))
 	}
 
-	return role, nil
+	return models.TokenClaims{UserID: userID, Role: role, TenantID: tenantID, IssuedAt: issuedAt}, nil
 }
@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"context"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/kstsm/pvz-service/config"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/tenant"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -11,10 +13,11 @@ import (
 var testSecret = "test-secret"
 
 func TestGenerateToken(t *testing.T) {
-	config.Config.JWT.JWTSecret = testSecret
+	tokens := NewTokens(testSecret)
 
 	t.Run("успешная генерация токена", func(t *testing.T) {
-		token, err := GenerateToken("employee")
+		userID, tenantID := uuid.New(), uuid.New()
+		token, err := tokens.Generate(context.Background(), userID, "employee", tenantID)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
 
@@ -27,23 +30,112 @@ func TestGenerateToken(t *testing.T) {
 		claims, ok := parsed.Claims.(jwt.MapClaims)
 		assert.True(t, ok)
 		assert.Equal(t, "employee", claims["role"])
+		assert.Equal(t, userID.String(), claims["user_id"])
+		assert.Equal(t, tenantID.String(), claims["tenant_id"])
 	})
 }
 
 func TestValidateToken(t *testing.T) {
-	config.Config.JWT.JWTSecret = "my_secret"
+	tokens := NewTokens(testSecret)
 
 	t.Run("валидный токен", func(t *testing.T) {
-		token, err := GenerateToken("admin")
+		userID, tenantID := uuid.New(), uuid.New()
+		token, err := tokens.Generate(context.Background(), userID, "admin", tenantID)
 		assert.NoError(t, err)
 
-		role, err := ValidateToken(token)
+		claims, err := tokens.Validate(context.Background(), token)
 		assert.NoError(t, err)
-		assert.Equal(t, "admin", role)
+		assert.Equal(t, "admin", claims.Role)
+		assert.Equal(t, userID, claims.UserID)
+		assert.Equal(t, tenantID, claims.TenantID)
+		assert.WithinDuration(t, time.Now(), claims.IssuedAt, 2*time.Second)
+	})
+
+	t.Run("токен без iat", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"user_id": uuid.New().String(),
+			"role":    "employee",
+			"exp":     time.Now().Add(10 * time.Minute).Unix(),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenStr, _ := token.SignedString([]byte(testSecret))
+
+		parsed, err := tokens.Validate(context.Background(), tokenStr)
+		assert.NoError(t, err)
+		assert.True(t, parsed.IssuedAt.IsZero())
+	})
+
+	t.Run("iat с миллисекундами", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"user_id": uuid.New().String(),
+			"role":    "employee",
+			"iat":     1760875200.123,
+			"exp":     time.Now().Add(10 * time.Minute).Unix(),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenStr, _ := token.SignedString([]byte(testSecret))
+
+		parsed, err := tokens.Validate(context.Background(), tokenStr)
+		assert.NoError(t, err)
+		assert.Equal(t, time.UnixMilli(1760875200123), parsed.IssuedAt)
+	})
+
+	t.Run("токен без tenant_id", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"user_id": uuid.New().String(),
+			"role":    "employee",
+			"exp":     time.Now().Add(10 * time.Minute).Unix(),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenStr, _ := token.SignedString([]byte(testSecret))
+
+		parsed, err := tokens.Validate(context.Background(), tokenStr)
+		assert.NoError(t, err)
+		assert.Equal(t, tenant.DefaultID, parsed.TenantID)
+	})
+
+	t.Run("невалидный tenant_id", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"role":      "employee",
+			"tenant_id": "acme",
+			"exp":       time.Now().Add(10 * time.Minute).Unix(),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenStr, _ := token.SignedString([]byte(testSecret))
+
+		_, err := tokens.Validate(context.Background(), tokenStr)
+		assert.EqualError(t, err, "поле tenant_id не является UUID")
+	})
+
+	t.Run("токен без user_id", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"role": "employee",
+			"exp":  time.Now().Add(10 * time.Minute).Unix(),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenStr, _ := token.SignedString([]byte(testSecret))
+
+		parsed, err := tokens.Validate(context.Background(), tokenStr)
+		assert.NoError(t, err)
+		assert.Equal(t, "employee", parsed.Role)
+		assert.Equal(t, uuid.Nil, parsed.UserID)
+	})
+
+	t.Run("невалидный user_id", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"user_id": "not-a-uuid",
+			"role":    "employee",
+			"exp":     time.Now().Add(10 * time.Minute).Unix(),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenStr, _ := token.SignedString([]byte(testSecret))
+
+		_, err := tokens.Validate(context.Background(), tokenStr)
+		assert.EqualError(t, err, "поле user_id не является UUID")
 	})
 
 	t.Run("невалидный токен", func(t *testing.T) {
-		_, err := ValidateToken("this.is.not.valid")
+		_, err := tokens.Validate(context.Background(), "this.is.not.valid")
 		assert.Error(t, err)
 	})
 
@@ -52,9 +144,9 @@ func TestValidateToken(t *testing.T) {
 			"exp": time.Now().Add(10 * time.Minute).Unix(),
 		}
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenStr, _ := token.SignedString([]byte(config.Config.JWT.JWTSecret))
+		tokenStr, _ := token.SignedString([]byte(testSecret))
 
-		_, err := ValidateToken(tokenStr)
+		_, err := tokens.Validate(context.Background(), tokenStr)
 		assert.EqualError(t, err, "поле role отсутствует или неверного типа")
 	})
 
@@ -66,7 +158,7 @@ func TestValidateToken(t *testing.T) {
 		token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
 		tokenStr, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
 
-		_, err := ValidateToken(tokenStr)
+		_, err := tokens.Validate(context.Background(), tokenStr)
 		assert.EqualError(t, err, "неверный метод подписи")
 	})
 }
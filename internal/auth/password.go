@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes — предел bcrypt: более длинный пароль не хешируется.
+const maxPasswordBytes = 72
+
+// PasswordPolicy задаёт требования к новому паролю.
+type PasswordPolicy struct {
+	MinLength        int
+	RequireLetter    bool
+	RequireDigit     bool
+	RequireMixedCase bool
+	RequireSpecial   bool
+}
+
+// DefaultPasswordPolicy действует, если политика не задана конфигурацией.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:     8,
+	RequireLetter: true,
+	RequireDigit:  true,
+}
+
+// Validate проверяет пароль по всем правилам сразу и перечисляет нарушенные
+// в ошибке, оборачивающей apperrors.ErrWeakPassword.
+func (p PasswordPolicy) Validate(password string) error {
+	var letter, digit, lower, upper, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+			lower = lower || unicode.IsLower(r)
+			upper = upper || unicode.IsUpper(r)
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			special = true
+		}
+	}
+
+	var problems []string
+	if utf8.RuneCountInString(password) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("короче %d символов", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("длиннее %d байт", maxPasswordBytes))
+	}
+	if p.RequireLetter && !letter {
+		problems = append(problems, "нет букв")
+	}
+	if p.RequireDigit && !digit {
+		problems = append(problems, "нет цифр")
+	}
+	if p.RequireMixedCase && !(lower && upper) {
+		problems = append(problems, "нет строчных и заглавных букв одновременно")
+	}
+	if p.RequireSpecial && !special {
+		problems = append(problems, "нет спецсимволов")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", apperrors.ErrWeakPassword, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
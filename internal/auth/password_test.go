@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireLetter: true, RequireDigit: true, RequireMixedCase: true, RequireSpecial: true}
+
+	tests := []struct {
+		name          string
+		policy        PasswordPolicy
+		password      string
+		expectedError string
+	}{
+		{"пароль по умолчанию подходит", DefaultPasswordPolicy, "password123", ""},
+		{"кириллица считается буквами", DefaultPasswordPolicy, "пароль2026", ""},
+		{"слишком короткий", DefaultPasswordPolicy, "pass1", "короче 8 символов"},
+		{"нет цифр", DefaultPasswordPolicy, "password", "нет цифр"},
+		{"нет букв", DefaultPasswordPolicy, "12345678", "нет букв"},
+		{"длиннее предела bcrypt", DefaultPasswordPolicy, strings.Repeat("a1", 40), "длиннее 72 байт"},
+		{"строгая политика выполнена", strict, "Pass-word-2026", ""},
+		{"все нарушения перечислены", strict, "password", "короче 10 символов, нет цифр, нет строчных и заглавных букв одновременно, нет спецсимволов"},
+		{"без требований", PasswordPolicy{}, "1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password)
+
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, apperrors.ErrWeakPassword)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestNewUserToken(t *testing.T) {
+	token, hash, err := NewUserToken()
+
+	assert.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashUserToken(token))
+
+	other, _, err := NewUserToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewUserToken создаёт одноразовый код для письма пользователю. В базе
+// хранится только hash, сам код знает лишь получатель письма.
+func NewUserToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("не удалось создать код: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashUserToken(token), nil
+}
+
+// HashUserToken возвращает хеш кода, по которому он ищется в базе.
+func HashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
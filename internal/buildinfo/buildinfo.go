@@ -0,0 +1,56 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version и Commit задаются при сборке:
+//
+//	go build -ldflags "-X github.com/kstsm/pvz-service/internal/buildinfo.Version=v1.2.0 -X github.com/kstsm/pvz-service/internal/buildinfo.Commit=abc123"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get возвращает сведения о сборке. Если коммит не передан через ldflags,
+// он берётся из данных VCS, которые go build встраивает в бинарник.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			info.BuildTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+
+	return info
+}
@@ -0,0 +1,18 @@
+// Package cache предоставляет кэш «ключ — значение» для готовых ответов
+// сервиса: в памяти процесса или в Redis.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache хранит сериализованные значения. Get сообщает о промахе через false,
+// а не через ошибку; ошибка означает недоступность самого кэша.
+// ttl <= 0 в Set означает значение без срока жизни. Delete отсутствующего
+// ключа не считается ошибкой.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	assert.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+
+	// Чтение делает "a" свежим, поэтому вытесняется "b".
+	_, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.NoError(t, c.Set(ctx, "c", []byte("3"), 0))
+
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+
+	value, ok, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	_, ok, _ = c.Get(ctx, "c")
+	assert.True(t, ok)
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	c := NewLRU(10)
+	c.now = func() time.Time { return now }
+
+	assert.NoError(t, c.Set(ctx, "short", []byte("1"), time.Minute))
+	assert.NoError(t, c.Set(ctx, "forever", []byte("2"), 0))
+
+	now = now.Add(59 * time.Second)
+	_, ok, _ := c.Get(ctx, "short")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok, _ = c.Get(ctx, "short")
+	assert.False(t, ok)
+
+	now = now.Add(24 * time.Hour)
+	_, ok, _ = c.Get(ctx, "forever")
+	assert.True(t, ok)
+}
+
+func TestLRUOverwrite(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(1)
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	assert.NoError(t, c.Set(ctx, "a", []byte("2"), 0))
+
+	value, ok, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), value)
+}
+
+func TestLRUDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	assert.NoError(t, c.Delete(ctx, "a"))
+	assert.NoError(t, c.Delete(ctx, "missing"))
+
+	_, ok, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
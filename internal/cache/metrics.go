@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"time"
+)
+
+var requests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "pvz_cache_requests_total",
+	Help: "Обращения к кэшу по результату: hit, miss или error.",
+}, []string{"cache", "result"})
+
+// Instrumented считает попадания, промахи и ошибки чтения кэша под именем name.
+func Instrumented(name string, c Cache) Cache {
+	return &instrumented{
+		Cache:  c,
+		hits:   requests.WithLabelValues(name, "hit"),
+		misses: requests.WithLabelValues(name, "miss"),
+		errors: requests.WithLabelValues(name, "error"),
+	}
+}
+
+type instrumented struct {
+	Cache
+	hits   prometheus.Counter
+	misses prometheus.Counter
+	errors prometheus.Counter
+}
+
+func (c *instrumented) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := c.Cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		c.errors.Inc()
+	case ok:
+		c.hits.Inc()
+	default:
+		c.misses.Inc()
+	}
+
+	return value, ok, err
+}
+
+func (c *instrumented) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.Cache.Set(ctx, key, value, ttl)
+}
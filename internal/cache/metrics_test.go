@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInstrumented(t *testing.T) {
+	ctx := context.Background()
+	c := Instrumented("test", NewLRU(10))
+
+	_, _, _ = c.Get(ctx, "a")
+	assert.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	_, _, _ = c.Get(ctx, "a")
+	_, _, _ = c.Get(ctx, "a")
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(requests.WithLabelValues("test", "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(requests.WithLabelValues("test", "miss")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(requests.WithLabelValues("test", "error")))
+}
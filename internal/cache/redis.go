@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// Redis — кэш в Redis или любом сервере, совместимом с его протоколом.
+// Значения живут до истечения ttl, вытеснение настраивается на сервере.
+type Redis struct {
+	client redis.UniversalClient
+}
+
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{client: client}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *Redis) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	c := NewRedis(client)
+
+	_, ok, err := c.Get(ctx, "missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Set(ctx, "short", []byte("1"), time.Minute))
+	assert.NoError(t, c.Set(ctx, "forever", []byte("2"), 0))
+
+	value, ok, err := c.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	server.FastForward(time.Minute)
+
+	_, ok, err = c.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = c.Get(ctx, "forever")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, c.Delete(ctx, "forever"))
+	assert.NoError(t, c.Delete(ctx, "missing"))
+	_, ok, err = c.Get(ctx, "forever")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	server.Close()
+	_, _, err = c.Get(ctx, "forever")
+	assert.Error(t, err)
+}
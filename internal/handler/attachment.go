@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/middleware"
+	"github.com/kstsm/pvz-service/models"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+const (
+	attachmentsFormField    = "photos"
+	maxAttachmentsPerUpload = 10
+	maxAttachmentSize       = 10 << 20
+	maxAttachmentsRequest   = maxAttachmentsPerUpload*maxAttachmentSize + 1<<20
+)
+
+func isAllowedAttachmentType(contentType string) bool {
+	allowedTypes := map[string]bool{
+		"image/jpeg": true,
+		"image/png":  true,
+		"image/webp": true,
+	}
+	return allowedTypes[contentType]
+}
+
+func (h Handler) uploadProductAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	productIDParam := chi.URLParam(r, "productId")
+	productID, err := uuid.Parse(productIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID товара при загрузке вложений", "productId", productIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора товара")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentsRequest)
+	if err = r.ParseMultipartForm(maxAttachmentSize); err != nil {
+		slog.WarnContext(r.Context(), "Ошибка разбора multipart-запроса", "productId", productID, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Ожидается multipart/form-data с фотографиями не больше 10 МБ")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	headers := r.MultipartForm.File[attachmentsFormField]
+	if len(headers) == 0 || len(headers) > maxAttachmentsPerUpload {
+		writeErrorResponse(w, http.StatusBadRequest,
+			fmt.Sprintf("Нужно приложить от 1 до %d фотографий в поле %q", maxAttachmentsPerUpload, attachmentsFormField))
+		return
+	}
+
+	uploads := make([]models.AttachmentUpload, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Не удалось открыть загруженный файл", "fileName", header.Filename, "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Не удалось прочитать файл "+header.Filename)
+			return
+		}
+		defer file.Close()
+
+		upload, err := readAttachmentUpload(header, file)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		uploads = append(uploads, upload)
+	}
+
+	employeeID, _ := middleware.UserIDFromContext(r.Context())
+
+	attachments, err := h.service.AddProductAttachments(r.Context(), productID, uploads, employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrProductNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Товар не найден")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за ПВЗ товара")
+		case errors.Is(err, apperrors.ErrAttachmentsDisabled):
+			writeErrorResponse(w, http.StatusServiceUnavailable, "Загрузка фотографий недоступна")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при сохранении вложений товара", "productId", productID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось сохранить фотографии")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusCreated, attachments)
+}
+
+// readAttachmentUpload проверяет размер файла и определяет его тип по содержимому,
+// не доверяя Content-Type, который прислал клиент.
+func readAttachmentUpload(header *multipart.FileHeader, file multipart.File) (models.AttachmentUpload, error) {
+	if header.Size > maxAttachmentSize {
+		return models.AttachmentUpload{}, fmt.Errorf("Файл %s больше 10 МБ", header.Filename)
+	}
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return models.AttachmentUpload{}, fmt.Errorf("Не удалось прочитать файл %s", header.Filename)
+	}
+
+	contentType := http.DetectContentType(sniff[:n])
+	if !isAllowedAttachmentType(contentType) {
+		return models.AttachmentUpload{}, fmt.Errorf("Файл %s не является изображением JPEG, PNG или WebP", header.Filename)
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return models.AttachmentUpload{}, fmt.Errorf("Не удалось прочитать файл %s", header.Filename)
+	}
+
+	return models.AttachmentUpload{
+		FileName:    header.Filename,
+		ContentType: contentType,
+		Size:        header.Size,
+		Content:     file,
+	}, nil
+}
+
+func (h Handler) getProductAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	productIDParam := chi.URLParam(r, "productId")
+	productID, err := uuid.Parse(productIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID товара при получении вложений", "productId", productIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора товара")
+		return
+	}
+
+	attachments, err := h.service.GetProductAttachments(r.Context(), productID, employeeScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrProductNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Товар не найден")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за ПВЗ товара")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении вложений товара", "productId", productID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить фотографии товара")
+		}
+		return
+	}
+
+	sendListResponse(w, h.listEnvelope, attachments)
+}
+
+func (h Handler) downloadProductAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "productId"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора товара")
+		return
+	}
+
+	attachmentID, err := uuid.Parse(chi.URLParam(r, "attachmentId"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора вложения")
+		return
+	}
+
+	attachment, content, err := h.service.OpenProductAttachment(r.Context(), productID, attachmentID, employeeScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrAttachmentNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Вложение не найдено")
+		case errors.Is(err, apperrors.ErrProductNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Товар не найден")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за ПВЗ товара")
+		case errors.Is(err, apperrors.ErrAttachmentsDisabled):
+			writeErrorResponse(w, http.StatusServiceUnavailable, "Загрузка фотографий недоступна")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении вложения", "attachmentId", attachmentID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить фотографию")
+		}
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err = io.Copy(w, content); err != nil {
+		slog.ErrorContext(r.Context(), "Ошибка при отправке вложения", "attachmentId", attachmentID, "error", err)
+	}
+}
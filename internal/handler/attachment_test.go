@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func multipartBody(t *testing.T, field string, files map[string][]byte) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile(field, name)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return body, writer.FormDataContentType()
+}
+
+func TestUploadProductAttachmentsHandler(t *testing.T) {
+	productID := uuid.New()
+
+	tests := []struct {
+		name           string
+		field          string
+		files          map[string][]byte
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Успешная загрузка фотографии",
+			field: "photos",
+			files: map[string][]byte{"box.png": pngHeader},
+			mockService: func(m *MockService) {
+				m.On("AddProductAttachments", mock.Anything, productID,
+					mock.MatchedBy(func(uploads []models.AttachmentUpload) bool {
+						return len(uploads) == 1 && uploads[0].FileName == "box.png" && uploads[0].ContentType == "image/png"
+					}), uuid.Nil).
+					Return([]models.ProductAttachment{{ID: uuid.New(), ProductID: productID, FileName: "box.png"}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"fileName":"box.png"`,
+		},
+		{
+			name:           "Файл не является изображением",
+			field:          "photos",
+			files:          map[string][]byte{"note.txt": []byte("просто текст")},
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Файл note.txt не является изображением JPEG, PNG или WebP"`,
+		},
+		{
+			name:           "Нет файлов в поле photos",
+			field:          "files",
+			files:          map[string][]byte{"box.png": pngHeader},
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Нужно приложить от 1 до 10 фотографий в поле \"photos\""`,
+		},
+		{
+			name:  "Товар не найден",
+			field: "photos",
+			files: map[string][]byte{"box.png": pngHeader},
+			mockService: func(m *MockService) {
+				m.On("AddProductAttachments", mock.Anything, productID, mock.Anything, uuid.Nil).
+					Return([]models.ProductAttachment(nil), apperrors.ErrProductNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Товар не найден"`,
+		},
+		{
+			name:  "Сотрудник не закреплён за ПВЗ товара",
+			field: "photos",
+			files: map[string][]byte{"box.png": pngHeader},
+			mockService: func(m *MockService) {
+				m.On("AddProductAttachments", mock.Anything, productID, mock.Anything, uuid.Nil).
+					Return([]models.ProductAttachment(nil), apperrors.ErrEmployeeNotAssigned)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"message":"Сотрудник не закреплён за ПВЗ товара"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Post("/products/{productId}/attachments", h.uploadProductAttachmentsHandler)
+
+			body, contentType := multipartBody(t, tt.field, tt.files)
+			req := httptest.NewRequest(http.MethodPost, "/products/"+productID.String()+"/attachments", body)
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDownloadProductAttachmentHandler(t *testing.T) {
+	productID, attachmentID := uuid.New(), uuid.New()
+
+	t.Run("Успешное скачивание", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("OpenProductAttachment", mock.Anything, productID, attachmentID, (*uuid.UUID)(nil)).Return(
+			models.ProductAttachment{ID: attachmentID, FileName: "box.png", ContentType: "image/png"},
+			io.NopCloser(bytes.NewReader(pngHeader)), nil)
+
+		h := Handler{service: mockService}
+		router := chi.NewRouter()
+		router.Get("/products/{productId}/attachments/{attachmentId}", h.downloadProductAttachmentHandler)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+			"/products/"+productID.String()+"/attachments/"+attachmentID.String(), nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+		assert.Equal(t, pngHeader, rec.Body.Bytes())
+	})
+
+	t.Run("Вложение не найдено", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("OpenProductAttachment", mock.Anything, productID, attachmentID, (*uuid.UUID)(nil)).
+			Return(models.ProductAttachment{}, nil, apperrors.ErrAttachmentNotFound)
+
+		h := Handler{service: mockService}
+		router := chi.NewRouter()
+		router.Get("/products/{productId}/attachments/{attachmentId}", h.downloadProductAttachmentHandler)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+			"/products/"+productID.String()+"/attachments/"+attachmentID.String(), nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"message":"Вложение не найдено"`)
+	})
+	t.Run("Сотрудник не закреплён за ПВЗ товара", func(t *testing.T) {
+		employeeID := uuid.New()
+		mockService := new(MockService)
+		mockService.On("OpenProductAttachment", mock.Anything, productID, attachmentID, &employeeID).
+			Return(models.ProductAttachment{}, nil, apperrors.ErrEmployeeNotAssigned)
+
+		h := Handler{service: mockService}
+		router := chi.NewRouter()
+		router.Get("/products/{productId}/attachments/{attachmentId}", h.downloadProductAttachmentHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/products/"+productID.String()+"/attachments/"+attachmentID.String(), nil)
+		ctx := context.WithValue(req.Context(), "role", "employee")
+		ctx = context.WithValue(ctx, "userID", employeeID)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(ctx))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"message":"Сотрудник не закреплён за ПВЗ товара"`)
+	})
+}
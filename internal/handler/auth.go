@@ -1,51 +1,102 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"github.com/gookit/slog"
 	"github.com/kstsm/pvz-service/internal/apperrors"
-	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/middleware"
+	"github.com/kstsm/pvz-service/internal/tenant"
 	"github.com/kstsm/pvz-service/models"
+	"log/slog"
 	"net/http"
 )
 
+// authenticate проверяет подпись и срок токена, а затем то, что его не
+// отозвала смена пароля владельца.
+func (h Handler) authenticate(ctx context.Context, token string) (models.TokenClaims, error) {
+	claims, err := h.tokens.Validate(ctx, token)
+	if err != nil {
+		return models.TokenClaims{}, err
+	}
+
+	if err = h.service.CheckTokenRevoked(tenant.WithID(ctx, claims.TenantID), claims); err != nil {
+		if !errors.Is(err, apperrors.ErrTokenRevoked) {
+			slog.ErrorContext(ctx, "Ошибка проверки отзыва токена", "userId", claims.UserID, "error", err)
+		}
+		return models.TokenClaims{}, err
+	}
+
+	return claims, nil
+}
+
 func (h Handler) dummyLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if token, ok := h.dummyLogin(w, r); ok {
+		sendJSONResponse(w, http.StatusOK, token)
+	}
+}
+
+func (h Handler) dummyLoginV2Handler(w http.ResponseWriter, r *http.Request) {
+	if token, ok := h.dummyLogin(w, r); ok {
+		sendJSONResponse(w, http.StatusOK, models.DummyLoginResponse{Token: token})
+	}
+}
+
+// dummyLogin выдаёт тестовый токен. При ошибке ответ уже записан и ok = false.
+func (h Handler) dummyLogin(w http.ResponseWriter, r *http.Request) (string, bool) {
 	var req models.DummyLoginRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Error("Недопустимое тело запроса", "error", err)
+		slog.ErrorContext(r.Context(), "Недопустимое тело запроса", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
-		return
+		return "", false
 	}
 
 	if !isValidRole(req.Role) {
-		slog.Error("Недопустимая роль", "role", req.Role)
+		slog.ErrorContext(r.Context(), "Недопустимая роль", "role", req.Role)
 		writeErrorResponse(w, http.StatusBadRequest, "Недопустимая роль")
-		return
+		return "", false
+	}
+
+	t, err := h.service.GetTenant(r.Context(), req.Tenant)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrTenantNotFound) {
+			writeErrorResponse(w, http.StatusBadRequest, "Арендатор не найден")
+			return "", false
+		}
+		slog.ErrorContext(r.Context(), "Ошибка при получении арендатора", "tenant", req.Tenant, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return "", false
+	}
+
+	userID, err := h.service.EnsureDummyUser(tenant.WithID(r.Context(), t.ID), req.Role)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Ошибка при получении тестового пользователя", "role", req.Role, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		return "", false
 	}
 
-	token, err := auth.GenerateToken(req.Role)
+	token, err := h.tokens.Generate(r.Context(), userID, req.Role, t.ID)
 	if err != nil {
-		slog.Error("Ошибка генерации токена", "error", err)
+		slog.ErrorContext(r.Context(), "Ошибка генерации токена", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Пользователь не найден")
-		return
+		return "", false
 	}
 
-	sendJSONResponse(w, http.StatusOK, token)
+	return token, true
 }
 
 func (h Handler) registerUserHandler(w http.ResponseWriter, r *http.Request) {
 	var req models.UserRegisterReq
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Error("Ошибка при декодировании тела запроса", "error", err)
+		slog.ErrorContext(r.Context(), "Ошибка при декодировании тела запроса", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
 		return
 	}
 
 	if !isValidRole(req.Role) {
-		slog.Error("Недопустимая роль", "role", req.Role)
+		slog.ErrorContext(r.Context(), "Недопустимая роль", "role", req.Role)
 		writeErrorResponse(w, http.StatusBadRequest, "Недопустимая роль")
 		return
 	}
@@ -55,10 +106,14 @@ func (h Handler) registerUserHandler(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, apperrors.ErrEmailAlreadyExists):
 			writeErrorResponse(w, http.StatusConflict, "Пользователь с таким email уже существует")
+		case errors.Is(err, apperrors.ErrWeakPassword):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, apperrors.ErrTenantNotFound):
+			writeErrorResponse(w, http.StatusBadRequest, "Арендатор не найден")
 		default:
 			writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при регистрации пользователя")
 		}
-		slog.Error("Ошибка регистрации пользователя", "email", req.Email, "error", err)
+		slog.ErrorContext(r.Context(), "Ошибка регистрации пользователя", "email", req.Email, "error", err)
 		return
 	}
 
@@ -66,27 +121,170 @@ func (h Handler) registerUserHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h Handler) loginUserHandler(w http.ResponseWriter, r *http.Request) {
+	if token, ok := h.loginUser(w, r, false); ok {
+		sendJSONResponse(w, http.StatusOK, token)
+	}
+}
+
+func (h Handler) loginUserV2Handler(w http.ResponseWriter, r *http.Request) {
+	if token, ok := h.loginUser(w, r, true); ok {
+		sendJSONResponse(w, http.StatusOK, models.LoginResponse{Token: token})
+	}
+}
+
+// loginUser проверяет email и пароль и возвращает токен. При ошибке ответ уже
+// записан и ok = false. С hideUnknownEmail неизвестный email получает тот же
+// 401, что и неверный пароль; v1 сохраняет прежний ответ 404.
+func (h Handler) loginUser(w http.ResponseWriter, r *http.Request, hideUnknownEmail bool) (string, bool) {
 	var req models.UserLoginReq
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Error("Ошибка при декодировании тела запроса", "error", err)
+		slog.ErrorContext(r.Context(), "Ошибка при декодировании тела запроса", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
-		return
+		return "", false
 	}
 
-	userRole, err := h.service.LoginUser(r.Context(), req)
+	token, err := h.service.LoginUser(r.Context(), req)
 	if err != nil {
 		switch {
-		case errors.Is(err, apperrors.ErrInvalidCredentials):
+		case errors.Is(err, apperrors.ErrInvalidCredentials),
+			hideUnknownEmail && errors.Is(err, apperrors.ErrEmailNotFound):
 			writeErrorResponse(w, http.StatusUnauthorized, "Неверный email или пароль")
+		case errors.Is(err, apperrors.ErrEmailNotVerified):
+			writeErrorResponse(w, http.StatusForbidden, "Email не подтверждён: перейдите по коду из письма или запросите его повторно")
 		case errors.Is(err, apperrors.ErrEmailNotFound):
 			writeErrorResponse(w, http.StatusNotFound, "Пользователь с таким email не найден")
+		case errors.Is(err, apperrors.ErrTenantNotFound):
+			writeErrorResponse(w, http.StatusBadRequest, "Арендатор не найден")
 		default:
-			slog.Error("Ошибка авторизации пользователя", "email", req.Email, "error", err)
+			slog.ErrorContext(r.Context(), "Ошибка авторизации пользователя", "email", req.Email, "error", err)
 			writeErrorResponse(w, http.StatusInternalServerError, "Ошибка при авторизации пользователя")
 		}
+		return "", false
+	}
+
+	return token, true
+}
+
+func (h Handler) verifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.EmailVerificationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	if err := h.service.VerifyEmail(r.Context(), req); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidUserToken):
+			writeErrorResponse(w, http.StatusBadRequest, "Код недействителен или устарел")
+		case errors.Is(err, apperrors.ErrTenantNotFound):
+			writeErrorResponse(w, http.StatusBadRequest, "Арендатор не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка подтверждения email", "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось подтвердить email")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h Handler) resendEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ResendVerificationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	if err := h.service.ResendEmailVerification(r.Context(), req); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrTenantNotFound):
+			writeErrorResponse(w, http.StatusBadRequest, "Арендатор не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка повторной отправки кода подтверждения", "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось отправить письмо")
+		}
+		return
+	}
+
+	// Ответ одинаков для известных и неизвестных адресов.
+	sendJSONResponse(w, http.StatusAccepted, nil)
+}
+
+func (h Handler) requestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.PasswordResetReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(r.Context(), req); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrTenantNotFound):
+			writeErrorResponse(w, http.StatusBadRequest, "Арендатор не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка запроса сброса пароля", "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось отправить письмо")
+		}
+		return
+	}
+
+	// Ответ одинаков для известных и неизвестных адресов.
+	sendJSONResponse(w, http.StatusAccepted, nil)
+}
+
+func (h Handler) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.PasswordResetConfirmReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrWeakPassword):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, apperrors.ErrInvalidUserToken):
+			writeErrorResponse(w, http.StatusBadRequest, "Код недействителен или устарел")
+		case errors.Is(err, apperrors.ErrTenantNotFound):
+			writeErrorResponse(w, http.StatusBadRequest, "Арендатор не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка сброса пароля", "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось сбросить пароль")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h Handler) changePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "Токен не содержит идентификатор пользователя")
+		return
+	}
+
+	var req models.ChangePasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое тело запроса")
+		return
+	}
+
+	if err := h.service.ChangePassword(r.Context(), userID, req); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrWeakPassword):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, apperrors.ErrInvalidCredentials):
+			writeErrorResponse(w, http.StatusBadRequest, "Неверный текущий пароль")
+		case errors.Is(err, apperrors.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Пользователь не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка смены пароля", "userId", userID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось сменить пароль")
+		}
 		return
 	}
 
-	sendJSONResponse(w, http.StatusOK, userRole)
+	sendJSONResponse(w, http.StatusNoContent, nil)
 }
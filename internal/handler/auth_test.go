@@ -2,10 +2,15 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/tenant"
 	"github.com/kstsm/pvz-service/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -90,6 +95,19 @@ func TestRegisterUserHandler(t *testing.T) {
 			expectedStatus: http.StatusConflict,
 			expectedError:  "Пользователь с таким email уже существует",
 		},
+		{
+			name: "Слабый_пароль",
+			reqBody: models.UserRegisterReq{
+				Email:    "client@example.com",
+				Password: "123",
+				Role:     "client",
+			},
+			mockService: func() *MockService {
+				return &MockService{}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "пароль не соответствует требованиям: короче 8 символов",
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,8 +149,11 @@ func TestRegisterUserHandler(t *testing.T) {
 }
 
 func TestDummyLoginHandler(t *testing.T) {
+	dummyUserID := uuid.New()
+
 	type args struct {
-		Role string `json:"role"`
+		Role   string `json:"role"`
+		Tenant string `json:"tenant,omitempty"`
 	}
 
 	tests := []struct {
@@ -164,6 +185,11 @@ func TestDummyLoginHandler(t *testing.T) {
 			body:       args{Role: "admin"},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "Неизвестный арендатор",
+			body:       args{Role: "employee", Tenant: "unknown"},
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "Пустое тело запроса",
 			body:       args{},
@@ -177,7 +203,10 @@ func TestDummyLoginHandler(t *testing.T) {
 			var err error
 
 			mockService := new(MockService)
-			handler := Handler{service: mockService}
+			mockService.On("GetTenant", mock.Anything, "").Return(models.Tenant{ID: tenant.DefaultID}, nil).Maybe()
+			mockService.On("GetTenant", mock.Anything, "unknown").Return(models.Tenant{}, apperrors.ErrTenantNotFound).Maybe()
+			mockService.On("EnsureDummyUser", mock.Anything, tt.body.Role).Return(dummyUserID, nil).Maybe()
+			handler := Handler{service: mockService, tokens: auth.NewTokens("test-secret")}
 
 			if tt.name != "Пустое тело запроса" {
 				body, err = json.Marshal(tt.body)
@@ -208,6 +237,8 @@ func TestDummyLoginHandler(t *testing.T) {
 				claims, ok := token.Claims.(jwt.MapClaims)
 				require.True(t, ok)
 				require.Equal(t, tt.wantRole, claims["role"])
+				require.Equal(t, tenant.DefaultID.String(), claims["tenant_id"])
+				require.Equal(t, dummyUserID.String(), claims["user_id"])
 			}
 		})
 	}
@@ -238,6 +269,14 @@ func TestLoginUserHandler(t *testing.T) {
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   `{"message":"Неверный email или пароль"}`,
 		},
+		{
+			name:           "Email не подтверждён",
+			loginReq:       models.UserLoginReq{Email: "new@example.com", Password: "password123"},
+			mockResponse:   "",
+			mockError:      apperrors.ErrEmailNotVerified,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"message":"Email не подтверждён: перейдите по коду из письма или запросите его повторно"}`,
+		},
 		{
 			name:           "Пользователь не найден",
 			loginReq:       models.UserLoginReq{Email: "nonexistent@example.com", Password: "password123"},
@@ -280,3 +319,190 @@ func TestLoginUserHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginUserV2HidesUnknownEmail(t *testing.T) {
+	loginReq := models.UserLoginReq{Email: "nonexistent@example.com", Password: "password123"}
+	mockService := new(MockService)
+	mockService.On("LoginUser", mock.Anything, loginReq).Return("", apperrors.ErrEmailNotFound)
+
+	handler := Handler{service: mockService}
+
+	reqBody, err := json.Marshal(loginReq)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/login", bytes.NewReader(reqBody))
+	w := httptest.NewRecorder()
+
+	handler.loginUserV2Handler(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"message":"Неверный email или пароль"}`, w.Body.String())
+}
+
+func TestEmailAndPasswordResetHandlers(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        func(h Handler) http.HandlerFunc
+		body           string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "Email подтверждён",
+			handler: func(h Handler) http.HandlerFunc { return h.verifyEmailHandler },
+			body:    `{"token":"code"}`,
+			mockService: func(m *MockService) {
+				m.On("VerifyEmail", mock.Anything, models.EmailVerificationReq{Token: "code"}).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "Код подтверждения устарел",
+			handler: func(h Handler) http.HandlerFunc { return h.verifyEmailHandler },
+			body:    `{"token":"old"}`,
+			mockService: func(m *MockService) {
+				m.On("VerifyEmail", mock.Anything, models.EmailVerificationReq{Token: "old"}).Return(apperrors.ErrInvalidUserToken)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Код недействителен или устарел"`,
+		},
+		{
+			name:    "Повторная отправка кода подтверждения",
+			handler: func(h Handler) http.HandlerFunc { return h.resendEmailVerificationHandler },
+			body:    `{"email":"user@example.com"}`,
+			mockService: func(m *MockService) {
+				m.On("ResendEmailVerification", mock.Anything, models.ResendVerificationReq{Email: "user@example.com"}).Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:    "Запрос сброса пароля",
+			handler: func(h Handler) http.HandlerFunc { return h.requestPasswordResetHandler },
+			body:    `{"email":"user@example.com","tenant":"acme"}`,
+			mockService: func(m *MockService) {
+				m.On("RequestPasswordReset", mock.Anything, models.PasswordResetReq{Email: "user@example.com", Tenant: "acme"}).Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:    "Сброс пароля для неизвестного арендатора",
+			handler: func(h Handler) http.HandlerFunc { return h.requestPasswordResetHandler },
+			body:    `{"email":"user@example.com","tenant":"unknown"}`,
+			mockService: func(m *MockService) {
+				m.On("RequestPasswordReset", mock.Anything, mock.Anything).Return(apperrors.ErrTenantNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Арендатор не найден"`,
+		},
+		{
+			name:    "Пароль сброшен",
+			handler: func(h Handler) http.HandlerFunc { return h.resetPasswordHandler },
+			body:    `{"token":"code","password":"new-password1"}`,
+			mockService: func(m *MockService) {
+				m.On("ResetPassword", mock.Anything, models.PasswordResetConfirmReq{Token: "code", Password: "new-password1"}).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:    "Слабый пароль при сбросе",
+			handler: func(h Handler) http.HandlerFunc { return h.resetPasswordHandler },
+			body:    `{"token":"code","password":"123"}`,
+			mockService: func(m *MockService) {
+				m.On("ResetPassword", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: короче 8 символов", apperrors.ErrWeakPassword))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"пароль не соответствует требованиям: короче 8 символов"`,
+		},
+		{
+			name:           "Невалидный JSON",
+			handler:        func(h Handler) http.HandlerFunc { return h.resetPasswordHandler },
+			body:           `{`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Недопустимое тело запроса"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+			h := Handler{service: mockService}
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			tt.handler(h)(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	userID := uuid.New()
+	req := models.ChangePasswordReq{CurrentPassword: "password123", NewPassword: "new-password1"}
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Пароль изменён",
+			userID: userID,
+			mockService: func(m *MockService) {
+				m.On("ChangePassword", mock.Anything, userID, req).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Неверный текущий пароль",
+			userID: userID,
+			mockService: func(m *MockService) {
+				m.On("ChangePassword", mock.Anything, userID, req).Return(apperrors.ErrInvalidCredentials)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Неверный текущий пароль"`,
+		},
+		{
+			name:   "Пользователя нет в базе",
+			userID: userID,
+			mockService: func(m *MockService) {
+				m.On("ChangePassword", mock.Anything, userID, req).Return(apperrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Пользователь не найден"`,
+		},
+		{
+			name:           "Токен без пользователя",
+			userID:         uuid.Nil,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `"message":"Токен не содержит идентификатор пользователя"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+			h := Handler{service: mockService}
+
+			body, err := json.Marshal(req)
+			require.NoError(t, err)
+			r := httptest.NewRequest(http.MethodPost, "/me/password", bytes.NewReader(body))
+			r = r.WithContext(context.WithValue(r.Context(), "userID", tt.userID))
+			rec := httptest.NewRecorder()
+			h.changePasswordHandler(rec, r)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,67 +1,191 @@
 package handler
 
 import (
-	"context"
 	"github.com/go-chi/chi"
 	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/health"
 	"github.com/kstsm/pvz-service/internal/middleware"
 	"github.com/kstsm/pvz-service/internal/service"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
 )
 
 type HandlerI interface {
 	NewRouter() http.Handler
 	dummyLoginHandler(w http.ResponseWriter, r *http.Request)
+	dummyLoginV2Handler(w http.ResponseWriter, r *http.Request)
 	createPVZHandler(w http.ResponseWriter, r *http.Request)
+	getPVZHandler(w http.ResponseWriter, r *http.Request)
+	updatePVZHandler(w http.ResponseWriter, r *http.Request)
+	decommissionPVZHandler(w http.ResponseWriter, r *http.Request)
+	assignEmployeeHandler(w http.ResponseWriter, r *http.Request)
+	unassignEmployeeHandler(w http.ResponseWriter, r *http.Request)
 	createReceptionHandler(w http.ResponseWriter, r *http.Request)
 	addProductToReceptionHandler(w http.ResponseWriter, r *http.Request)
 	deleteLastProductHandler(w http.ResponseWriter, r *http.Request)
+	getDeletedProductsHandler(w http.ResponseWriter, r *http.Request)
+	restoreProductHandler(w http.ResponseWriter, r *http.Request)
 	closeLastReceptionHandler(w http.ResponseWriter, r *http.Request)
 	getListPVZ(w http.ResponseWriter, r *http.Request)
+	getListPVZV2(w http.ResponseWriter, r *http.Request)
+	issueProductHandler(w http.ResponseWriter, r *http.Request)
+	returnProductHandler(w http.ResponseWriter, r *http.Request)
+	getPVZStockHandler(w http.ResponseWriter, r *http.Request)
+	getExpiredProductsHandler(w http.ResponseWriter, r *http.Request)
+	getPVZDailyReportHandler(w http.ResponseWriter, r *http.Request)
+	forceCloseReceptionHandler(w http.ResponseWriter, r *http.Request)
+	reopenReceptionHandler(w http.ResponseWriter, r *http.Request)
+	getStaleReceptionsHandler(w http.ResponseWriter, r *http.Request)
+	getDiscrepancyReportHandler(w http.ResponseWriter, r *http.Request)
+	getReceptionHandler(w http.ResponseWriter, r *http.Request)
+	uploadProductAttachmentsHandler(w http.ResponseWriter, r *http.Request)
+	getProductAttachmentsHandler(w http.ResponseWriter, r *http.Request)
+	downloadProductAttachmentHandler(w http.ResponseWriter, r *http.Request)
+	getNearbyPVZHandler(w http.ResponseWriter, r *http.Request)
+	searchHandler(w http.ResponseWriter, r *http.Request)
+	linkParcelHandler(w http.ResponseWriter, r *http.Request)
+	getMyParcelsHandler(w http.ResponseWriter, r *http.Request)
 	registerUserHandler(w http.ResponseWriter, r *http.Request)
 	loginUserHandler(w http.ResponseWriter, r *http.Request)
+	loginUserV2Handler(w http.ResponseWriter, r *http.Request)
+	verifyEmailHandler(w http.ResponseWriter, r *http.Request)
+	resendEmailVerificationHandler(w http.ResponseWriter, r *http.Request)
+	requestPasswordResetHandler(w http.ResponseWriter, r *http.Request)
+	resetPasswordHandler(w http.ResponseWriter, r *http.Request)
+	changePasswordHandler(w http.ResponseWriter, r *http.Request)
+	healthzHandler(w http.ResponseWriter, r *http.Request)
+	readyzHandler(w http.ResponseWriter, r *http.Request)
+	versionHandler(w http.ResponseWriter, r *http.Request)
 }
 
 type Handler struct {
-	ctx     context.Context
-	service service.ServiceI
+	service   service.ServiceI
+	tokens    *auth.Tokens
+	readiness *health.Readiness
+	// listEnvelope включается для маршрутов /v2: списки в ответах
+	// оборачиваются в объект с items и total.
+	listEnvelope bool
 }
 
-func NewHandler(ctx context.Context, svc service.ServiceI) HandlerI {
+func NewHandler(svc service.ServiceI, tokens *auth.Tokens, readiness *health.Readiness) HandlerI {
 	return &Handler{
-		ctx:     ctx,
-		service: svc,
+		service:   svc,
+		tokens:    tokens,
+		readiness: readiness,
 	}
 }
 
-func NewRouterForTests(ctx context.Context, svc service.ServiceI) http.Handler {
-	router := NewHandler(ctx, svc)
+func NewRouterForTests(svc service.ServiceI, tokens *auth.Tokens) http.Handler {
+	router := NewHandler(svc, tokens, health.NewReadiness())
 	return router.NewRouter()
 }
 
 func (h Handler) NewRouter() http.Handler {
 	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Tracing)
+	r.Use(middleware.AccessLog)
+	r.Use(middleware.ReadYourWrites)
 
 	r.Group(func(r chi.Router) {
-		r.Post("/dummyLogin", h.dummyLoginHandler)
-		r.Post("/register", h.registerUserHandler)
-		r.Post("/login", h.loginUserHandler)
+		r.Handle("/metrics", promhttp.Handler())
+		r.Get("/healthz", h.healthzHandler)
+		r.Get("/readyz", h.readyzHandler)
+		r.Get("/version", h.versionHandler)
 	})
 
+	v1 := apiRoutes{
+		dummyLogin: h.dummyLoginHandler,
+		login:      h.loginUserHandler,
+		listPVZ:    h.getListPVZ,
+	}
+	v2 := apiRoutes{
+		dummyLogin:   h.dummyLoginV2Handler,
+		login:        h.loginUserV2Handler,
+		listPVZ:      h.getListPVZV2,
+		listEnvelope: true,
+	}
+
+	r.Route("/v1", func(r chi.Router) {
+		r.Use(middleware.Deprecated("/v1", "/v2"))
+		h.mountAPI(r, v1)
+	})
+	r.Route("/v2", func(r chi.Router) {
+		h.mountAPI(r, v2)
+	})
+	// Пути без версии остаются для старых клиентов и работают как /v1.
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware(auth.ValidateToken))
+		r.Use(middleware.Deprecated("", "/v2"))
+		h.mountAPI(r, v1)
+	})
+
+	return r
+}
+
+// apiRoutes — обработчики, у которых форма ответа отличается между версиями
+// API. Остальные маршруты у версий общие.
+type apiRoutes struct {
+	dummyLogin   http.HandlerFunc
+	login        http.HandlerFunc
+	listPVZ      http.HandlerFunc
+	listEnvelope bool
+}
 
-		r.With(middleware.RequireRole("moderator")).Post("/pvz", h.createPVZHandler)
+func (h Handler) mountAPI(r chi.Router, api apiRoutes) {
+	h.listEnvelope = api.listEnvelope
+
+	r.Post("/dummyLogin", api.dummyLogin)
+	r.Post("/register", h.registerUserHandler)
+	r.Post("/login", api.login)
+	r.Post("/verify-email", h.verifyEmailHandler)
+	r.Post("/verify-email/resend", h.resendEmailVerificationHandler)
+	r.Post("/password/reset", h.requestPasswordResetHandler)
+	r.Post("/password/reset/confirm", h.resetPasswordHandler)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(h.authenticate))
+
+		r.With(middleware.RequireRole("moderator")).Group(func(r chi.Router) {
+			r.Post("/pvz", h.createPVZHandler)
+			r.Get("/pvz/{pvzId}", h.getPVZHandler)
+			r.Patch("/pvz/{pvzId}", h.updatePVZHandler)
+			r.Delete("/pvz/{pvzId}", h.decommissionPVZHandler)
+			r.Put("/pvz/{pvzId}/employees/{userId}", h.assignEmployeeHandler)
+			r.Delete("/pvz/{pvzId}/employees/{userId}", h.unassignEmployeeHandler)
+			r.Post("/receptions/{receptionId}/force_close", h.forceCloseReceptionHandler)
+			r.Post("/receptions/{receptionId}/reopen", h.reopenReceptionHandler)
+			r.Get("/receptions/{receptionId}/deleted_products", h.getDeletedProductsHandler)
+			r.Post("/products/{productId}/restore", h.restoreProductHandler)
+		})
 
 		r.With(middleware.RequireRole("employee")).Group(func(r chi.Router) {
 			r.Post("/receptions", h.createReceptionHandler)
 			r.Post("/products", h.addProductToReceptionHandler)
 			r.Post("/pvz/{pvzId}/delete_last_product", h.deleteLastProductHandler)
 			r.Post("/pvz/{pvzId}/close_last_reception", h.closeLastReceptionHandler)
+			r.Post("/products/{productId}/issue", h.issueProductHandler)
+			r.Post("/products/{productId}/return", h.returnProductHandler)
+			r.Post("/products/{productId}/attachments", h.uploadProductAttachmentsHandler)
 		})
 
-		r.With(middleware.RequireRole("employee", "moderator")).Get("/pvz", h.getListPVZ)
-	})
+		r.With(middleware.RequireRole("client")).Group(func(r chi.Router) {
+			r.Post("/my/parcels", h.linkParcelHandler)
+			r.Get("/my/parcels", h.getMyParcelsHandler)
+		})
 
-	return r
+		r.With(middleware.RequireRole("employee", "moderator")).Group(func(r chi.Router) {
+			r.Get("/pvz", api.listPVZ)
+			r.Get("/search", h.searchHandler)
+			r.Get("/pvz/{pvzId}/stock", h.getPVZStockHandler)
+			r.Get("/pvz/{pvzId}/expired", h.getExpiredProductsHandler)
+			r.Get("/pvz/{pvzId}/reports/daily", h.getPVZDailyReportHandler)
+			r.Get("/receptions/stale", h.getStaleReceptionsHandler)
+			r.Get("/receptions/{receptionId}", h.getReceptionHandler)
+			r.Get("/receptions/{receptionId}/discrepancies", h.getDiscrepancyReportHandler)
+			r.Get("/products/{productId}/attachments", h.getProductAttachmentsHandler)
+			r.Get("/products/{productId}/attachments/{attachmentId}", h.downloadProductAttachmentHandler)
+		})
+		r.With(middleware.RequireRole("client", "employee", "moderator")).Get("/pvz/nearby", h.getNearbyPVZHandler)
+		r.With(middleware.RequireRole("client", "employee", "moderator")).Post("/me/password", h.changePasswordHandler)
+	})
 }
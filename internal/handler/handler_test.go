@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIVersions(t *testing.T) {
+	tokens := auth.NewTokens("test-secret")
+	moderatorToken, err := tokens.Generate(context.Background(), uuid.New(), "moderator", tenant.DefaultID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		body             string
+		expectedStatus   int
+		expectedBody     string
+		expectedLink     string
+		expectDeprecated bool
+	}{
+		{
+			name:             "v1 возвращает токен строкой",
+			method:           http.MethodPost,
+			path:             "/v1/dummyLogin",
+			body:             `{"role":"moderator"}`,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `"ey`,
+			expectedLink:     `</v2/dummyLogin>; rel="successor-version"`,
+			expectDeprecated: true,
+		},
+		{
+			name:             "Путь без версии работает как v1",
+			method:           http.MethodPost,
+			path:             "/dummyLogin",
+			body:             `{"role":"moderator"}`,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `"ey`,
+			expectedLink:     `</v2/dummyLogin>; rel="successor-version"`,
+			expectDeprecated: true,
+		},
+		{
+			name:           "v2 возвращает токен в объекте",
+			method:         http.MethodPost,
+			path:           "/v2/dummyLogin",
+			body:           `{"role":"moderator"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"token":"ey`,
+		},
+		{
+			name:             "v1 возвращает список ПВЗ массивом",
+			method:           http.MethodGet,
+			path:             "/v1/pvz?page=2&limit=5",
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `[{"pvz":`,
+			expectedLink:     `</v2/pvz>; rel="successor-version"`,
+			expectDeprecated: true,
+		},
+		{
+			name:           "v2 возвращает список ПВЗ с пагинацией",
+			method:         http.MethodGet,
+			path:           "/v2/pvz?page=2&limit=5",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"pagination":{"page":2,"limit":5,"count":1,"hasNext":false}`,
+		},
+		{
+			name:             "v1 возвращает зависшие приёмки массивом",
+			method:           http.MethodGet,
+			path:             "/v1/receptions/stale",
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `[{"id":`,
+			expectedLink:     `</v2/receptions/stale>; rel="successor-version"`,
+			expectDeprecated: true,
+		},
+		{
+			name:           "v2 оборачивает зависшие приёмки в items",
+			method:         http.MethodGet,
+			path:           "/v2/receptions/stale",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"total":1}`,
+		},
+		{
+			name:           "v2 отдаёт найденное в items",
+			method:         http.MethodGet,
+			path:           "/v2/search?q=Казань",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"items":[],"total":0,"facets":`,
+		},
+		{
+			name:           "Служебные маршруты без версии",
+			method:         http.MethodGet,
+			path:           "/healthz",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			mockService.On("GetTenant", mock.Anything, "").Return(models.Tenant{ID: tenant.DefaultID}, nil).Maybe()
+			mockService.On("EnsureDummyUser", mock.Anything, mock.Anything).Return(uuid.New(), nil).Maybe()
+			mockService.On("GetPVZList", mock.Anything, models.PVZFilterParams{Page: 2, Limit: 5}).
+				Return(models.PVZPage{Items: []models.PVZWithReceptions{{PVZ: models.PVZ{ID: uuid.New(), City: "Казань"}}}}, nil).Maybe()
+			mockService.On("GetStaleReceptions", mock.Anything, mock.Anything).
+				Return([]models.Reception{{ID: uuid.New(), Status: models.ReceptionStatusInProgress}}, nil).Maybe()
+			mockService.On("Search", mock.Anything, mock.Anything).Return(models.SearchResult{}, nil).Maybe()
+			mockService.On("CheckTokenRevoked", mock.Anything, mock.Anything).Return(nil).Maybe()
+			router := NewRouterForTests(mockService, tokens)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+moderatorToken)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			if tt.expectDeprecated {
+				assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+				assert.Equal(t, tt.expectedLink, rec.Header().Get("Link"))
+			} else {
+				assert.Empty(t, rec.Header().Get("Deprecation"))
+			}
+		})
+	}
+}
+
+func TestRevokedToken(t *testing.T) {
+	tokens := auth.NewTokens("test-secret")
+	userID := uuid.New()
+	token, err := tokens.Generate(context.Background(), userID, "moderator", tenant.DefaultID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		revokeErr      error
+		expectedStatus int
+	}{
+		{name: "Пароль не менялся", expectedStatus: http.StatusOK},
+		{name: "Пароль сменён после выпуска токена", revokeErr: apperrors.ErrTokenRevoked, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			mockService.On("CheckTokenRevoked", mock.Anything, mock.MatchedBy(func(claims models.TokenClaims) bool {
+				return claims.UserID == userID
+			})).Return(tt.revokeErr)
+			mockService.On("GetStaleReceptions", mock.Anything, mock.Anything).Return([]models.Reception{}, nil).Maybe()
+			router := NewRouterForTests(mockService, tokens)
+
+			req := httptest.NewRequest(http.MethodGet, "/v2/receptions/stale", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
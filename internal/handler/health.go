@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/kstsm/pvz-service/internal/buildinfo"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"net/http"
+)
+
+// healthzHandler отвечает, пока процесс жив, и не проверяет зависимости:
+// перезапуск контейнера не поможет, если недоступна база.
+func (h Handler) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	sendJSONResponse(w, http.StatusOK, models.HealthResponse{Status: "ok"})
+}
+
+func (h Handler) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	report := h.readiness.Check(r.Context())
+
+	resp := models.HealthResponse{Status: "ok", Checks: make(map[string]string, len(report.Checks))}
+	for name, err := range report.Checks {
+		if err != nil {
+			resp.Checks[name] = err.Error()
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+
+	if !report.Ready {
+		slog.WarnContext(r.Context(), "Сервис не готов принимать запросы", "checks", resp.Checks)
+		resp.Status = "unavailable"
+		sendJSONResponse(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, resp)
+}
+
+func (h Handler) versionHandler(w http.ResponseWriter, r *http.Request) {
+	sendJSONResponse(w, http.StatusOK, buildinfo.Get())
+}
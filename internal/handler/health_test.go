@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/kstsm/pvz-service/internal/health"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyzHandler(t *testing.T) {
+	postgres := health.Check{Name: "postgres", Run: func(context.Context) error { return nil }}
+	migrations := health.Check{Name: "migrations", Run: func(context.Context) error {
+		return errors.New("не применены миграции: [002_add_index.sql]")
+	}}
+
+	tests := []struct {
+		name           string
+		checks         []health.Check
+		drain          bool
+		expectedStatus int
+		expectedBody   models.HealthResponse
+	}{
+		{
+			name:           "Сервис готов",
+			checks:         []health.Check{postgres},
+			expectedStatus: http.StatusOK,
+			expectedBody:   models.HealthResponse{Status: "ok", Checks: map[string]string{"postgres": "ok"}},
+		},
+		{
+			name:           "Есть невыполненные миграции",
+			checks:         []health.Check{postgres, migrations},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: models.HealthResponse{Status: "unavailable", Checks: map[string]string{
+				"postgres":   "ok",
+				"migrations": "не применены миграции: [002_add_index.sql]",
+			}},
+		},
+		{
+			name:           "Сервер завершает работу",
+			checks:         []health.Check{postgres},
+			drain:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: models.HealthResponse{Status: "unavailable", Checks: map[string]string{
+				"postgres": "ok",
+				"shutdown": health.ErrDraining.Error(),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readiness := health.NewReadiness(tt.checks...)
+			if tt.drain {
+				readiness.Drain()
+			}
+			h := Handler{readiness: readiness}
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+
+			h.readyzHandler(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			var body models.HealthResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
+
+func TestHealthzHandler(t *testing.T) {
+	h := Handler{readiness: health.NewReadiness()}
+	h.readiness.Drain()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+
+	h.healthzHandler(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "живость не зависит от готовности")
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
@@ -2,12 +2,18 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/middleware"
 	"github.com/kstsm/pvz-service/models"
-	"log"
+	"log/slog"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 func isValidProduct(product string) bool {
@@ -19,6 +25,35 @@ func isValidProduct(product string) bool {
 	return allowedProduct[product]
 }
 
+func isValidCondition(condition string) bool {
+	allowedCondition := map[string]bool{
+		models.ProductConditionOK:      true,
+		models.ProductConditionDamaged: true,
+		models.ProductConditionOpened:  true,
+	}
+	return allowedCondition[condition]
+}
+
+// parseConditionFilter читает необязательный фильтр товаров по состоянию.
+func parseConditionFilter(r *http.Request) (string, error) {
+	condition := r.URL.Query().Get("condition")
+	if condition != "" && !isValidCondition(condition) {
+		return "", fmt.Errorf("недопустимое состояние товара: %q", condition)
+	}
+	return condition, nil
+}
+
+// employeeScope возвращает идентификатор сотрудника, если запрос сделан
+// сотрудником: ему доступны только ПВЗ, за которыми он закреплён. Токен без
+// user_id не даёт доступа ни к одному ПВЗ. Для остальных ролей возвращает nil.
+func employeeScope(r *http.Request) *uuid.UUID {
+	if role, _ := r.Context().Value("role").(string); role != "employee" {
+		return nil
+	}
+	employeeID, _ := middleware.UserIDFromContext(r.Context())
+	return &employeeID
+}
+
 func isValidRole(role string) bool {
 	allowedRoles := map[string]bool{
 		"client":    true,
@@ -37,6 +72,142 @@ func isValidCity(city string) bool {
 	return allowedCity[city]
 }
 
+var barcodePattern = regexp.MustCompile(`^[0-9A-Za-z-]{4,64}$`)
+
+func isValidBarcode(barcode string) bool {
+	return barcodePattern.MatchString(barcode)
+}
+
+var pickupCodePattern = regexp.MustCompile(`^[0-9A-Za-z]{6,32}$`)
+
+func isValidPickupCode(code string) bool {
+	return pickupCodePattern.MatchString(code)
+}
+
+const maxReasonLength = 500
+
+const maxNoteLength = 1000
+
+// isValidReason проверяет причину, которую модератор указывает при ручном
+// вмешательстве в приёмку. Пробелы по краям должны быть уже обрезаны.
+func isValidReason(reason string) bool {
+	return reason != "" && utf8.RuneCountInString(reason) <= maxReasonLength
+}
+
+const maxManifestItems = 1000
+
+func validateManifest(manifest []models.ManifestItem) error {
+	if len(manifest) > maxManifestItems {
+		return fmt.Errorf("манифест не может содержать больше %d позиций", maxManifestItems)
+	}
+
+	seen := make(map[string]bool, len(manifest))
+	for _, item := range manifest {
+		if !isValidBarcode(item.Barcode) {
+			return fmt.Errorf("недопустимый штрихкод %q", item.Barcode)
+		}
+		if !isValidProduct(item.Type) {
+			return fmt.Errorf("недопустимый тип товара %q", item.Type)
+		}
+		if item.PickupCode != "" && !isValidPickupCode(item.PickupCode) {
+			return fmt.Errorf("недопустимый код получения у штрихкода %q", item.Barcode)
+		}
+		if seen[item.Barcode] {
+			return fmt.Errorf("штрихкод %q указан повторно", item.Barcode)
+		}
+		seen[item.Barcode] = true
+	}
+
+	return nil
+}
+
+// isValidPVZStatus проверяет статус, который можно задать через PATCH.
+// Вывести ПВЗ из эксплуатации можно только через DELETE: он проверяет
+// открытую приёмку и остатки и помечает ПВЗ удалённым.
+func isValidPVZStatus(status string) bool {
+	allowedStatus := map[string]bool{
+		models.PVZStatusActive:            true,
+		models.PVZStatusTemporarilyClosed: true,
+	}
+	return allowedStatus[status]
+}
+
+// validateTimezone принимает только названия поясов из базы IANA. Пустое
+// имя и Local Go понимает как пояс сервера, а Postgres их не знает, и
+// запросы с AT TIME ZONE по такому ПВЗ падали бы с ошибкой.
+func validateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return fmt.Errorf("неизвестный часовой пояс: %q", name)
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("неизвестный часовой пояс: %q", name)
+	}
+
+	return nil
+}
+
+func validateUpdatePVZRequest(req models.UpdatePVZRequest) error {
+	if req.Status != nil && *req.Status == models.PVZStatusDecommissioned {
+		return errors.New("вывести ПВЗ из эксплуатации можно только через DELETE /pvz/{pvzId}")
+	}
+	if req.Status != nil && !isValidPVZStatus(*req.Status) {
+		return fmt.Errorf("недопустимый статус ПВЗ: %q", *req.Status)
+	}
+
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return errors.New("широта и долгота должны передаваться вместе")
+	}
+	if req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90) {
+		return fmt.Errorf("широта вне допустимого диапазона: %v", *req.Latitude)
+	}
+	if req.Longitude != nil && (*req.Longitude < -180 || *req.Longitude > 180) {
+		return fmt.Errorf("долгота вне допустимого диапазона: %v", *req.Longitude)
+	}
+
+	if req.Timezone != nil {
+		if err := validateTimezone(*req.Timezone); err != nil {
+			return err
+		}
+	}
+
+	if req.WorkingHours != nil {
+		seen := make(map[int]bool)
+		for _, wh := range *req.WorkingHours {
+			if wh.Weekday < 0 || wh.Weekday > 6 {
+				return fmt.Errorf("недопустимый день недели: %d", wh.Weekday)
+			}
+			if seen[wh.Weekday] {
+				return fmt.Errorf("часы работы для дня %d указаны повторно", wh.Weekday)
+			}
+			seen[wh.Weekday] = true
+
+			open, err := time.Parse("15:04", wh.Open)
+			if err != nil {
+				return fmt.Errorf("неверный формат времени открытия: %q", wh.Open)
+			}
+			closing, err := time.Parse("15:04", wh.Close)
+			if err != nil {
+				return fmt.Errorf("неверный формат времени закрытия: %q", wh.Close)
+			}
+			if !closing.After(open) {
+				return fmt.Errorf("время закрытия должно быть позже времени открытия: %s-%s", wh.Open, wh.Close)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidatePVZ проверяет город и параметры ПВЗ по тем же правилам, что и API.
+// Нужна командам, которые заводят ПВЗ в обход HTTP, например импорту из CSV.
+func ValidatePVZ(city string, req models.UpdatePVZRequest) error {
+	if !isValidCity(city) {
+		return fmt.Errorf("город %q пока недоступен", city)
+	}
+
+	return validateUpdatePVZRequest(req)
+}
+
 func sendJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -46,11 +217,31 @@ func sendJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
 	}
 
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Println("Ошибка кодирования JSON:", err)
+		slog.Error("Ошибка кодирования JSON", "error", err)
 		http.Error(w, "Ошибка при отправке ответа", http.StatusInternalServerError)
 	}
 }
 
+// sendListResponse отвечает списком: в /v2 — объектом {"items": [...], "total": n},
+// в /v1 — массивом, как раньше.
+func sendListResponse[T any](w http.ResponseWriter, envelope bool, items []T) {
+	if !envelope {
+		sendJSONResponse(w, http.StatusOK, items)
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, models.ListResponse[T]{Items: nonNil(items), Total: len(items)})
+}
+
+// nonNil заменяет nil пустым срезом, чтобы пустой список в JSON был [], а не null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+
+	return items
+}
+
 func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -63,20 +254,15 @@ func parsePVZFilterParams(r *http.Request) (models.PVZFilterParams, error) {
 		Limit: 10,
 	}
 
-	if startDate := r.URL.Query().Get("startDate"); startDate != "" {
-		parsedStartDate, err := time.Parse(time.RFC3339, startDate)
-		if err != nil {
-			return params, fmt.Errorf("неверный формат даты начала: %v", err)
-		}
-		params.StartDate = &parsedStartDate
+	var err error
+	if params.StartDate, params.StartDay, err = parseDateFilter(r.URL.Query().Get("startDate")); err != nil {
+		return params, fmt.Errorf("неверный формат даты начала: %w", err)
 	}
-
-	if endDate := r.URL.Query().Get("endDate"); endDate != "" {
-		parsedEndDate, err := time.Parse(time.RFC3339, endDate)
-		if err != nil {
-			return params, fmt.Errorf("неверный формат даты конца: %v", err)
-		}
-		params.EndDate = &parsedEndDate
+	if params.EndDate, params.EndDay, err = parseDateFilter(r.URL.Query().Get("endDate")); err != nil {
+		return params, fmt.Errorf("неверный формат даты конца: %w", err)
+	}
+	if params.StartDay != "" && params.EndDay != "" && params.StartDay > params.EndDay {
+		return params, fmt.Errorf("дата начала %s позже даты конца %s", params.StartDay, params.EndDay)
 	}
 
 	if page := r.URL.Query().Get("page"); page != "" {
@@ -95,3 +281,144 @@ func parsePVZFilterParams(r *http.Request) (models.PVZFilterParams, error) {
 
 	return params, nil
 }
+
+// parseDateFilter разбирает дату фильтра. Момент в RFC3339 возвращается как
+// время, а календарная дата 2006-01-02 — строкой: её границы зависят от
+// часового пояса ПВЗ и вычисляются в базе.
+func parseDateFilter(value string) (*time.Time, string, error) {
+	if value == "" {
+		return nil, "", nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, "", nil
+	}
+	if _, err := time.Parse(models.DateLayout, value); err == nil {
+		return nil, value, nil
+	}
+
+	return nil, "", fmt.Errorf("ожидается дата вида 2006-01-02 или 2006-01-02T15:04:05Z07:00, получено %q", value)
+}
+
+// maxReportDays ограничивает период дневного отчёта.
+const maxReportDays = 366
+
+// parseReportPeriod разбирает обязательный период отчёта из календарных дат
+// startDate и endDate включительно.
+func parseReportPeriod(r *http.Request) (string, string, error) {
+	startDay, endDay := r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate")
+	start, err := time.Parse(models.DateLayout, startDay)
+	if err != nil {
+		return "", "", fmt.Errorf("неверная дата начала %q: ожидается 2006-01-02", startDay)
+	}
+	end, err := time.Parse(models.DateLayout, endDay)
+	if err != nil {
+		return "", "", fmt.Errorf("неверная дата конца %q: ожидается 2006-01-02", endDay)
+	}
+
+	switch days := int(end.Sub(start).Hours()/24) + 1; {
+	case days < 1:
+		return "", "", fmt.Errorf("дата начала %s позже даты конца %s", startDay, endDay)
+	case days > maxReportDays:
+		return "", "", fmt.Errorf("период отчёта больше %d дней", maxReportDays)
+	}
+
+	return startDay, endDay, nil
+}
+
+func parseNearbyPVZParams(r *http.Request) (models.NearbyPVZParams, error) {
+	params := models.NearbyPVZParams{
+		RadiusKm: 5,
+		Limit:    10,
+	}
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return params, fmt.Errorf("неверное значение широты: %q", query.Get("lat"))
+	}
+	params.Latitude = lat
+
+	lon, err := strconv.ParseFloat(query.Get("lon"), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return params, fmt.Errorf("неверное значение долготы: %q", query.Get("lon"))
+	}
+	params.Longitude = lon
+
+	if radius := query.Get("radius"); radius != "" {
+		parsedRadius, err := strconv.ParseFloat(radius, 64)
+		if err != nil || parsedRadius <= 0 || parsedRadius > 100 {
+			return params, fmt.Errorf("радиус должен быть в пределах (0; 100] км: %q", radius)
+		}
+		params.RadiusKm = parsedRadius
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsedLimit, err := strconv.Atoi(limit)
+		if err == nil && parsedLimit > 0 && parsedLimit <= 30 {
+			params.Limit = parsedLimit
+		}
+	}
+
+	if openNow := query.Get("openNow"); openNow != "" {
+		parsedOpenNow, err := strconv.ParseBool(openNow)
+		if err != nil {
+			return params, fmt.Errorf("неверное значение openNow: %q", openNow)
+		}
+		params.OpenNow = parsedOpenNow
+	}
+
+	return params, nil
+}
+
+const (
+	minSearchQueryLength = 2
+	maxSearchQueryLength = 100
+)
+
+func parseSearchParams(r *http.Request) (models.SearchParams, error) {
+	query := r.URL.Query()
+	params := models.SearchParams{
+		Query:           strings.TrimSpace(query.Get("q")),
+		Kind:            query.Get("kind"),
+		City:            query.Get("city"),
+		ProductType:     query.Get("productType"),
+		ReceptionStatus: query.Get("receptionStatus"),
+		Limit:           20,
+	}
+
+	length := utf8.RuneCountInString(params.Query)
+	if length < minSearchQueryLength || length > maxSearchQueryLength {
+		return params, fmt.Errorf("поисковый запрос должен содержать от %d до %d символов",
+			minSearchQueryLength, maxSearchQueryLength)
+	}
+
+	switch params.Kind {
+	case "", models.SearchKindPVZ, models.SearchKindReception, models.SearchKindProduct:
+	default:
+		return params, fmt.Errorf("недопустимый тип результата: %q", params.Kind)
+	}
+
+	if params.City != "" && !isValidCity(params.City) {
+		return params, fmt.Errorf("недопустимый город: %q", params.City)
+	}
+
+	if params.ProductType != "" && !isValidProduct(params.ProductType) {
+		return params, fmt.Errorf("недопустимый тип товара: %q", params.ProductType)
+	}
+
+	switch params.ReceptionStatus {
+	case "", models.ReceptionStatusInProgress, models.ReceptionStatusClose, models.ReceptionStatusAbandoned:
+	default:
+		return params, fmt.Errorf("недопустимый статус приёмки: %q", params.ReceptionStatus)
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsedLimit, err := strconv.Atoi(limit)
+		if err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			params.Limit = parsedLimit
+		}
+	}
+
+	return params, nil
+}
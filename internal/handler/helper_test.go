@@ -129,6 +129,34 @@ func TestParsePVZFilterParams(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name: "Календарные даты",
+			queryParams: map[string]string{
+				"startDate": "2026-10-01",
+				"endDate":   "2026-10-01",
+			},
+			expectedParams: models.PVZFilterParams{
+				StartDay: "2026-10-01",
+				EndDay:   "2026-10-01",
+				Page:     1,
+				Limit:    10,
+			},
+			expectedError: false,
+		},
+		{
+			name: "Дата начала позже даты конца",
+			queryParams: map[string]string{
+				"startDate": "2026-10-02",
+				"endDate":   "2026-10-01",
+			},
+			expectedParams: models.PVZFilterParams{
+				StartDay: "2026-10-02",
+				EndDay:   "2026-10-01",
+				Page:     1,
+				Limit:    10,
+			},
+			expectedError: true,
+		},
 		{
 			name: "Invalid startDate format",
 			queryParams: map[string]string{
@@ -211,7 +239,7 @@ func parseTime(timeStr string) *time.Time {
 }
 
 func equalParams(a, b models.PVZFilterParams) bool {
-	if a.Page != b.Page || a.Limit != b.Limit {
+	if a.Page != b.Page || a.Limit != b.Limit || a.StartDay != b.StartDay || a.EndDay != b.EndDay {
 		return false
 	}
 	if a.StartDate != nil && b.StartDate != nil && !a.StartDate.Equal(*b.StartDate) {
@@ -222,3 +250,106 @@ func equalParams(a, b models.PVZFilterParams) bool {
 	}
 	return true
 }
+
+func TestParseReportPeriod(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expectedStart string
+		expectedEnd   string
+		expectedError bool
+	}{
+		{
+			name:          "Корректный период",
+			query:         "startDate=2026-10-01&endDate=2026-10-07",
+			expectedStart: "2026-10-01",
+			expectedEnd:   "2026-10-07",
+		},
+		{
+			name:          "Один день",
+			query:         "startDate=2026-10-01&endDate=2026-10-01",
+			expectedStart: "2026-10-01",
+			expectedEnd:   "2026-10-01",
+		},
+		{
+			name:          "Нет даты конца",
+			query:         "startDate=2026-10-01",
+			expectedError: true,
+		},
+		{
+			name:          "Дата с временем",
+			query:         "startDate=2026-10-01T00:00:00Z&endDate=2026-10-07",
+			expectedError: true,
+		},
+		{
+			name:          "Начало позже конца",
+			query:         "startDate=2026-10-07&endDate=2026-10-01",
+			expectedError: true,
+		},
+		{
+			name:          "Слишком длинный период",
+			query:         "startDate=2025-01-01&endDate=2026-10-01",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/pvz/id/reports/daily?"+tt.query, nil)
+
+			startDay, endDay, err := parseReportPeriod(req)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStart, startDay)
+			assert.Equal(t, tt.expectedEnd, endDay)
+		})
+	}
+}
+
+func TestValidateUpdatePVZRequest(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	lat, lon := 55.75, 37.61
+	badLat := 91.0
+
+	tests := []struct {
+		name    string
+		req     models.UpdatePVZRequest
+		wantErr bool
+	}{
+		{"пустой запрос", models.UpdatePVZRequest{}, false},
+		{"валидный статус", models.UpdatePVZRequest{Status: ptr(models.PVZStatusTemporarilyClosed)}, false},
+		{"вывод из эксплуатации", models.UpdatePVZRequest{Status: ptr(models.PVZStatusDecommissioned)}, true},
+		{"невалидный статус", models.UpdatePVZRequest{Status: ptr("closed")}, true},
+		{"координаты", models.UpdatePVZRequest{Latitude: &lat, Longitude: &lon}, false},
+		{"широта вне диапазона", models.UpdatePVZRequest{Latitude: &badLat, Longitude: &lon}, true},
+		{"валидный часовой пояс", models.UpdatePVZRequest{Timezone: ptr("Europe/Moscow")}, false},
+		{"неизвестный часовой пояс", models.UpdatePVZRequest{Timezone: ptr("Mars/Olympus")}, true},
+		{"пустой часовой пояс", models.UpdatePVZRequest{Timezone: ptr("")}, true},
+		{"часовой пояс сервера", models.UpdatePVZRequest{Timezone: ptr("Local")}, true},
+		{"валидные часы работы", models.UpdatePVZRequest{WorkingHours: &[]models.WorkingHours{
+			{Weekday: 1, Open: "09:00", Close: "21:00"},
+			{Weekday: 6, Open: "10:00", Close: "18:00"},
+		}}, false},
+		{"закрытие раньше открытия", models.UpdatePVZRequest{WorkingHours: &[]models.WorkingHours{
+			{Weekday: 1, Open: "21:00", Close: "09:00"},
+		}}, true},
+		{"повтор дня недели", models.UpdatePVZRequest{WorkingHours: &[]models.WorkingHours{
+			{Weekday: 1, Open: "09:00", Close: "21:00"},
+			{Weekday: 1, Open: "10:00", Close: "20:00"},
+		}}, true},
+		{"неверный формат времени", models.UpdatePVZRequest{WorkingHours: &[]models.WorkingHours{
+			{Weekday: 2, Open: "9am", Close: "21:00"},
+		}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateUpdatePVZRequest(tt.req)
+			assert.Equal(t, tt.wantErr, err != nil, "err = %v", err)
+		})
+	}
+}
@@ -3,38 +3,50 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/models"
 	"github.com/stretchr/testify/mock"
+	"io"
 )
 
 type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) AddProductToActiveReception(ctx context.Context, productType string, pvzID uuid.UUID) (models.Product, error) {
-	args := m.Called(ctx, productType, pvzID)
+func (m *MockService) AddProductToActiveReception(ctx context.Context, req models.AddProductRequest) (models.Product, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(models.Product), args.Error(1)
 }
 
-func (m *MockService) CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *MockService) CreateReception(ctx context.Context, req models.CreateReceptionRequest) (models.Reception, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
-func (m *MockService) GetPVZList(ctx context.Context, params models.PVZFilterParams) ([]models.PVZWithReceptions, error) {
+func (m *MockService) GetPVZList(ctx context.Context, params models.PVZFilterParams) (models.PVZPage, error) {
 	args := m.Called(ctx, params)
-	return args.Get(0).([]models.PVZWithReceptions), args.Error(1)
+	return args.Get(0).(models.PVZPage), args.Error(1)
 }
 
 func (m *MockService) CreatePVZ(ctx context.Context, city string) (models.PVZ, error) {
 	args := m.Called(ctx, city)
 	return args.Get(0).(models.PVZ), args.Error(1)
 }
-func (m *MockService) DummyLogin(ctx context.Context, req models.UserLoginReq) (string, error) {
-	args := m.Called(ctx, req)
-	return args.String(0), args.Error(1)
+func (m *MockService) GetPVZByID(ctx context.Context, pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockService) UpdatePVZ(ctx context.Context, pvzID uuid.UUID, req models.UpdatePVZRequest) (models.PVZ, error) {
+	args := m.Called(ctx, pvzID, req)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockService) EnsureDummyUser(ctx context.Context, role string) (uuid.UUID, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
 func (m *MockService) RegisterUser(ctx context.Context, req models.UserRegisterReq) (models.UserRegisterResp, error) {
@@ -46,6 +58,10 @@ func (m *MockService) RegisterUser(ctx context.Context, req models.UserRegisterR
 		return models.UserRegisterResp{}, apperrors.ErrEmailAlreadyExists
 	}
 
+	if len(req.Password) < 8 {
+		return models.UserRegisterResp{}, fmt.Errorf("%w: короче 8 символов", apperrors.ErrWeakPassword)
+	}
+
 	return models.UserRegisterResp{
 		ID:    uuid.New(),
 		Email: req.Email,
@@ -58,12 +74,158 @@ func (m *MockService) LoginUser(ctx context.Context, req models.UserLoginReq) (s
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockService) DeleteLastProductInReception(ctx context.Context, pvzID uuid.UUID) error {
-	args := m.Called(ctx, pvzID)
+func (m *MockService) DeleteLastProductInReception(ctx context.Context, pvzID, employeeID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, employeeID)
 	return args.Error(0)
 }
 
-func (m *MockService) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *MockService) GetDeletedProducts(ctx context.Context, receptionID uuid.UUID) ([]models.DeletedProduct, error) {
+	args := m.Called(ctx, receptionID)
+	return args.Get(0).([]models.DeletedProduct), args.Error(1)
+}
+
+func (m *MockService) RestoreProduct(ctx context.Context, productID, moderatorID uuid.UUID) (models.Product, error) {
+	args := m.Called(ctx, productID, moderatorID)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockService) DecommissionPVZ(ctx context.Context, pvzID, moderatorID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, moderatorID)
+	return args.Error(0)
+}
+
+func (m *MockService) CloseLastReception(ctx context.Context, pvzID uuid.UUID, acknowledge bool) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, acknowledge)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockService) GetDiscrepancyReport(ctx context.Context, receptionID uuid.UUID, employeeID *uuid.UUID) (models.DiscrepancyReport, error) {
+	args := m.Called(ctx, receptionID, employeeID)
+	return args.Get(0).(models.DiscrepancyReport), args.Error(1)
+}
+
+func (m *MockService) GetNearbyPVZ(ctx context.Context, params models.NearbyPVZParams) ([]models.PVZWithDistance, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]models.PVZWithDistance), args.Error(1)
+}
+
+func (m *MockService) GetPVZDailyReport(ctx context.Context, pvzID uuid.UUID, startDay, endDay string, employeeID *uuid.UUID) (models.PVZDailyReport, error) {
+	args := m.Called(ctx, pvzID, startDay, endDay, employeeID)
+	return args.Get(0).(models.PVZDailyReport), args.Error(1)
+}
+
+func (m *MockService) VerifyEmail(ctx context.Context, req models.EmailVerificationReq) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockService) ResendEmailVerification(ctx context.Context, req models.ResendVerificationReq) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockService) RequestPasswordReset(ctx context.Context, req models.PasswordResetReq) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockService) ResetPassword(ctx context.Context, req models.PasswordResetConfirmReq) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockService) ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordReq) error {
+	args := m.Called(ctx, userID, req)
+	return args.Error(0)
+}
+
+func (m *MockService) CheckTokenRevoked(ctx context.Context, claims models.TokenClaims) error {
+	args := m.Called(ctx, claims)
+	return args.Error(0)
+}
+
+func (m *MockService) LinkParcel(ctx context.Context, clientID uuid.UUID, req models.LinkParcelRequest) (models.Parcel, error) {
+	args := m.Called(ctx, clientID, req)
+	return args.Get(0).(models.Parcel), args.Error(1)
+}
+
+func (m *MockService) GetClientParcels(ctx context.Context, clientID uuid.UUID) ([]models.Parcel, error) {
+	args := m.Called(ctx, clientID)
+	return args.Get(0).([]models.Parcel), args.Error(1)
+}
+
+func (m *MockService) IssueProduct(ctx context.Context, productID uuid.UUID, employeeID uuid.UUID) (models.Product, error) {
+	args := m.Called(ctx, productID, employeeID)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockService) ReturnProduct(ctx context.Context, productID uuid.UUID, employeeID uuid.UUID) (models.Product, error) {
+	args := m.Called(ctx, productID, employeeID)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockService) GetPVZStock(ctx context.Context, pvzID uuid.UUID, condition string, employeeID *uuid.UUID) (models.PVZStock, error) {
+	args := m.Called(ctx, pvzID, condition, employeeID)
+	return args.Get(0).(models.PVZStock), args.Error(1)
+}
+
+func (m *MockService) GetReception(ctx context.Context, receptionID uuid.UUID, condition string, employeeID *uuid.UUID) (models.ReceptionWithProducts, error) {
+	args := m.Called(ctx, receptionID, condition, employeeID)
+	return args.Get(0).(models.ReceptionWithProducts), args.Error(1)
+}
+
+func (m *MockService) AddProductAttachments(ctx context.Context, productID uuid.UUID, uploads []models.AttachmentUpload, employeeID uuid.UUID) ([]models.ProductAttachment, error) {
+	args := m.Called(ctx, productID, uploads, employeeID)
+	return args.Get(0).([]models.ProductAttachment), args.Error(1)
+}
+
+func (m *MockService) GetProductAttachments(ctx context.Context, productID uuid.UUID, employeeID *uuid.UUID) ([]models.ProductAttachment, error) {
+	args := m.Called(ctx, productID, employeeID)
+	return args.Get(0).([]models.ProductAttachment), args.Error(1)
+}
+
+func (m *MockService) OpenProductAttachment(ctx context.Context, productID, attachmentID uuid.UUID, employeeID *uuid.UUID) (models.ProductAttachment, io.ReadCloser, error) {
+	args := m.Called(ctx, productID, attachmentID, employeeID)
+	content, _ := args.Get(1).(io.ReadCloser)
+	return args.Get(0).(models.ProductAttachment), content, args.Error(2)
+}
+
+func (m *MockService) GetExpiredProducts(ctx context.Context, pvzID uuid.UUID, employeeID *uuid.UUID) ([]models.Product, error) {
+	args := m.Called(ctx, pvzID, employeeID)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockService) ForceCloseReception(ctx context.Context, receptionID uuid.UUID, req models.ForceCloseReceptionRequest, moderatorID uuid.UUID) (models.Reception, error) {
+	args := m.Called(ctx, receptionID, req, moderatorID)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockService) ReopenReception(ctx context.Context, receptionID uuid.UUID, req models.ReopenReceptionRequest, moderatorID uuid.UUID) (models.Reception, error) {
+	args := m.Called(ctx, receptionID, req, moderatorID)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
+
+func (m *MockService) GetStaleReceptions(ctx context.Context, employeeID *uuid.UUID) ([]models.Reception, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).([]models.Reception), args.Error(1)
+}
+
+func (m *MockService) AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, userID)
+	return args.Error(0)
+}
+
+func (m *MockService) UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, userID)
+	return args.Error(0)
+}
+
+func (m *MockService) Search(ctx context.Context, params models.SearchParams) (models.SearchResult, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.SearchResult), args.Error(1)
+}
+
+func (m *MockService) GetTenant(ctx context.Context, slug string) (models.Tenant, error) {
+	args := m.Called(ctx, slug)
+	return args.Get(0).(models.Tenant), args.Error(1)
+}
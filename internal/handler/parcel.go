@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/middleware"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"net/http"
+)
+
+func (h Handler) linkParcelHandler(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "Токен не содержит идентификатор пользователя")
+		return
+	}
+
+	var req models.LinkParcelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.WarnContext(r.Context(), "Ошибка декодирования JSON при привязке посылки", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Невалидный JSON")
+		return
+	}
+
+	if !isValidBarcode(req.Barcode) {
+		slog.WarnContext(r.Context(), "Недопустимый штрихкод посылки", "barcode", req.Barcode)
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимый штрихкод")
+		return
+	}
+
+	if !isValidPickupCode(req.PickupCode) {
+		slog.WarnContext(r.Context(), "Недопустимый код получения посылки", "barcode", req.Barcode)
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимый код получения")
+		return
+	}
+
+	parcel, err := h.service.LinkParcel(r.Context(), clientID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Пользователь не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при привязке посылки", "clientId", clientID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось привязать посылку")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusCreated, parcel)
+}
+
+func (h Handler) getMyParcelsHandler(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "Токен не содержит идентификатор пользователя")
+		return
+	}
+
+	parcels, err := h.service.GetClientParcels(r.Context(), clientID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Ошибка при получении посылок клиента", "clientId", clientID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить список посылок")
+		return
+	}
+
+	sendListResponse(w, h.listEnvelope, parcels)
+}
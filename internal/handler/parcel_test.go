@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkParcelHandler(t *testing.T) {
+	clientID := uuid.New()
+	linkReq := models.LinkParcelRequest{Barcode: "RU123456789", PickupCode: "483920"}
+
+	tests := []struct {
+		name           string
+		userID         any
+		requestBody    string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Успешная привязка посылки",
+			userID:      clientID,
+			requestBody: `{"barcode":"RU123456789","pickupCode":"483920"}`,
+			mockService: func(m *MockService) {
+				m.On("LinkParcel", mock.Anything, clientID, linkReq).
+					Return(models.Parcel{ID: uuid.New(), Barcode: "RU123456789", Status: models.ParcelStatusExpected}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"status":"expected"`,
+		},
+		{
+			name:           "Токен без идентификатора пользователя",
+			userID:         nil,
+			requestBody:    `{"barcode":"RU123456789","pickupCode":"483920"}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `"message":"Токен не содержит идентификатор пользователя"`,
+		},
+		{
+			name:           "Недопустимый штрихкод",
+			userID:         clientID,
+			requestBody:    `{"barcode":"RU 1"}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Недопустимый штрихкод"`,
+		},
+		{
+			name:        "Клиент не найден",
+			userID:      clientID,
+			requestBody: `{"barcode":"RU123456789","pickupCode":"483920"}`,
+			mockService: func(m *MockService) {
+				m.On("LinkParcel", mock.Anything, clientID, linkReq).
+					Return(models.Parcel{}, apperrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Пользователь не найден"`,
+		},
+		{
+			name:           "Без кода получения",
+			userID:         clientID,
+			requestBody:    `{"barcode":"RU123456789"}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Недопустимый код получения"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			req := httptest.NewRequest(http.MethodPost, "/my/parcels", strings.NewReader(tt.requestBody))
+			req = req.WithContext(context.WithValue(req.Context(), "userID", tt.userID))
+			rec := httptest.NewRecorder()
+
+			h.linkParcelHandler(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMyParcelsHandler(t *testing.T) {
+	clientID := uuid.New()
+	readyAt := time.Now()
+
+	tests := []struct {
+		name           string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Успешное получение посылок",
+			mockService: func(m *MockService) {
+				m.On("GetClientParcels", mock.Anything, clientID).Return([]models.Parcel{{
+					ID:      uuid.New(),
+					Barcode: "RU123456789",
+					Status:  models.ParcelStatusReadyForPickup,
+					ReadyAt: &readyAt,
+					PVZ:     &models.ParcelPVZ{ID: uuid.New(), City: "Казань"},
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"ready_for_pickup"`,
+		},
+		{
+			name: "Ошибка сервиса",
+			mockService: func(m *MockService) {
+				m.On("GetClientParcels", mock.Anything, clientID).Return([]models.Parcel(nil), errors.New("ошибка сервиса"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"message":"Не удалось получить список посылок"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			req := httptest.NewRequest(http.MethodGet, "/my/parcels", nil)
+			req = req.WithContext(context.WithValue(req.Context(), "userID", clientID))
+			rec := httptest.NewRecorder()
+
+			h.getMyParcelsHandler(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
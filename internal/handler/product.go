@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/middleware"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"net/http"
+)
+
+type releaseProductFunc func(ctx context.Context, productID uuid.UUID, employeeID uuid.UUID) (models.Product, error)
+
+func (h Handler) issueProductHandler(w http.ResponseWriter, r *http.Request) {
+	h.releaseProduct(w, r, h.service.IssueProduct)
+}
+
+func (h Handler) returnProductHandler(w http.ResponseWriter, r *http.Request) {
+	h.releaseProduct(w, r, h.service.ReturnProduct)
+}
+
+func (h Handler) releaseProduct(w http.ResponseWriter, r *http.Request, release releaseProductFunc) {
+	productIDParam := chi.URLParam(r, "productId")
+	productID, err := uuid.Parse(productIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID товара", "productId", productIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора товара")
+		return
+	}
+
+	// Токен без user_id не закреплён ни за одним ПВЗ, и выдача будет отклонена.
+	employeeID, _ := middleware.UserIDFromContext(r.Context())
+
+	product, err := release(r.Context(), productID, employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrProductNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Товар не найден")
+		case errors.Is(err, apperrors.ErrProductAlreadyReleased):
+			writeErrorResponse(w, http.StatusBadRequest, "Товар уже выдан или возвращён")
+		case errors.Is(err, apperrors.ErrProductNotReady):
+			writeErrorResponse(w, http.StatusBadRequest, "Товар ещё не принят: приёмка не закрыта")
+		case errors.Is(err, apperrors.ErrProductExpired):
+			writeErrorResponse(w, http.StatusBadRequest, "Срок хранения товара истёк: его можно только вернуть отправителю")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за этим ПВЗ")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при снятии товара с полки", "productId", productID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, product)
+}
+
+func (h Handler) restoreProductHandler(w http.ResponseWriter, r *http.Request) {
+	productIDParam := chi.URLParam(r, "productId")
+	productID, err := uuid.Parse(productIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID товара", "productId", productIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора товара")
+		return
+	}
+
+	moderatorID, _ := middleware.UserIDFromContext(r.Context())
+
+	product, err := h.service.RestoreProduct(r.Context(), productID, moderatorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrProductNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Товар не найден")
+		case errors.Is(err, apperrors.ErrProductNotDeleted):
+			writeErrorResponse(w, http.StatusBadRequest, "Товар не удалён")
+		case errors.Is(err, apperrors.ErrReceptionAlreadyClosed):
+			writeErrorResponse(w, http.StatusConflict, "Восстановить можно только товар открытой приёмки")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при восстановлении товара", "productId", productID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, product)
+}
+
+func (h Handler) getPVZStockHandler(w http.ResponseWriter, r *http.Request) {
+	pvzIDParam := chi.URLParam(r, "pvzId")
+	pvzID, err := uuid.Parse(pvzIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при получении остатков", "pvzId", pvzIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
+		return
+	}
+
+	condition, err := parseConditionFilter(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое состояние товара")
+		return
+	}
+
+	stock, err := h.service.GetPVZStock(r.Context(), pvzID, condition, employeeScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "ПВЗ не найден")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за этим ПВЗ")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении остатков ПВЗ", "pvzId", pvzID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить остатки ПВЗ")
+		}
+		return
+	}
+
+	if h.listEnvelope {
+		sendJSONResponse(w, http.StatusOK, models.PVZStockResponse{
+			PVZID:        stock.PVZID,
+			Items:        nonNil(stock.Products),
+			Total:        stock.Total,
+			CountsByType: stock.CountsByType,
+			Damaged:      stock.Damaged,
+			Opened:       stock.Opened,
+		})
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, stock)
+}
+
+func (h Handler) getExpiredProductsHandler(w http.ResponseWriter, r *http.Request) {
+	pvzIDParam := chi.URLParam(r, "pvzId")
+	pvzID, err := uuid.Parse(pvzIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при получении просроченных товаров", "pvzId", pvzIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
+		return
+	}
+
+	products, err := h.service.GetExpiredProducts(r.Context(), pvzID, employeeScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "ПВЗ не найден")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за этим ПВЗ")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении просроченных товаров", "pvzId", pvzID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить товары с истёкшим сроком хранения")
+		}
+		return
+	}
+
+	sendListResponse(w, h.listEnvelope, products)
+}
@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReleaseProductHandlers(t *testing.T) {
+	productID := uuid.New()
+	employeeID := uuid.New()
+
+	tests := []struct {
+		name           string
+		path           string
+		method         string
+		productIDParam string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Успешная выдача товара",
+			path:           "issue",
+			method:         "IssueProduct",
+			productIDParam: productID.String(),
+			mockService: func(m *MockService) {
+				m.On("IssueProduct", mock.Anything, productID, employeeID).
+					Return(models.Product{ID: productID, Status: models.ProductStatusIssued}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"issued"`,
+		},
+		{
+			name:           "Успешный возврат курьеру",
+			path:           "return",
+			method:         "ReturnProduct",
+			productIDParam: productID.String(),
+			mockService: func(m *MockService) {
+				m.On("ReturnProduct", mock.Anything, productID, employeeID).
+					Return(models.Product{ID: productID, Status: models.ProductStatusReturned}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"returned"`,
+		},
+		{
+			name:           "Товар уже выдан",
+			path:           "issue",
+			productIDParam: productID.String(),
+			mockService: func(m *MockService) {
+				m.On("IssueProduct", mock.Anything, productID, employeeID).
+					Return(models.Product{}, apperrors.ErrProductAlreadyReleased)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Товар уже выдан или возвращён"`,
+		},
+		{
+			name:           "Приёмка товара не закрыта",
+			path:           "issue",
+			productIDParam: productID.String(),
+			mockService: func(m *MockService) {
+				m.On("IssueProduct", mock.Anything, productID, employeeID).
+					Return(models.Product{}, apperrors.ErrProductNotReady)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Товар ещё не принят: приёмка не закрыта"`,
+		},
+		{
+			name:           "Выдача товара с истёкшим сроком хранения",
+			path:           "issue",
+			productIDParam: productID.String(),
+			mockService: func(m *MockService) {
+				m.On("IssueProduct", mock.Anything, productID, employeeID).
+					Return(models.Product{}, apperrors.ErrProductExpired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Срок хранения товара истёк: его можно только вернуть отправителю"`,
+		},
+		{
+			name:           "Сотрудник не закреплён за ПВЗ товара",
+			path:           "return",
+			productIDParam: productID.String(),
+			mockService: func(m *MockService) {
+				m.On("ReturnProduct", mock.Anything, productID, employeeID).
+					Return(models.Product{}, apperrors.ErrEmployeeNotAssigned)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"message":"Сотрудник не закреплён за этим ПВЗ"`,
+		},
+		{
+			name:           "Товар не найден",
+			path:           "return",
+			productIDParam: productID.String(),
+			mockService: func(m *MockService) {
+				m.On("ReturnProduct", mock.Anything, productID, employeeID).
+					Return(models.Product{}, apperrors.ErrProductNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Товар не найден"`,
+		},
+		{
+			name:           "Некорректный UUID товара",
+			path:           "issue",
+			productIDParam: "invalid-uuid",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Неверный формат идентификатора товара"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Post("/products/{productId}/issue", h.issueProductHandler)
+			router.Post("/products/{productId}/return", h.returnProductHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/products/"+tt.productIDParam+"/"+tt.path, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "userID", employeeID))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestoreProductHandler(t *testing.T) {
+	productID := uuid.New()
+	moderatorID := uuid.New()
+
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Успешное восстановление",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":"` + productID.String() + `"`,
+		},
+		{
+			name:           "Товар не найден",
+			serviceErr:     apperrors.ErrProductNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Товар не найден"`,
+		},
+		{
+			name:           "Товар не удалён",
+			serviceErr:     apperrors.ErrProductNotDeleted,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Товар не удалён"`,
+		},
+		{
+			name:           "Приёмка закрыта",
+			serviceErr:     apperrors.ErrReceptionAlreadyClosed,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"message":"Восстановить можно только товар открытой приёмки"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			product := models.Product{ID: productID}
+			if tt.serviceErr != nil {
+				product = models.Product{}
+			}
+			mockService.On("RestoreProduct", mock.Anything, productID, moderatorID).Return(product, tt.serviceErr)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Post("/products/{productId}/restore", h.restoreProductHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/products/"+productID.String()+"/restore", nil)
+			req = req.WithContext(context.WithValue(req.Context(), "userID", moderatorID))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPVZStockHandler(t *testing.T) {
+	pvzID := uuid.New()
+	employeeID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		role           string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Успешное получение остатков",
+			mockService: func(m *MockService) {
+				m.On("GetPVZStock", mock.Anything, pvzID, "", (*uuid.UUID)(nil)).Return(models.PVZStock{
+					PVZID:        pvzID,
+					Total:        2,
+					CountsByType: map[string]int{"обувь": 2},
+					Products:     []models.Product{{ID: uuid.New()}, {ID: uuid.New()}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"countsByType":{"обувь":2}`,
+		},
+		{
+			name:  "Фильтр по повреждённым товарам",
+			query: "?condition=damaged",
+			mockService: func(m *MockService) {
+				m.On("GetPVZStock", mock.Anything, pvzID, models.ProductConditionDamaged, (*uuid.UUID)(nil)).Return(models.PVZStock{
+					PVZID:        pvzID,
+					Total:        1,
+					CountsByType: map[string]int{"одежда": 1},
+					Damaged:      1,
+					Products:     []models.Product{{ID: uuid.New(), Condition: models.ProductConditionDamaged}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"damaged":1`,
+		},
+		{
+			name:           "Недопустимое состояние товара",
+			query:          "?condition=broken",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Недопустимое состояние товара"`,
+		},
+		{
+			name: "Остатки ПВЗ закреплённого сотрудника",
+			role: "employee",
+			mockService: func(m *MockService) {
+				m.On("GetPVZStock", mock.Anything, pvzID, "", &employeeID).
+					Return(models.PVZStock{PVZID: pvzID, CountsByType: map[string]int{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"total":0`,
+		},
+		{
+			name: "Сотрудник не закреплён за ПВЗ",
+			role: "employee",
+			mockService: func(m *MockService) {
+				m.On("GetPVZStock", mock.Anything, pvzID, "", &employeeID).
+					Return(models.PVZStock{}, apperrors.ErrEmployeeNotAssigned)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"message":"Сотрудник не закреплён за этим ПВЗ"`,
+		},
+		{
+			name: "ПВЗ не найден",
+			mockService: func(m *MockService) {
+				m.On("GetPVZStock", mock.Anything, pvzID, "", (*uuid.UUID)(nil)).Return(models.PVZStock{}, apperrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"ПВЗ не найден"`,
+		},
+		{
+			name: "Ошибка сервиса",
+			mockService: func(m *MockService) {
+				m.On("GetPVZStock", mock.Anything, pvzID, "", (*uuid.UUID)(nil)).Return(models.PVZStock{}, errors.New("ошибка сервиса"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"message":"Не удалось получить остатки ПВЗ"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Get("/pvz/{pvzId}/stock", h.getPVZStockHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/stock"+tt.query, nil)
+			ctx := context.WithValue(req.Context(), "role", tt.role)
+			ctx = context.WithValue(ctx, "userID", employeeID)
+			req = req.WithContext(ctx)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetExpiredProductsHandler(t *testing.T) {
+	pvzID, employeeID := uuid.New(), uuid.New()
+	expiredAt := time.Now()
+
+	tests := []struct {
+		name           string
+		role           string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Успешное получение просроченных товаров",
+			mockService: func(m *MockService) {
+				m.On("GetExpiredProducts", mock.Anything, pvzID, mock.Anything).Return([]models.Product{
+					{ID: uuid.New(), Status: models.ProductStatusExpired, ExpiredAt: &expiredAt},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"expired"`,
+		},
+		{
+			name: "ПВЗ не найден",
+			mockService: func(m *MockService) {
+				m.On("GetExpiredProducts", mock.Anything, pvzID, mock.Anything).Return([]models.Product(nil), apperrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"ПВЗ не найден"`,
+		},
+		{
+			name: "Сотрудник не закреплён за ПВЗ",
+			role: "employee",
+			mockService: func(m *MockService) {
+				m.On("GetExpiredProducts", mock.Anything, pvzID, &employeeID).Return([]models.Product(nil), apperrors.ErrEmployeeNotAssigned)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"message":"Сотрудник не закреплён за этим ПВЗ"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Get("/pvz/{pvzId}/expired", h.getExpiredProductsHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/expired", nil)
+			ctx := context.WithValue(req.Context(), "role", tt.role)
+			ctx = context.WithValue(ctx, "userID", employeeID)
+			req = req.WithContext(ctx)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -2,28 +2,33 @@ package handler
 
 import (
 	"encoding/json"
-	"github.com/gookit/slog"
+	"errors"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/middleware"
 	"github.com/kstsm/pvz-service/models"
+	"log/slog"
 	"net/http"
 )
 
 func (h Handler) createPVZHandler(w http.ResponseWriter, r *http.Request) {
 	var req models.PVZ
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Warn("Ошибка декодирования JSON при создании ПВЗ", "error", err)
+		slog.WarnContext(r.Context(), "Ошибка декодирования JSON при создании ПВЗ", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Невалидный JSON")
 		return
 	}
 
 	if !isValidCity(req.City) {
-		slog.Warn("Попытка создать ПВЗ в недопустимом городе", "city", req.City)
+		slog.WarnContext(r.Context(), "Попытка создать ПВЗ в недопустимом городе", "city", req.City)
 		writeErrorResponse(w, http.StatusBadRequest, "Данный город пока недоступен")
 		return
 	}
 
 	pvz, err := h.service.CreatePVZ(r.Context(), req.City)
 	if err != nil {
-		slog.Error("Ошибка при создании ПВЗ", "city", req.City, "error", err)
+		slog.ErrorContext(r.Context(), "Ошибка при создании ПВЗ", "city", req.City, "error", err)
 		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось создать ПВЗ")
 		return
 	}
@@ -32,18 +37,240 @@ func (h Handler) createPVZHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h Handler) getListPVZ(w http.ResponseWriter, r *http.Request) {
+	if _, page, ok := h.listPVZ(w, r); ok {
+		sendJSONResponse(w, http.StatusOK, page.Items)
+	}
+}
+
+func (h Handler) getListPVZV2(w http.ResponseWriter, r *http.Request) {
+	params, page, ok := h.listPVZ(w, r)
+	if !ok {
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, models.PVZListResponse{
+		Items: nonNil(page.Items),
+		Pagination: models.Pagination{
+			Page:    params.Page,
+			Limit:   params.Limit,
+			Count:   len(page.Items),
+			HasNext: page.HasNext,
+		},
+	})
+}
+
+// listPVZ разбирает фильтры и получает страницу ПВЗ. При ошибке ответ уже
+// записан и ok = false.
+func (h Handler) listPVZ(w http.ResponseWriter, r *http.Request) (models.PVZFilterParams, models.PVZPage, bool) {
 	params, err := parsePVZFilterParams(r)
 	if err != nil {
-		slog.Warn("Невалидные параметры запроса для фильтрации ПВЗ", "error", err)
+		slog.WarnContext(r.Context(), "Невалидные параметры запроса для фильтрации ПВЗ", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Невалидные параметры запроса")
-		return
+		return params, models.PVZPage{}, false
 	}
-	pvzList, err := h.service.GetPVZList(r.Context(), params)
+	page, err := h.service.GetPVZList(r.Context(), params)
 	if err != nil {
-		slog.Error("Ошибка при получении списка ПВЗ", "error", err)
+		slog.ErrorContext(r.Context(), "Ошибка при получении списка ПВЗ", "error", err)
 		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить список ПВЗ")
+		return params, models.PVZPage{}, false
+	}
+
+	return params, page, true
+}
+
+func (h Handler) getPVZHandler(w http.ResponseWriter, r *http.Request) {
+	pvzIDParam := chi.URLParam(r, "pvzId")
+	pvzID, err := uuid.Parse(pvzIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при получении ПВЗ", "pvzId", pvzIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
+		return
+	}
+
+	pvz, err := h.service.GetPVZByID(r.Context(), pvzID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "ПВЗ не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении ПВЗ", "pvzId", pvzID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить ПВЗ")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, pvz)
+}
+
+func (h Handler) updatePVZHandler(w http.ResponseWriter, r *http.Request) {
+	pvzIDParam := chi.URLParam(r, "pvzId")
+	pvzID, err := uuid.Parse(pvzIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при обновлении ПВЗ", "pvzId", pvzIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
+		return
+	}
+
+	var req models.UpdatePVZRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.WarnContext(r.Context(), "Ошибка декодирования JSON при обновлении ПВЗ", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Невалидный JSON")
+		return
+	}
+
+	if err = validateUpdatePVZRequest(req); err != nil {
+		slog.WarnContext(r.Context(), "Невалидные данные для обновления ПВЗ", "pvzId", pvzID, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pvz, err := h.service.UpdatePVZ(r.Context(), pvzID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "ПВЗ не найден")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при обновлении ПВЗ", "pvzId", pvzID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось обновить ПВЗ")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, pvz)
+}
+
+func (h Handler) decommissionPVZHandler(w http.ResponseWriter, r *http.Request) {
+	pvzIDParam := chi.URLParam(r, "pvzId")
+	pvzID, err := uuid.Parse(pvzIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при выводе из эксплуатации", "pvzId", pvzIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
+		return
+	}
+
+	moderatorID, _ := middleware.UserIDFromContext(r.Context())
+
+	err = h.service.DecommissionPVZ(r.Context(), pvzID, moderatorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "ПВЗ не найден")
+		case errors.Is(err, apperrors.ErrPVZHasOpenReception):
+			writeErrorResponse(w, http.StatusConflict, "В ПВЗ есть открытая приёмка")
+		case errors.Is(err, apperrors.ErrPVZHasStock):
+			writeErrorResponse(w, http.StatusConflict, "В ПВЗ остались невыданные товары")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при выводе ПВЗ из эксплуатации", "pvzId", pvzID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось вывести ПВЗ из эксплуатации")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, nil)
+}
+
+func (h Handler) assignEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	pvzID, userID, ok := parsePVZEmployeeParams(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.AssignEmployee(r.Context(), pvzID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "ПВЗ не найден")
+		case errors.Is(err, apperrors.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Пользователь не найден")
+		case errors.Is(err, apperrors.ErrUserNotEmployee):
+			writeErrorResponse(w, http.StatusBadRequest, "Закрепить за ПВЗ можно только сотрудника")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при закреплении сотрудника за ПВЗ", "pvzId", pvzID, "userId", userID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось закрепить сотрудника")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, nil)
+}
+
+func (h Handler) unassignEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	pvzID, userID, ok := parsePVZEmployeeParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnassignEmployee(r.Context(), pvzID, userID); err != nil {
+		slog.ErrorContext(r.Context(), "Ошибка при откреплении сотрудника от ПВЗ", "pvzId", pvzID, "userId", userID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось открепить сотрудника")
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, nil)
+}
+
+func parsePVZEmployeeParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	pvzID, err := uuid.Parse(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора пользователя")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return pvzID, userID, true
+}
+
+func (h Handler) getNearbyPVZHandler(w http.ResponseWriter, r *http.Request) {
+	params, err := parseNearbyPVZParams(r)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Невалидные параметры поиска ближайших ПВЗ", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pvzList, err := h.service.GetNearbyPVZ(r.Context(), params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Ошибка при поиске ближайших ПВЗ", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось найти ближайшие ПВЗ")
+		return
+	}
+
+	sendListResponse(w, h.listEnvelope, pvzList)
+}
+
+func (h Handler) getPVZDailyReportHandler(w http.ResponseWriter, r *http.Request) {
+	pvzIDParam := chi.URLParam(r, "pvzId")
+	pvzID, err := uuid.Parse(pvzIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при построении отчёта", "pvzId", pvzIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
+		return
+	}
+
+	startDay, endDay, err := parseReportPeriod(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.GetPVZDailyReport(r.Context(), pvzID, startDay, endDay, employeeScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "ПВЗ не найден")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за этим ПВЗ")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при построении дневного отчёта ПВЗ", "pvzId", pvzID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось построить отчёт")
+		}
 		return
 	}
 
-	sendJSONResponse(w, http.StatusOK, pvzList)
+	sendJSONResponse(w, http.StatusOK, report)
 }
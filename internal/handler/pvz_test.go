@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"github.com/go-chi/chi"
 	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -33,7 +36,7 @@ func TestGetListPVZ(t *testing.T) {
 					Page:  1,
 					Limit: 10,
 				}).
-					Return([]models.PVZWithReceptions{
+					Return(models.PVZPage{Items: []models.PVZWithReceptions{
 						{
 							PVZ: models.PVZ{
 								ID:               uuid.New(),
@@ -49,7 +52,7 @@ func TestGetListPVZ(t *testing.T) {
 								},
 							},
 						},
-					}, nil)
+					}}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `"city":"Москва"`,
@@ -65,7 +68,7 @@ func TestGetListPVZ(t *testing.T) {
 					Page:  1,
 					Limit: 10,
 				}).
-					Return([]models.PVZWithReceptions(nil), errors.New("ошибка сервиса"))
+					Return(models.PVZPage{}, errors.New("ошибка сервиса"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `"message":"Не удалось получить список ПВЗ"`,
@@ -201,3 +204,356 @@ func TestCreatePVZHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPVZHandler(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name           string
+		pvzIDParam     string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "Успешное получение ПВЗ",
+			pvzIDParam: pvzID.String(),
+			mockService: func(m *MockService) {
+				m.On("GetPVZByID", mock.Anything, pvzID).
+					Return(models.PVZ{ID: pvzID, City: "Казань", Status: models.PVZStatusActive}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"active"`,
+		},
+		{
+			name:       "ПВЗ не найден",
+			pvzIDParam: pvzID.String(),
+			mockService: func(m *MockService) {
+				m.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{}, apperrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"ПВЗ не найден"`,
+		},
+		{
+			name:           "Некорректный UUID",
+			pvzIDParam:     "invalid-uuid",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Неверный формат идентификатора ПВЗ"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Get("/pvz/{pvzId}", h.getPVZHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/"+tt.pvzIDParam, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdatePVZHandler(t *testing.T) {
+	pvzID := uuid.New()
+	closed := models.PVZStatusTemporarilyClosed
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Успешное обновление статуса",
+			requestBody: `{"status":"temporarily_closed"}`,
+			mockService: func(m *MockService) {
+				m.On("UpdatePVZ", mock.Anything, pvzID, models.UpdatePVZRequest{Status: &closed}).
+					Return(models.PVZ{ID: pvzID, Status: closed}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"temporarily_closed"`,
+		},
+		{
+			name:           "Недопустимый статус",
+			requestBody:    `{"status":"deleted"}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `недопустимый статус ПВЗ`,
+		},
+		{
+			name:           "Широта без долготы",
+			requestBody:    `{"latitude":55.75}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `широта и долгота должны передаваться вместе`,
+		},
+		{
+			name:           "Некорректный JSON",
+			requestBody:    `{"status":`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Невалидный JSON"`,
+		},
+		{
+			name:        "ПВЗ не найден",
+			requestBody: `{"status":"temporarily_closed"}`,
+			mockService: func(m *MockService) {
+				m.On("UpdatePVZ", mock.Anything, pvzID, models.UpdatePVZRequest{Status: &closed}).
+					Return(models.PVZ{}, apperrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"ПВЗ не найден"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Patch("/pvz/{pvzId}", h.updatePVZHandler)
+
+			req := httptest.NewRequest(http.MethodPatch, "/pvz/"+pvzID.String(), strings.NewReader(tt.requestBody))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDecommissionPVZHandler(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Успешный вывод из эксплуатации",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "ПВЗ не найден",
+			serviceErr:     apperrors.ErrPVZNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"ПВЗ не найден"`,
+		},
+		{
+			name:           "Есть открытая приёмка",
+			serviceErr:     apperrors.ErrPVZHasOpenReception,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"message":"В ПВЗ есть открытая приёмка"`,
+		},
+		{
+			name:           "Остались товары",
+			serviceErr:     apperrors.ErrPVZHasStock,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"message":"В ПВЗ остались невыданные товары"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			mockService.On("DecommissionPVZ", mock.Anything, pvzID, mock.Anything).Return(tt.serviceErr)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Delete("/pvz/{pvzId}", h.decommissionPVZHandler)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/pvz/"+pvzID.String(), nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetNearbyPVZHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Успешный поиск с параметрами по умолчанию",
+			query: "lat=55.75&lon=37.61",
+			mockService: func(m *MockService) {
+				m.On("GetNearbyPVZ", mock.Anything, models.NearbyPVZParams{
+					Latitude: 55.75, Longitude: 37.61, RadiusKm: 5, Limit: 10,
+				}).Return([]models.PVZWithDistance{
+					{PVZ: models.PVZ{ID: uuid.New(), City: "Москва"}, DistanceKm: 1.2},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"distanceKm":1.2`,
+		},
+		{
+			name:  "Только открытые сейчас",
+			query: "lat=55.75&lon=37.61&radius=2.5&limit=3&openNow=true",
+			mockService: func(m *MockService) {
+				m.On("GetNearbyPVZ", mock.Anything, models.NearbyPVZParams{
+					Latitude: 55.75, Longitude: 37.61, RadiusKm: 2.5, Limit: 3, OpenNow: true,
+				}).Return([]models.PVZWithDistance{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "Отсутствует широта",
+			query:          "lon=37.61",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `неверное значение широты`,
+		},
+		{
+			name:           "Слишком большой радиус",
+			query:          "lat=55.75&lon=37.61&radius=500",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `радиус должен быть`,
+		},
+		{
+			name:  "Ошибка сервиса",
+			query: "lat=55.75&lon=37.61",
+			mockService: func(m *MockService) {
+				m.On("GetNearbyPVZ", mock.Anything, mock.Anything).
+					Return([]models.PVZWithDistance(nil), errors.New("ошибка сервиса"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"message":"Не удалось найти ближайшие ПВЗ"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/nearby?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			h.getNearbyPVZHandler(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPVZDailyReportHandler(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name           string
+		pvzID          string
+		query          string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Успешное построение отчёта",
+			pvzID: pvzID.String(),
+			query: "startDate=2026-10-01&endDate=2026-10-02",
+			mockService: func(m *MockService) {
+				m.On("GetPVZDailyReport", mock.Anything, pvzID, "2026-10-01", "2026-10-02", mock.Anything).Return(models.PVZDailyReport{
+					PVZID:     pvzID,
+					Timezone:  "Europe/Moscow",
+					StartDate: "2026-10-01",
+					EndDate:   "2026-10-02",
+					Days: []models.DailyReceptionStats{
+						{Date: "2026-10-01", Receptions: 1, Products: 3},
+						{Date: "2026-10-02"},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"date":"2026-10-01","receptions":1,"products":3}`,
+		},
+		{
+			name:           "Некорректный UUID ПВЗ",
+			pvzID:          "not-a-uuid",
+			query:          "startDate=2026-10-01&endDate=2026-10-02",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Неверный формат идентификатора ПВЗ"`,
+		},
+		{
+			name:           "Нет периода",
+			pvzID:          pvzID.String(),
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `неверная дата начала`,
+		},
+		{
+			name:  "ПВЗ не найден",
+			pvzID: pvzID.String(),
+			query: "startDate=2026-10-01&endDate=2026-10-02",
+			mockService: func(m *MockService) {
+				m.On("GetPVZDailyReport", mock.Anything, pvzID, "2026-10-01", "2026-10-02", mock.Anything).
+					Return(models.PVZDailyReport{}, apperrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"ПВЗ не найден"`,
+		},
+		{
+			name:  "Ошибка сервиса",
+			pvzID: pvzID.String(),
+			query: "startDate=2026-10-01&endDate=2026-10-02",
+			mockService: func(m *MockService) {
+				m.On("GetPVZDailyReport", mock.Anything, pvzID, "2026-10-01", "2026-10-02", mock.Anything).
+					Return(models.PVZDailyReport{}, errors.New("ошибка сервиса"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"message":"Не удалось построить отчёт"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Get("/pvz/{pvzId}/reports/daily", h.getPVZDailyReportHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/"+tt.pvzID+"/reports/daily?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -6,29 +6,45 @@ import (
 	"fmt"
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
-	"github.com/gookit/slog"
 	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/middleware"
 	"github.com/kstsm/pvz-service/models"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 func (h Handler) createReceptionHandler(w http.ResponseWriter, r *http.Request) {
-	var req models.Reception
+	var req models.CreateReceptionRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Warn("Некорректный JSON в теле запроса при создании приёмки", "error", err)
+		slog.WarnContext(r.Context(), "Некорректный JSON в теле запроса при создании приёмки", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Невалидный JSON")
 		return
 	}
 
-	reception, err := h.service.CreateReception(r.Context(), req.PVZID)
+	if err := validateManifest(req.Manifest); err != nil {
+		slog.WarnContext(r.Context(), "Некорректный манифест приёмки", "pvzId", req.PVZID, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Некорректный манифест: "+err.Error())
+		return
+	}
+
+	reception, err := h.service.CreateReception(r.Context(), req)
 	if err != nil {
 		switch {
 		case errors.Is(err, apperrors.ErrReceptionAlreadyInProgress):
-			slog.Info("Попытка создания приёмки при уже открытой приёмке", "pvzId", req.PVZID)
+			slog.InfoContext(r.Context(), "Попытка создания приёмки при уже открытой приёмке", "pvzId", req.PVZID)
 			writeErrorResponse(w, http.StatusBadRequest, "Невозможно создать приёмку: предыдущая не закрыта")
+		case errors.Is(err, apperrors.ErrPVZNotFound):
+			slog.WarnContext(r.Context(), "Попытка создания приёмки в несуществующем ПВЗ", "pvzId", req.PVZID)
+			writeErrorResponse(w, http.StatusBadRequest, "ПВЗ не найден")
+		case errors.Is(err, apperrors.ErrPVZNotActive):
+			slog.WarnContext(r.Context(), "Попытка создания приёмки в неактивном ПВЗ", "pvzId", req.PVZID)
+			writeErrorResponse(w, http.StatusBadRequest, "ПВЗ временно закрыт или выведен из эксплуатации")
 		default:
-			slog.Error("Внутренняя ошибка при создании приёмки", "error", err, "pvzId", req.PVZID)
+			slog.ErrorContext(r.Context(), "Внутренняя ошибка при создании приёмки", "error", err, "pvzId", req.PVZID)
 			writeErrorResponse(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
 		}
 		return
@@ -41,31 +57,48 @@ func (h Handler) addProductToReceptionHandler(w http.ResponseWriter, r *http.Req
 	var req models.AddProductRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Warn("Ошибка декодирования JSON при добавлении товара", "error", err)
+		slog.WarnContext(r.Context(), "Ошибка декодирования JSON при добавлении товара", "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат запроса")
 		return
 	}
 
 	if req.Type == "" || req.PVZID == uuid.Nil {
-		slog.Warn("Отсутствуют обязательные поля в запросе на добавление товара", "req", req)
+		slog.WarnContext(r.Context(), "Отсутствуют обязательные поля в запросе на добавление товара", "req", req)
 		writeErrorResponse(w, http.StatusBadRequest, "Тип товара и PVZ ID обязательны")
 		return
 	}
 
 	if !isValidProduct(req.Type) {
-		slog.Error("Недопустимый продукт", "role", req.Type)
+		slog.ErrorContext(r.Context(), "Недопустимый продукт", "role", req.Type)
 		writeErrorResponse(w, http.StatusBadRequest, "Недопустимый продукт")
 		return
 	}
 
-	product, err := h.service.AddProductToActiveReception(r.Context(), req.Type, req.PVZID)
+	if req.Barcode != "" && !isValidBarcode(req.Barcode) {
+		slog.WarnContext(r.Context(), "Недопустимый штрихкод товара", "barcode", req.Barcode)
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимый штрихкод")
+		return
+	}
+
+	if req.Condition != "" && !isValidCondition(req.Condition) {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое состояние товара")
+		return
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(req.Note) > maxNoteLength {
+		writeErrorResponse(w, http.StatusBadRequest, "Комментарий к товару не должен превышать 1000 символов")
+		return
+	}
+
+	product, err := h.service.AddProductToActiveReception(r.Context(), req)
 	if err != nil {
 		switch {
 		case errors.Is(err, apperrors.ErrNoActiveReception):
-			slog.Warn("Ошибка при добавлении товара: нет активной приёмки", "req", req, "error", err)
+			slog.WarnContext(r.Context(), "Ошибка при добавлении товара: нет активной приёмки", "req", req, "error", err)
 			writeErrorResponse(w, http.StatusBadRequest, err.Error())
 		default:
-			slog.Warn("Ошибка при добавлении товара в приёмку", "req", req, "error", err)
+			slog.WarnContext(r.Context(), "Ошибка при добавлении товара в приёмку", "req", req, "error", err)
 			writeErrorResponse(w, http.StatusInternalServerError, "Ошибка сервера: "+err.Error())
 		}
 		return
@@ -78,22 +111,24 @@ func (h Handler) deleteLastProductHandler(w http.ResponseWriter, r *http.Request
 	pvzIDStr := chi.URLParam(r, "pvzId")
 	pvzID, err := uuid.Parse(pvzIDStr)
 	if err != nil {
-		slog.Warn("Некорректный UUID ПВЗ при удалении товара", "pvzId", pvzIDStr, "error", err)
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при удалении товара", "pvzId", pvzIDStr, "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора ПВЗ")
 		return
 	}
 
-	err = h.service.DeleteLastProductInReception(r.Context(), pvzID)
+	employeeID, _ := middleware.UserIDFromContext(r.Context())
+
+	err = h.service.DeleteLastProductInReception(r.Context(), pvzID, employeeID)
 	if err != nil {
 		switch {
 		case errors.Is(err, apperrors.ErrNoActiveReception):
-			slog.Warn("Нет активной приёмки для удаления товара", "pvzId", pvzID, "error", err)
+			slog.WarnContext(r.Context(), "Нет активной приёмки для удаления товара", "pvzId", pvzID, "error", err)
 			writeErrorResponse(w, http.StatusBadRequest, "Нет активной приёмки для удаления товара")
 		case errors.Is(err, apperrors.ErrNoProductToDelete):
-			slog.Warn("Нет товаров для удаления в активной приёмке", "pvzId", pvzID, "error", err)
+			slog.WarnContext(r.Context(), "Нет товаров для удаления в активной приёмке", "pvzId", pvzID, "error", err)
 			writeErrorResponse(w, http.StatusBadRequest, "Нет товаров для удаления в активной приёмке")
 		default:
-			slog.Error("Ошибка при удалении товара из приёмки", "pvzId", pvzID, "error", err)
+			slog.ErrorContext(r.Context(), "Ошибка при удалении товара из приёмки", "pvzId", pvzID, "error", err)
 			writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Внутренняя ошибка: %s", err.Error()))
 		}
 		return
@@ -106,19 +141,31 @@ func (h Handler) closeLastReceptionHandler(w http.ResponseWriter, r *http.Reques
 	pvzIDParam := chi.URLParam(r, "pvzId")
 	pvzID, err := uuid.Parse(pvzIDParam)
 	if err != nil {
-		slog.Warn("Некорректный UUID ПВЗ при закрытии приёмки", "pvzId", pvzIDParam, "error", err)
+		slog.WarnContext(r.Context(), "Некорректный UUID ПВЗ при закрытии приёмки", "pvzId", pvzIDParam, "error", err)
 		writeErrorResponse(w, http.StatusBadRequest, "Некорректный идентификатор ПВЗ")
 		return
 	}
 
-	reception, err := h.service.CloseLastReception(r.Context(), pvzID)
+	var acknowledge bool
+	if value := r.URL.Query().Get("acknowledgeDiscrepancies"); value != "" {
+		acknowledge, err = strconv.ParseBool(value)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Некорректное значение acknowledgeDiscrepancies")
+			return
+		}
+	}
+
+	reception, err := h.service.CloseLastReception(r.Context(), pvzID, acknowledge)
 	if err != nil {
 		switch {
 		case errors.Is(err, apperrors.ErrReceptionAlreadyClosed):
-			slog.Warn("Попытка закрыть уже закрытую или не найденную приемку", "pvzId", pvzID, "error", err)
+			slog.WarnContext(r.Context(), "Попытка закрыть уже закрытую или не найденную приемку", "pvzId", pvzID, "error", err)
 			writeErrorResponse(w, http.StatusBadRequest, "Приемка уже закрыта или не найдена")
+		case errors.Is(err, apperrors.ErrReceptionHasDiscrepancies):
+			writeErrorResponse(w, http.StatusConflict,
+				"Приёмка расходится с манифестом: проверьте отчёт о расхождениях и повторите закрытие с acknowledgeDiscrepancies=true")
 		default:
-			slog.Error("Ошибка при закрытии приемки", "pvzId", pvzID, "error", err)
+			slog.ErrorContext(r.Context(), "Ошибка при закрытии приемки", "pvzId", pvzID, "error", err)
 			writeErrorResponse(w, http.StatusInternalServerError, "Ошибка сервера: "+err.Error())
 		}
 
@@ -127,3 +174,192 @@ func (h Handler) closeLastReceptionHandler(w http.ResponseWriter, r *http.Reques
 
 	sendJSONResponse(w, http.StatusOK, reception)
 }
+
+func (h Handler) forceCloseReceptionHandler(w http.ResponseWriter, r *http.Request) {
+	receptionIDParam := chi.URLParam(r, "receptionId")
+	receptionID, err := uuid.Parse(receptionIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID приёмки при принудительном закрытии", "receptionId", receptionIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора приёмки")
+		return
+	}
+
+	var req models.ForceCloseReceptionRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.WarnContext(r.Context(), "Ошибка декодирования JSON при принудительном закрытии приёмки", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Невалидный JSON")
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !isValidReason(req.Reason) {
+		writeErrorResponse(w, http.StatusBadRequest, "Причина закрытия обязательна и не должна превышать 500 символов")
+		return
+	}
+
+	moderatorID, _ := middleware.UserIDFromContext(r.Context())
+
+	reception, err := h.service.ForceCloseReception(r.Context(), receptionID, req, moderatorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrReceptionNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Приёмка не найдена")
+		case errors.Is(err, apperrors.ErrReceptionAlreadyClosed):
+			writeErrorResponse(w, http.StatusBadRequest, "Приемка уже закрыта или не найдена")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при принудительном закрытии приёмки", "receptionId", receptionID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, reception)
+}
+
+func (h Handler) reopenReceptionHandler(w http.ResponseWriter, r *http.Request) {
+	receptionIDParam := chi.URLParam(r, "receptionId")
+	receptionID, err := uuid.Parse(receptionIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID приёмки при переоткрытии", "receptionId", receptionIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора приёмки")
+		return
+	}
+
+	var req models.ReopenReceptionRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.WarnContext(r.Context(), "Ошибка декодирования JSON при переоткрытии приёмки", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Невалидный JSON")
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !isValidReason(req.Reason) {
+		writeErrorResponse(w, http.StatusBadRequest, "Причина переоткрытия обязательна и не должна превышать 500 символов")
+		return
+	}
+
+	moderatorID, _ := middleware.UserIDFromContext(r.Context())
+
+	reception, err := h.service.ReopenReception(r.Context(), receptionID, req, moderatorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrReceptionNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Приёмка не найдена")
+		case errors.Is(err, apperrors.ErrReceptionNotClosed):
+			writeErrorResponse(w, http.StatusBadRequest, "Переоткрыть можно только закрытую приёмку")
+		case errors.Is(err, apperrors.ErrReopenWindowExpired):
+			writeErrorResponse(w, http.StatusConflict, "Время, в течение которого приёмку можно переоткрыть, истекло")
+		case errors.Is(err, apperrors.ErrNewerReceptionExists):
+			writeErrorResponse(w, http.StatusConflict, "В ПВЗ уже заведена более новая приёмка")
+		case errors.Is(err, apperrors.ErrReceptionAlreadyInProgress):
+			writeErrorResponse(w, http.StatusConflict, "В ПВЗ уже есть открытая приёмка")
+		case errors.Is(err, apperrors.ErrReceptionProductsReleased):
+			writeErrorResponse(w, http.StatusConflict, "Часть товаров приёмки уже выдана, возвращена или просрочена")
+		case errors.Is(err, apperrors.ErrPVZNotActive):
+			writeErrorResponse(w, http.StatusConflict, "ПВЗ выведен из эксплуатации")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при переоткрытии приёмки", "receptionId", receptionID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, reception)
+}
+
+func (h Handler) getStaleReceptionsHandler(w http.ResponseWriter, r *http.Request) {
+	receptions, err := h.service.GetStaleReceptions(r.Context(), employeeScope(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Ошибка при получении зависших приёмок", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить зависшие приёмки")
+		return
+	}
+
+	sendListResponse(w, h.listEnvelope, receptions)
+}
+
+func (h Handler) getReceptionHandler(w http.ResponseWriter, r *http.Request) {
+	receptionIDParam := chi.URLParam(r, "receptionId")
+	receptionID, err := uuid.Parse(receptionIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID приёмки", "receptionId", receptionIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора приёмки")
+		return
+	}
+
+	condition, err := parseConditionFilter(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Недопустимое состояние товара")
+		return
+	}
+
+	reception, err := h.service.GetReception(r.Context(), receptionID, condition, employeeScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrReceptionNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Приёмка не найдена")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за этим ПВЗ")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении приёмки", "receptionId", receptionID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить приёмку")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, reception)
+}
+
+func (h Handler) getDiscrepancyReportHandler(w http.ResponseWriter, r *http.Request) {
+	receptionIDParam := chi.URLParam(r, "receptionId")
+	receptionID, err := uuid.Parse(receptionIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID приёмки при получении расхождений", "receptionId", receptionIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора приёмки")
+		return
+	}
+
+	report, err := h.service.GetDiscrepancyReport(r.Context(), receptionID, employeeScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrDiscrepancyReportNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Отчёт о расхождениях не найден")
+		case errors.Is(err, apperrors.ErrReceptionNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Приёмка не найдена")
+		case errors.Is(err, apperrors.ErrEmployeeNotAssigned):
+			writeErrorResponse(w, http.StatusForbidden, "Сотрудник не закреплён за этим ПВЗ")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении отчёта о расхождениях", "receptionId", receptionID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить отчёт о расхождениях")
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, report)
+}
+
+func (h Handler) getDeletedProductsHandler(w http.ResponseWriter, r *http.Request) {
+	receptionIDParam := chi.URLParam(r, "receptionId")
+	receptionID, err := uuid.Parse(receptionIDParam)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Некорректный UUID приёмки", "receptionId", receptionIDParam, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Неверный формат идентификатора приёмки")
+		return
+	}
+
+	products, err := h.service.GetDeletedProducts(r.Context(), receptionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrReceptionNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Приёмка не найдена")
+		case errors.Is(err, apperrors.ErrReceptionAlreadyClosed):
+			writeErrorResponse(w, http.StatusBadRequest, "Удалённые товары доступны только для открытой приёмки")
+		default:
+			slog.ErrorContext(r.Context(), "Ошибка при получении удалённых товаров", "receptionId", receptionID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Не удалось получить удалённые товары")
+		}
+		return
+	}
+
+	sendListResponse(w, h.listEnvelope, products)
+}
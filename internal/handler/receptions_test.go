@@ -29,6 +29,8 @@ func TestCloseLastReceptionHandler(t *testing.T) {
 	tests := []struct {
 		name               string
 		pvzIDParam         string
+		query              string
+		acknowledge        bool
 		userRole           string
 		expectedStatusCode int
 		expectedResponse   models.Error
@@ -53,6 +55,27 @@ func TestCloseLastReceptionHandler(t *testing.T) {
 			mockServiceReturn:  reception,
 			mockServiceError:   nil,
 		},
+		{
+			name:               "Расхождения с манифестом без подтверждения",
+			pvzIDParam:         pvzID.String(),
+			userRole:           "employee",
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: models.Error{Message: "Приёмка расходится с манифестом: проверьте отчёт о расхождениях " +
+				"и повторите закрытие с acknowledgeDiscrepancies=true"},
+			mockServiceReturn: models.Reception{},
+			mockServiceError:  apperrors.ErrReceptionHasDiscrepancies,
+		},
+		{
+			name:               "Закрытие с подтверждением расхождений",
+			pvzIDParam:         pvzID.String(),
+			query:              "?acknowledgeDiscrepancies=true",
+			acknowledge:        true,
+			userRole:           "employee",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.Error{},
+			mockServiceReturn:  reception,
+			mockServiceError:   nil,
+		},
 		{
 			name:               "Reception not found for employee",
 			pvzIDParam:         pvzID.String(),
@@ -67,13 +90,14 @@ func TestCloseLastReceptionHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockService)
-			mockService.On("CloseLastReception", mock.Anything, pvzID).Return(tt.mockServiceReturn, tt.mockServiceError)
+			mockService.On("CloseLastReception", mock.Anything, pvzID, tt.acknowledge).
+				Return(tt.mockServiceReturn, tt.mockServiceError)
 
 			h := Handler{
 				service: mockService,
 			}
 
-			req := httptest.NewRequest(http.MethodPost, "/pvz/"+tt.pvzIDParam+"/close_last_reception", nil)
+			req := httptest.NewRequest(http.MethodPost, "/pvz/"+tt.pvzIDParam+"/close_last_reception"+tt.query, nil)
 			req.Header.Set("Authorization", "Bearer valid_token")
 			req = req.WithContext(context.WithValue(req.Context(), "userRole", tt.userRole))
 
@@ -143,9 +167,9 @@ func TestDeleteLastProductHandler(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			serviceMock := new(MockService)
 			if tt.mockServiceError != nil {
-				serviceMock.On("DeleteLastProductInReception", mock.Anything, mock.Anything).Return(tt.mockServiceError)
+				serviceMock.On("DeleteLastProductInReception", mock.Anything, mock.Anything, mock.Anything).Return(tt.mockServiceError)
 			} else {
-				serviceMock.On("DeleteLastProductInReception", mock.Anything, mock.Anything).Return(nil)
+				serviceMock.On("DeleteLastProductInReception", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			}
 
 			handler := Handler{
@@ -194,7 +218,7 @@ func TestAddProductToReceptionHandler(t *testing.T) {
 				"pvzId": "86a4c84c-9719-419c-8449-f03267a2c885",
 			},
 			mockService: func(m *MockService) {
-				m.On("AddProductToActiveReception", mock.Anything, "электроника", uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")).
+				m.On("AddProductToActiveReception", mock.Anything, models.AddProductRequest{Type: "электроника", PVZID: uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")}).
 					Return(models.Product{
 						ID:          uuid.New(),
 						Type:        "электроника",
@@ -204,6 +228,41 @@ func TestAddProductToReceptionHandler(t *testing.T) {
 			},
 			expectedStatus: http.StatusCreated,
 			expectedBody:   `"type":"электроника"`,
+		},
+		{
+			name: "Добавление повреждённого товара с комментарием",
+			requestBody: map[string]interface{}{
+				"type":      "обувь",
+				"pvzId":     "86a4c84c-9719-419c-8449-f03267a2c885",
+				"condition": "damaged",
+				"note":      "  порвана коробка  ",
+			},
+			mockService: func(m *MockService) {
+				m.On("AddProductToActiveReception", mock.Anything, models.AddProductRequest{
+					Type:      "обувь",
+					PVZID:     uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885"),
+					Condition: models.ProductConditionDamaged,
+					Note:      "порвана коробка",
+				}).Return(models.Product{
+					ID:        uuid.New(),
+					Type:      "обувь",
+					Condition: models.ProductConditionDamaged,
+					Note:      "порвана коробка",
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"condition":"damaged","note":"порвана коробка"`,
+		},
+		{
+			name: "Недопустимое состояние товара",
+			requestBody: map[string]interface{}{
+				"type":      "обувь",
+				"pvzId":     "86a4c84c-9719-419c-8449-f03267a2c885",
+				"condition": "broken",
+			},
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Недопустимое состояние товара"`,
 		}, {
 			name: "Ошибка при добавлении товара из-за отсутствия активной приёмки",
 			requestBody: map[string]interface{}{
@@ -211,7 +270,7 @@ func TestAddProductToReceptionHandler(t *testing.T) {
 				"pvzId": "86a4c84c-9719-419c-8449-f03267a2c885",
 			},
 			mockService: func(m *MockService) {
-				m.On("AddProductToActiveReception", mock.Anything, "одежда", uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")).
+				m.On("AddProductToActiveReception", mock.Anything, models.AddProductRequest{Type: "одежда", PVZID: uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")}).
 					Return(models.Product{}, apperrors.ErrNoActiveReception)
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -223,7 +282,7 @@ func TestAddProductToReceptionHandler(t *testing.T) {
 				"pvzId": "86a4c84c-9719-419c-8449-f03267a2c885",
 			},
 			mockService: func(m *MockService) {
-				m.On("AddProductToActiveReception", mock.Anything, "одежда", uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")).
+				m.On("AddProductToActiveReception", mock.Anything, models.AddProductRequest{Type: "одежда", PVZID: uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")}).
 					Return(models.Product{}, apperrors.ErrNoActiveReception)
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -243,7 +302,7 @@ func TestAddProductToReceptionHandler(t *testing.T) {
 				"pvzId": "86a4c84c-9719-419c-8449-f03267a2c885",
 			},
 			mockService: func(m *MockService) {
-				m.On("AddProductToActiveReception", mock.Anything, "обувь", uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")).
+				m.On("AddProductToActiveReception", mock.Anything, models.AddProductRequest{Type: "обувь", PVZID: uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")}).
 					Return(models.Product{}, errors.New("внутренняя ошибка сервера"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -303,7 +362,8 @@ func TestCreateReceptionHandler(t *testing.T) {
 				"pvzId": "86a4c84c-9719-419c-8449-f03267a2c885",
 			},
 			mockService: func(m *MockService) {
-				m.On("CreateReception", mock.Anything, uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")).
+				m.On("CreateReception", mock.Anything,
+					models.CreateReceptionRequest{PVZID: uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")}).
 					Return(models.Reception{
 						ID:       uuid.New(),
 						DateTime: time.Now(),
@@ -314,18 +374,64 @@ func TestCreateReceptionHandler(t *testing.T) {
 			expectedStatus: http.StatusCreated,
 			expectedBody:   `"status":"in_progress"`,
 		},
+		{
+			name: "ПВЗ временно закрыт",
+			requestBody: map[string]string{
+				"pvzId": "86a4c84c-9719-419c-8449-f03267a2c885",
+			},
+			mockService: func(m *MockService) {
+				m.On("CreateReception", mock.Anything,
+					models.CreateReceptionRequest{PVZID: uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")}).
+					Return(models.Reception{}, apperrors.ErrPVZNotActive)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"ПВЗ временно закрыт или выведен из эксплуатации"`,
+		},
 		{
 			name: "Ошибка сервиса при создании приёмки",
 			requestBody: map[string]string{
 				"pvzId": "86a4c84c-9719-419c-8449-f03267a2c885",
 			},
 			mockService: func(m *MockService) {
-				m.On("CreateReception", mock.Anything, uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")).
+				m.On("CreateReception", mock.Anything,
+					models.CreateReceptionRequest{PVZID: uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885")}).
 					Return(models.Reception{}, errors.New("ошибка сервиса"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `"message":"Внутренняя ошибка сервера"`,
 		},
+		{
+			name: "Создание приёмки с манифестом",
+			requestBody: `{"pvzId":"86a4c84c-9719-419c-8449-f03267a2c885",` +
+				`"manifest":[{"barcode":"4601234567890","type":"обувь"}]}`,
+			mockService: func(m *MockService) {
+				m.On("CreateReception", mock.Anything, models.CreateReceptionRequest{
+					PVZID:    uuid.MustParse("86a4c84c-9719-419c-8449-f03267a2c885"),
+					Manifest: []models.ManifestItem{{Barcode: "4601234567890", Type: "обувь"}},
+				}).Return(models.Reception{
+					Status:   "in_progress",
+					Manifest: []models.ManifestItem{{Barcode: "4601234567890", Type: "обувь"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"manifest":[{"barcode":"4601234567890","type":"обувь"}]`,
+		},
+		{
+			name: "Повторяющийся штрихкод в манифесте",
+			requestBody: `{"pvzId":"86a4c84c-9719-419c-8449-f03267a2c885",` +
+				`"manifest":[{"barcode":"4601234567890","type":"обувь"},{"barcode":"4601234567890","type":"одежда"}]}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Некорректный манифест: штрихкод \"4601234567890\" указан повторно"`,
+		},
+		{
+			name: "Недопустимый код получения в манифесте",
+			requestBody: `{"pvzId":"86a4c84c-9719-419c-8449-f03267a2c885",` +
+				`"manifest":[{"barcode":"4601234567890","type":"обувь","pickupCode":"12"}]}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Некорректный манифест: недопустимый код получения у штрихкода \"4601234567890\""`,
+		},
 		{
 			name:           "Некорректный JSON",
 			requestBody:    `{"invalid_json"`,
@@ -372,3 +478,403 @@ func TestCreateReceptionHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestForceCloseReceptionHandler(t *testing.T) {
+	receptionID := uuid.New()
+	moderatorID := uuid.New()
+	reason := "сотрудник забыл закрыть приёмку"
+
+	tests := []struct {
+		name           string
+		receptionParam string
+		requestBody    string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Успешное принудительное закрытие",
+			receptionParam: receptionID.String(),
+			requestBody:    `{"reason":"  ` + reason + `  "}`,
+			mockService: func(m *MockService) {
+				m.On("ForceCloseReception", mock.Anything, receptionID,
+					models.ForceCloseReceptionRequest{Reason: reason}, moderatorID).
+					Return(models.Reception{ID: receptionID, Status: models.ReceptionStatusClose, CloseReason: &reason}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"closeReason":"` + reason + `"`,
+		},
+		{
+			name:           "Отсутствует причина",
+			receptionParam: receptionID.String(),
+			requestBody:    `{"reason":"   "}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Причина закрытия обязательна и не должна превышать 500 символов"`,
+		},
+		{
+			name:           "Приёмка уже закрыта",
+			receptionParam: receptionID.String(),
+			requestBody:    `{"reason":"дубль","abandon":true}`,
+			mockService: func(m *MockService) {
+				m.On("ForceCloseReception", mock.Anything, receptionID,
+					models.ForceCloseReceptionRequest{Reason: "дубль", Abandon: true}, moderatorID).
+					Return(models.Reception{}, apperrors.ErrReceptionAlreadyClosed)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Приемка уже закрыта или не найдена"`,
+		},
+		{
+			name:           "Приёмка не найдена",
+			receptionParam: receptionID.String(),
+			requestBody:    `{"reason":"дубль"}`,
+			mockService: func(m *MockService) {
+				m.On("ForceCloseReception", mock.Anything, receptionID,
+					models.ForceCloseReceptionRequest{Reason: "дубль"}, moderatorID).
+					Return(models.Reception{}, apperrors.ErrReceptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Приёмка не найдена"`,
+		},
+		{
+			name:           "Некорректный UUID приёмки",
+			receptionParam: "invalid-uuid",
+			requestBody:    `{"reason":"дубль"}`,
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Неверный формат идентификатора приёмки"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Post("/receptions/{receptionId}/force_close", h.forceCloseReceptionHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/receptions/"+tt.receptionParam+"/force_close",
+				bytes.NewReader([]byte(tt.requestBody)))
+			req = req.WithContext(context.WithValue(req.Context(), "userID", moderatorID))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetStaleReceptionsHandler(t *testing.T) {
+	staleAt := time.Now()
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("GetStaleReceptions", mock.Anything, mock.Anything).Return([]models.Reception{
+			{ID: uuid.New(), Status: models.ReceptionStatusInProgress, StaleAt: &staleAt},
+		}, nil)
+
+		h := Handler{service: mockService}
+		rec := httptest.NewRecorder()
+		h.getStaleReceptionsHandler(rec, httptest.NewRequest(http.MethodGet, "/receptions/stale", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"staleAt"`)
+	})
+
+	t.Run("Ошибка сервиса", func(t *testing.T) {
+		mockService := new(MockService)
+		mockService.On("GetStaleReceptions", mock.Anything, mock.Anything).Return([]models.Reception(nil), errors.New("ошибка сервиса"))
+
+		h := Handler{service: mockService}
+		rec := httptest.NewRecorder()
+		h.getStaleReceptionsHandler(rec, httptest.NewRequest(http.MethodGet, "/receptions/stale", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"message":"Не удалось получить зависшие приёмки"`)
+	})
+
+	t.Run("Сотрудник видит только свои ПВЗ", func(t *testing.T) {
+		employeeID := uuid.New()
+		mockService := new(MockService)
+		mockService.On("GetStaleReceptions", mock.Anything, &employeeID).Return([]models.Reception{}, nil)
+
+		h := Handler{service: mockService}
+		req := httptest.NewRequest(http.MethodGet, "/receptions/stale", nil)
+		ctx := context.WithValue(req.Context(), "role", "employee")
+		ctx = context.WithValue(ctx, "userID", employeeID)
+		rec := httptest.NewRecorder()
+		h.getStaleReceptionsHandler(rec, req.WithContext(ctx))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestGetDiscrepancyReportHandler(t *testing.T) {
+	receptionID := uuid.New()
+
+	tests := []struct {
+		name           string
+		receptionParam string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Успешное получение отчёта",
+			receptionParam: receptionID.String(),
+			mockService: func(m *MockService) {
+				m.On("GetDiscrepancyReport", mock.Anything, receptionID, mock.Anything).Return(models.DiscrepancyReport{
+					ReceptionID: receptionID,
+					Missing:     []models.ManifestItem{{Barcode: "4601234567890", Type: "обувь"}},
+					Extra:       []models.ManifestItem{},
+					Mismatched:  []models.TypeMismatch{},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"missing":[{"barcode":"4601234567890","type":"обувь"}]`,
+		},
+		{
+			name:           "Отчёт не найден",
+			receptionParam: receptionID.String(),
+			mockService: func(m *MockService) {
+				m.On("GetDiscrepancyReport", mock.Anything, receptionID, mock.Anything).
+					Return(models.DiscrepancyReport{}, apperrors.ErrDiscrepancyReportNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Отчёт о расхождениях не найден"`,
+		},
+		{
+			name:           "Некорректный UUID приёмки",
+			receptionParam: "invalid-uuid",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Неверный формат идентификатора приёмки"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Get("/receptions/{receptionId}/discrepancies", h.getDiscrepancyReportHandler)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/receptions/"+tt.receptionParam+"/discrepancies", nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReopenReceptionHandler(t *testing.T) {
+	receptionID := uuid.New()
+	moderatorID := uuid.New()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		serviceErr     error
+		callsService   bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Успешное переоткрытие",
+			requestBody:    `{"reason":"закрыли до выгрузки последней коробки"}`,
+			callsService:   true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"in_progress"`,
+		},
+		{
+			name:           "Пустая причина",
+			requestBody:    `{"reason":""}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Причина переоткрытия обязательна и не должна превышать 500 символов"`,
+		},
+		{
+			name:           "Истекло время на переоткрытие",
+			requestBody:    `{"reason":"ошибка"}`,
+			serviceErr:     apperrors.ErrReopenWindowExpired,
+			callsService:   true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"message":"Время, в течение которого приёмку можно переоткрыть, истекло"`,
+		},
+		{
+			name:           "Есть более новая приёмка",
+			requestBody:    `{"reason":"ошибка"}`,
+			serviceErr:     apperrors.ErrNewerReceptionExists,
+			callsService:   true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"message":"В ПВЗ уже заведена более новая приёмка"`,
+		},
+		{
+			name:           "Приёмка не закрыта",
+			requestBody:    `{"reason":"ошибка"}`,
+			serviceErr:     apperrors.ErrReceptionNotClosed,
+			callsService:   true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Переоткрыть можно только закрытую приёмку"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			if tt.callsService {
+				var req models.ReopenReceptionRequest
+				assert.NoError(t, json.Unmarshal([]byte(tt.requestBody), &req))
+
+				reception := models.Reception{ID: receptionID, Status: models.ReceptionStatusInProgress}
+				if tt.serviceErr != nil {
+					reception = models.Reception{}
+				}
+				mockService.On("ReopenReception", mock.Anything, receptionID, req, moderatorID).
+					Return(reception, tt.serviceErr)
+			}
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Post("/receptions/{receptionId}/reopen", h.reopenReceptionHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/receptions/"+receptionID.String()+"/reopen",
+				bytes.NewReader([]byte(tt.requestBody)))
+			req = req.WithContext(context.WithValue(req.Context(), "userID", moderatorID))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetReceptionHandler(t *testing.T) {
+	receptionID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Повреждённые товары приёмки",
+			query: "?condition=damaged",
+			mockService: func(m *MockService) {
+				m.On("GetReception", mock.Anything, receptionID, models.ProductConditionDamaged, mock.Anything).
+					Return(models.ReceptionWithProducts{
+						Reception: models.Reception{ID: receptionID, Status: models.ReceptionStatusClose},
+						Products: []models.Product{
+							{ID: uuid.New(), Condition: models.ProductConditionDamaged, Note: "мокрая коробка"},
+						},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"condition":"damaged","note":"мокрая коробка"`,
+		},
+		{
+			name:           "Недопустимое состояние",
+			query:          "?condition=broken",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Недопустимое состояние товара"`,
+		},
+		{
+			name: "Приёмка не найдена",
+			mockService: func(m *MockService) {
+				m.On("GetReception", mock.Anything, receptionID, "", mock.Anything).
+					Return(models.ReceptionWithProducts{}, apperrors.ErrReceptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Приёмка не найдена"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Get("/receptions/{receptionId}", h.getReceptionHandler)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/receptions/"+receptionID.String()+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDeletedProductsHandler(t *testing.T) {
+	receptionID := uuid.New()
+
+	tests := []struct {
+		name           string
+		products       []models.DeletedProduct
+		serviceErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Удалённые товары открытой приёмки",
+			products: []models.DeletedProduct{{
+				Product:   models.Product{ID: uuid.New(), ReceptionID: receptionID, Type: "обувь"},
+				DeletedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+			}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"deletedAt":"2025-03-01T10:00:00Z"`,
+		},
+		{
+			name:           "Приёмка закрыта",
+			serviceErr:     apperrors.ErrReceptionAlreadyClosed,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Удалённые товары доступны только для открытой приёмки"`,
+		},
+		{
+			name:           "Приёмка не найдена",
+			serviceErr:     apperrors.ErrReceptionNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"message":"Приёмка не найдена"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			mockService.On("GetDeletedProducts", mock.Anything, receptionID).Return(tt.products, tt.serviceErr)
+
+			h := Handler{service: mockService}
+
+			router := chi.NewRouter()
+			router.Get("/receptions/{receptionId}/deleted_products", h.getDeletedProductsHandler)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+				"/receptions/"+receptionID.String()+"/deleted_products", nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
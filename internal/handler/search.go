@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"net/http"
+)
+
+func (h Handler) searchHandler(w http.ResponseWriter, r *http.Request) {
+	params, err := parseSearchParams(r)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Невалидные параметры поиска", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params.EmployeeID = employeeScope(r)
+
+	result, err := h.service.Search(r.Context(), params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Ошибка при поиске", "query", params.Query, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Не удалось выполнить поиск")
+		return
+	}
+
+	if h.listEnvelope {
+		sendJSONResponse(w, http.StatusOK, models.SearchResponse{
+			Items:  nonNil(result.Hits),
+			Total:  result.Total,
+			Facets: result.Facets,
+		})
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, result)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchHandler(t *testing.T) {
+	employeeID := uuid.New()
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		role           string
+		mockService    func(*MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Модератор ищет по всем ПВЗ",
+			query: "?q=Тверская&city=Москва",
+			role:  "moderator",
+			mockService: func(m *MockService) {
+				m.On("Search", mock.Anything, models.SearchParams{Query: "Тверская", City: "Москва", Limit: 20}).
+					Return(models.SearchResult{
+						Total: 1,
+						Hits: []models.SearchHit{
+							{Kind: models.SearchKindPVZ, ID: pvzID, PVZID: pvzID, Title: "Москва, Тверская, 1", City: "Москва"},
+						},
+						Facets: models.SearchFacets{Kind: map[string]int{models.SearchKindPVZ: 1}},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"title":"Москва, Тверская, 1"`,
+		},
+		{
+			name:  "Сотрудник ищет только по своим ПВЗ",
+			query: "?q=4600000000001&kind=product&limit=5",
+			role:  "employee",
+			mockService: func(m *MockService) {
+				m.On("Search", mock.Anything, models.SearchParams{
+					Query: "4600000000001", Kind: models.SearchKindProduct, Limit: 5, EmployeeID: &employeeID,
+				}).Return(models.SearchResult{Hits: []models.SearchHit{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"total":0`,
+		},
+		{
+			name:           "Слишком короткий запрос",
+			query:          "?q=а",
+			role:           "moderator",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `поисковый запрос должен содержать от 2 до 100 символов`,
+		},
+		{
+			name:           "Недопустимый тип результата",
+			query:          "?q=Казань&kind=user",
+			role:           "moderator",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `недопустимый тип результата`,
+		},
+		{
+			name:           "Недопустимый статус приёмки",
+			query:          "?q=Казань&receptionStatus=open",
+			role:           "moderator",
+			mockService:    func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `недопустимый статус приёмки`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			tt.mockService(mockService)
+
+			h := Handler{service: mockService}
+
+			req := httptest.NewRequest(http.MethodGet, "/search"+tt.query, nil)
+			ctx := context.WithValue(req.Context(), "role", tt.role)
+			ctx = context.WithValue(ctx, "userID", employeeID)
+			rec := httptest.NewRecorder()
+			h.searchHandler(rec, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDraining возвращается проверкой готовности после начала завершения сервера.
+var ErrDraining = errors.New("сервер завершает работу")
+
+// checkTimeout ограничивает время одной проверки, чтобы зависшая база
+// не задерживала ответ балансировщику.
+const checkTimeout = 2 * time.Second
+
+// Check — зависимость, без которой сервис не может обслуживать запросы.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Report — результат проверки готовности: общий статус и ошибки по зависимостям.
+type Report struct {
+	Ready  bool
+	Checks map[string]error
+}
+
+// Readiness отвечает на вопрос, можно ли направлять на сервис трафик.
+type Readiness struct {
+	checks   []Check
+	draining atomic.Bool
+}
+
+func NewReadiness(checks ...Check) *Readiness {
+	return &Readiness{checks: checks}
+}
+
+// Drain переводит сервис в состояние «не готов». Вызывается перед
+// завершением сервера, чтобы балансировщик успел снять с него трафик.
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+// Check выполняет все проверки. После Drain сервис не готов независимо от их результата.
+func (r *Readiness) Check(ctx context.Context) Report {
+	report := Report{Ready: true, Checks: make(map[string]error, len(r.checks))}
+
+	if r.draining.Load() {
+		report.Ready = false
+		report.Checks["shutdown"] = ErrDraining
+	}
+
+	for _, check := range r.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := check.Run(checkCtx)
+		cancel()
+
+		report.Checks[check.Name] = err
+		if err != nil {
+			report.Ready = false
+		}
+	}
+
+	return report
+}
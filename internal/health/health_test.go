@@ -0,0 +1,72 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadinessCheck(t *testing.T) {
+	ok := Check{Name: "postgres", Run: func(context.Context) error { return nil }}
+	failed := Check{Name: "migrations", Run: func(context.Context) error { return errors.New("есть невыполненные миграции") }}
+
+	tests := []struct {
+		name      string
+		checks    []Check
+		drain     bool
+		wantReady bool
+		wantFail  []string
+	}{
+		{
+			name:      "Все проверки пройдены",
+			checks:    []Check{ok},
+			wantReady: true,
+		},
+		{
+			name:      "Одна проверка не пройдена",
+			checks:    []Check{ok, failed},
+			wantReady: false,
+			wantFail:  []string{"migrations"},
+		},
+		{
+			name:      "Сервер завершает работу",
+			checks:    []Check{ok},
+			drain:     true,
+			wantReady: false,
+			wantFail:  []string{"shutdown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readiness := NewReadiness(tt.checks...)
+			if tt.drain {
+				readiness.Drain()
+			}
+
+			report := readiness.Check(context.Background())
+
+			assert.Equal(t, tt.wantReady, report.Ready)
+			var failedNames []string
+			for name, err := range report.Checks {
+				if err != nil {
+					failedNames = append(failedNames, name)
+				}
+			}
+			assert.ElementsMatch(t, tt.wantFail, failedNames)
+		})
+	}
+}
+
+func TestReadinessCheckTimeout(t *testing.T) {
+	slow := Check{Name: "postgres", Run: func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "у проверки должен быть таймаут")
+		return nil
+	}}
+
+	report := NewReadiness(slow).Check(context.Background())
+
+	assert.True(t, report.Ready)
+}
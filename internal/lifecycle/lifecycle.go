@@ -0,0 +1,161 @@
+// Package lifecycle запускает компоненты сервиса по порядку и останавливает
+// их в обратном порядке, давая каждому ограниченное время на завершение.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultStopTimeout используется для компонентов без собственного таймаута.
+const defaultStopTimeout = 10 * time.Second
+
+// Component — часть сервиса с управляемым временем жизни. Start не должен
+// блокироваться: долгую работу он запускает в фоне. Оба поля необязательны.
+type Component struct {
+	Name        string
+	Start       func(ctx context.Context) error
+	Stop        func(ctx context.Context) error
+	StopTimeout time.Duration
+}
+
+type Manager struct {
+	components []Component
+	onShutdown []func()
+	drainDelay time.Duration
+	failures   chan error
+}
+
+// New создаёт менеджер. drainDelay — пауза между началом завершения и
+// остановкой компонентов, за которую балансировщик снимает трафик.
+func New(drainDelay time.Duration) *Manager {
+	return &Manager{drainDelay: drainDelay, failures: make(chan error, 1)}
+}
+
+// Add добавляет компонент. Компоненты запускаются в порядке добавления,
+// а останавливаются в обратном: сначала приём запросов, последней — база.
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+}
+
+// OnShutdown регистрирует функцию, вызываемую в самом начале завершения,
+// до паузы на снятие трафика. Так сервис сообщает о завершении через /readyz.
+func (m *Manager) OnShutdown(fn func()) {
+	m.onShutdown = append(m.onShutdown, fn)
+}
+
+// Fail сообщает о непредвиденной остановке компонента и запускает завершение.
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failures <- err:
+	default:
+	}
+}
+
+// Run запускает компоненты и ждёт отмены ctx или сбоя компонента, после чего
+// останавливает их. Компоненты получают контекст, который не отменяется
+// вместе с ctx: фоновая работа завершается через Stop, а не обрывается сигналом.
+func (m *Manager) Run(ctx context.Context) error {
+	base := context.WithoutCancel(ctx)
+
+	for i, c := range m.components {
+		if c.Start == nil {
+			continue
+		}
+		if err := c.Start(base); err != nil {
+			return errors.Join(fmt.Errorf("не удалось запустить %s: %w", c.Name, err), m.stop(i))
+		}
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("Получен сигнал завершения")
+	case runErr = <-m.failures:
+		slog.Error("Компонент остановился с ошибкой, завершаем сервис", "error", runErr)
+	}
+
+	for _, fn := range m.onShutdown {
+		fn()
+	}
+	if m.drainDelay > 0 {
+		slog.Info("Ожидаем снятия трафика", "delay", m.drainDelay)
+		time.Sleep(m.drainDelay)
+	}
+
+	return errors.Join(runErr, m.stop(len(m.components)))
+}
+
+// stop останавливает первые n компонентов в обратном порядке.
+func (m *Manager) stop(n int) error {
+	var errs []error
+	for i := n - 1; i >= 0; i-- {
+		c := m.components[i]
+		if c.Stop == nil {
+			continue
+		}
+		if err := stopComponent(c); err != nil {
+			slog.Error("Ошибка при остановке компонента", "component", c.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopComponent ограничивает остановку таймаутом, даже если Stop не следит за
+// контекстом, как pgxpool.Pool.Close.
+func stopComponent(c Component) error {
+	timeout := c.StopTimeout
+	if timeout <= 0 {
+		timeout = defaultStopTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			slog.Info("Компонент остановлен", "component", c.Name, "duration", time.Since(start))
+		}
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("не остановлен за %s", timeout)
+	}
+}
+
+// HTTPServer управляет HTTP-сервером: Start занимает порт сразу, чтобы ошибка
+// адреса была видна при запуске, а Stop дожидается завершения текущих
+// запросов. Ошибка обслуживания передаётся в fail.
+func HTTPServer(srv *http.Server, stopTimeout time.Duration, fail func(error)) Component {
+	return Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+
+			slog.Info("Запуск сервера", "addr", ln.Addr().String())
+			go func() {
+				if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+					fail(fmt.Errorf("http: %w", err))
+				}
+			}()
+			return nil
+		},
+		Stop:        srv.Shutdown,
+		StopTimeout: stopTimeout,
+	}
+}
@@ -0,0 +1,170 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, event)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+func component(name string, ev *events, startErr error) Component {
+	return Component{
+		Name: name,
+		Start: func(context.Context) error {
+			ev.add("start " + name)
+			return startErr
+		},
+		Stop: func(context.Context) error {
+			ev.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestManagerRun(t *testing.T) {
+	startErr := errors.New("порт занят")
+	failure := errors.New("сервер упал")
+
+	tests := []struct {
+		name       string
+		components func(ev *events) []Component
+		trigger    func(m *Manager, cancel context.CancelFunc)
+		wantEvents []string
+		wantErr    error
+	}{
+		{
+			name: "Остановка в обратном порядке по сигналу",
+			components: func(ev *events) []Component {
+				return []Component{component("postgres", ev, nil), component("scheduler", ev, nil), component("http", ev, nil)}
+			},
+			trigger: func(_ *Manager, cancel context.CancelFunc) { cancel() },
+			wantEvents: []string{
+				"start postgres", "start scheduler", "start http",
+				"shutdown",
+				"stop http", "stop scheduler", "stop postgres",
+			},
+		},
+		{
+			name: "Сбой компонента запускает завершение",
+			components: func(ev *events) []Component {
+				return []Component{component("postgres", ev, nil), component("http", ev, nil)}
+			},
+			trigger: func(m *Manager, _ context.CancelFunc) { m.Fail(failure) },
+			wantEvents: []string{
+				"start postgres", "start http",
+				"shutdown",
+				"stop http", "stop postgres",
+			},
+			wantErr: failure,
+		},
+		{
+			name: "Ошибка запуска останавливает уже запущенные",
+			components: func(ev *events) []Component {
+				return []Component{component("postgres", ev, nil), component("http", ev, startErr), component("extra", ev, nil)}
+			},
+			wantEvents: []string{"start postgres", "start http", "stop postgres"},
+			wantErr:    startErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := &events{}
+			m := New(0)
+			for _, c := range tt.components(ev) {
+				m.Add(c)
+			}
+			m.OnShutdown(func() { ev.add("shutdown") })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- m.Run(ctx) }()
+
+			if tt.trigger != nil {
+				require.Eventually(t, func() bool { return len(ev.get()) == len(tt.components(&events{})) },
+					time.Second, time.Millisecond)
+				tt.trigger(m, cancel)
+			}
+
+			select {
+			case err := <-done:
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				} else {
+					assert.NoError(t, err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Run не завершился")
+			}
+			assert.Equal(t, tt.wantEvents, ev.get())
+		})
+	}
+}
+
+func TestManagerStopTimeout(t *testing.T) {
+	var stopped bool
+	m := New(0)
+	m.Add(Component{
+		Name:        "stuck",
+		Stop:        func(context.Context) error { select {} },
+		StopTimeout: 20 * time.Millisecond,
+	})
+	m.Add(Component{
+		Name: "http",
+		Stop: func(context.Context) error {
+			stopped = true
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.Run(ctx)
+
+	assert.ErrorContains(t, err, "stuck: не остановлен за 20ms")
+	assert.True(t, stopped, "зависший компонент не мешает остановить остальные")
+}
+
+func TestManagerStartContextOutlivesSignal(t *testing.T) {
+	var startCtx context.Context
+	m := New(0)
+	m.Add(Component{
+		Name: "scheduler",
+		Start: func(ctx context.Context) error {
+			startCtx = ctx
+			return nil
+		},
+		Stop: func(context.Context) error {
+			assert.NoError(t, startCtx.Err(), "фоновая работа не обрывается сигналом до Stop")
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, m.Run(ctx))
+}
@@ -0,0 +1,82 @@
+// Package logger настраивает общий структурированный лог сервиса на основе
+// log/slog: формат, уровень, поля запроса из контекста и скрытие секретов.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"github.com/kstsm/pvz-service/config"
+	"github.com/kstsm/pvz-service/internal/requestid"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// New создаёт логгер с форматом и уровнем из cfg, который пишет в w.
+func New(w io.Writer, cfg config.Log) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("некорректный уровень логирования %q: %w", cfg.Level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: level, AddSource: level == slog.LevelDebug, ReplaceAttr: redactAttr}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("некорректный формат логирования %q", cfg.Format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Setup делает логгер из cfg логгером по умолчанию. Логи пишутся в stderr,
+// чтобы не смешиваться с выводом команд вроде token mint.
+func Setup(cfg config.Log) error {
+	log, err := New(os.Stderr, cfg)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(log)
+	return nil
+}
+
+// Fatal пишет ошибку в лог и завершает процесс.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// contextHandler добавляет к записи идентификаторы запроса и трассировки из
+// контекста, переданного в slog.InfoContext и подобные функции.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id, ok := requestid.FromContext(ctx); ok {
+		record.AddAttrs(slog.String("requestId", id))
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record.AddAttrs(
+			slog.String("traceId", spanCtx.TraceID().String()),
+			slog.String("spanId", spanCtx.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "***"
+
+// sensitiveKeys — части имён полей, значения которых не попадают в лог целиком.
+var sensitiveKeys = []string{"password", "token", "secret", "authorization"}
+
+var (
+	bearerPattern = regexp.MustCompile(`(?i)bearer\s+\S+`)
+	jwtPattern    = regexp.MustCompile(`eyJ[\w-]*\.[\w-]+\.[\w-]*`)
+)
+
+// redactAttr скрывает значения полей с секретами по имени и вычищает токены,
+// случайно попавшие в строки и тексты ошибок.
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, redactString(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, redactString(err.Error()))
+		}
+	}
+
+	return attr
+}
+
+func redactString(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+	return jwtPattern.ReplaceAllString(s, redacted)
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileMailer сохраняет каждое письмо файлом .eml в каталог, откуда его можно
+// открыть почтовым клиентом.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог писем %q: %w", dir, err)
+	}
+
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	file, err := os.CreateTemp(m.dir, now.UTC().Format("20060102T150405")+"-*.eml")
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл письма: %w", err)
+	}
+
+	if _, err = file.WriteString(formatMessage(m.from, msg, now)); err != nil {
+		file.Close()
+		return fmt.Errorf("не удалось записать письмо %s: %w", file.Name(), err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("не удалось записать письмо %s: %w", file.Name(), err)
+	}
+
+	return nil
+}
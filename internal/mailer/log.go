@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogMailer пишет в лог только получателя и тему письма, без текста: в тексте
+// коды подтверждения и сброса пароля. Подходит для локального запуска, когда
+// письма читать не нужно; чтобы прочитать их, есть FileMailer.
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "Письмо", "from", m.from, "to", msg.To, "subject", msg.Subject)
+	return nil
+}
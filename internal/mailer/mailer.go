@@ -0,0 +1,53 @@
+// Package mailer отправляет письма пользователям: коды подтверждения email
+// и сброса пароля.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"github.com/kstsm/pvz-service/config"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Message — письмо одному получателю.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer доставляет письма.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создаёт отправителя писем по конфигурации.
+func New(cfg config.Mail) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	case "log":
+		return NewLogMailer(cfg.From), nil
+	case "file":
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	default:
+		return nil, fmt.Errorf("неизвестный способ отправки писем %q", cfg.Driver)
+	}
+}
+
+// formatMessage собирает письмо в формате RFC 5322 с телом в UTF-8.
+func formatMessage(from string, msg Message, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return b.String()
+}
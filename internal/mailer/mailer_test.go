@@ -0,0 +1,84 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"github.com/kstsm/pvz-service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "no-reply@example.com")
+	require.NoError(t, err)
+
+	err = m.Send(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Подтверждение email",
+		Body:    "Код: abc\nДействует сутки.",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: no-reply@example.com\r\n")
+	assert.Contains(t, string(content), "To: user@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: =?utf-8?q?")
+	assert.Contains(t, string(content), "\r\n\r\nКод: abc\r\nДействует сутки.")
+}
+
+func TestLogMailerOmitsBody(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	err := NewLogMailer("no-reply@example.com").Send(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Сброс пароля",
+		Body:    "Код: secret-reset-code",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "user@example.com")
+	assert.NotContains(t, buf.String(), "secret-reset-code")
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Mail
+		expected Mailer
+		wantErr  bool
+	}{
+		{name: "В лог", cfg: config.Mail{Driver: "log", From: "a@b"}, expected: &LogMailer{from: "a@b"}},
+		{
+			name:     "Через почтовый сервер",
+			cfg:      config.Mail{Driver: "smtp", From: "a@b", SMTPAddr: "smtp.example.com:587"},
+			expected: &SMTPMailer{addr: "smtp.example.com:587", from: "a@b"},
+		},
+		{name: "Адрес сервера без порта", cfg: config.Mail{Driver: "smtp", SMTPAddr: "smtp.example.com"}, wantErr: true},
+		{name: "Неизвестный способ", cfg: config.Mail{Driver: "sendmail"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, m)
+		})
+	}
+}
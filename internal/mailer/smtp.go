@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer отправляет письма через почтовый сервер. Если задан логин,
+// используется AUTH PLAIN; STARTTLS включается, когда сервер его предлагает.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(addr, username, password, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный адрес почтового сервера %q: %w", addr, err)
+	}
+
+	m := &SMTPMailer{addr: addr, from: from}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data := formatMessage(m.from, msg, time.Now())
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(data)); err != nil {
+		return fmt.Errorf("не удалось отправить письмо на %s: %w", msg.To, err)
+	}
+
+	return nil
+}
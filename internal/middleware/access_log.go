@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// accessEntry собирает сведения о запросе, которые становятся известны глубже
+// по цепочке обработчиков, например пользователя после AuthMiddleware.
+type accessEntry struct {
+	userID uuid.UUID
+	role   string
+}
+
+// setAccessUser запоминает пользователя запроса для журнала доступа.
+func setAccessUser(ctx context.Context, userID uuid.UUID, role string) {
+	if entry, ok := ctx.Value("accessEntry").(*accessEntry); ok {
+		entry.userID = userID
+		entry.role = role
+	}
+}
+
+// AccessLog пишет в лог каждый обработанный запрос: метод, маршрут, код
+// ответа, длительность и пользователя. Ответы 5xx пишутся с уровнем error.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if probePaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		entry := &accessEntry{}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), "accessEntry", entry)))
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", routePattern(r)),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Duration("latency", time.Since(start)),
+		}
+		if entry.role != "" {
+			attrs = append(attrs, slog.String("role", entry.role))
+		}
+		if entry.userID != uuid.Nil {
+			attrs = append(attrs, slog.String("userId", entry.userID.String()))
+		}
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(r.Context(), level, "Запрос обработан", attrs...)
+	})
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/requestid"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "Без заголовка", incoming: "", wantSame: false},
+		{name: "Корректный заголовок", incoming: "lb-7f3a9c", wantSame: true},
+		{name: "Заголовок с пробелами", incoming: "bad id", wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = requestid.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.NotEmpty(t, got)
+			assert.Equal(t, got, rec.Header().Get(requestid.Header))
+			assert.Equal(t, tt.wantSame, got == tt.incoming)
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		path      string
+		auth      bool
+		status    int
+		wantLog   bool
+		wantLevel string
+	}{
+		{name: "Запрос пользователя", path: "/pvz/1", auth: true, status: http.StatusCreated, wantLog: true, wantLevel: "INFO"},
+		{name: "Ошибка сервера", path: "/pvz/1", status: http.StatusInternalServerError, wantLog: true, wantLevel: "ERROR"},
+		{name: "Проверка состояния не пишется", path: "/healthz", status: http.StatusOK, wantLog: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLog(t)
+
+			validate := func(context.Context, string) (models.TokenClaims, error) {
+				return models.TokenClaims{UserID: userID, Role: "employee"}, nil
+			}
+			handle := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte("{}"))
+			}
+
+			r := chi.NewRouter()
+			r.Use(RequestID)
+			r.Use(AccessLog)
+			r.Get("/healthz", handle)
+			r.Group(func(r chi.Router) {
+				if tt.auth {
+					r.Use(AuthMiddleware(validate))
+				}
+				r.Get("/pvz/{pvzId}", handle)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer token")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !tt.wantLog {
+				assert.Empty(t, buf.String())
+				return
+			}
+
+			var line map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+			assert.Equal(t, tt.wantLevel, line["level"])
+			assert.Equal(t, http.MethodGet, line["method"])
+			assert.Equal(t, "/pvz/{pvzId}", line["route"])
+			assert.EqualValues(t, tt.status, line["status"])
+			assert.EqualValues(t, 2, line["bytes"])
+			assert.Contains(t, line, "latency")
+			if tt.auth {
+				assert.Equal(t, userID.String(), line["userId"])
+				assert.Equal(t, "employee", line["role"])
+			} else {
+				assert.NotContains(t, line, "userId")
+			}
+		})
+	}
+}
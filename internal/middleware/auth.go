@@ -3,12 +3,15 @@ package middleware
 import (
 	"context"
 	"encoding/json"
-	"github.com/gookit/slog"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
 	"net/http"
 	"strings"
 )
 
-func AuthMiddleware(validateToken func(string) (string, error)) func(http.Handler) http.Handler {
+func AuthMiddleware(validateToken func(context.Context, string) (models.TokenClaims, error)) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token := ExtractToken(r)
@@ -17,13 +20,17 @@ func AuthMiddleware(validateToken func(string) (string, error)) func(http.Handle
 				return
 			}
 
-			role, err := validateToken(token)
+			claims, err := validateToken(r.Context(), token)
 			if err != nil {
 				sendJSONError(w, http.StatusUnauthorized, "Неверный или просроченный токен")
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "role", role)
+			setAccessUser(r.Context(), claims.UserID, claims.Role)
+
+			ctx := context.WithValue(r.Context(), "role", claims.Role)
+			ctx = context.WithValue(ctx, "userID", claims.UserID)
+			ctx = tenant.WithID(ctx, claims.TenantID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -51,15 +58,25 @@ func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
 	}
 }
 
+// UserIDFromContext возвращает идентификатор пользователя, положенный в контекст AuthMiddleware.
+// Для токенов без user_id возвращается false.
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Value("userID").(uuid.UUID)
+	if !ok || userID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
 func ExtractToken(r *http.Request) string {
 	bearerToken := r.Header.Get("Authorization")
 	if bearerToken == "" {
-		slog.Warn("Заголовок Authorization отсутствует", "path", r.URL.Path)
+		slog.WarnContext(r.Context(), "Заголовок Authorization отсутствует", "path", r.URL.Path)
 		return ""
 	}
 	parts := strings.Split(bearerToken, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		slog.Warn("Некорректный формат токена", "path", r.URL.Path, "token", bearerToken)
+		slog.WarnContext(r.Context(), "Некорректный формат токена", "path", r.URL.Path)
 		return ""
 	}
 
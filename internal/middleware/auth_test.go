@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/models"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -14,7 +16,7 @@ func TestAuthMiddleware(t *testing.T) {
 	tests := []struct {
 		name           string
 		tokenHeader    string
-		validateToken  func(string) (string, error)
+		validateToken  func(context.Context, string) (models.TokenClaims, error)
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -28,8 +30,8 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:        "Неверный токен",
 			tokenHeader: "Bearer invalid-token",
-			validateToken: func(token string) (string, error) {
-				return "", assert.AnError
+			validateToken: func(_ context.Context, token string) (models.TokenClaims, error) {
+				return models.TokenClaims{}, assert.AnError
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   `{"errors":"Неверный или просроченный токен"}`,
@@ -37,8 +39,8 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:        "Валидный токен",
 			tokenHeader: "Bearer valid-token",
-			validateToken: func(token string) (string, error) {
-				return "admin", nil
+			validateToken: func(_ context.Context, token string) (models.TokenClaims, error) {
+				return models.TokenClaims{Role: "admin"}, nil
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   "ok",
@@ -75,6 +77,37 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 }
 
+func TestAuthMiddlewarePutsClaimsIntoContext(t *testing.T) {
+	userID := uuid.New()
+	validateToken := func(_ context.Context, token string) (models.TokenClaims, error) {
+		return models.TokenClaims{UserID: userID, Role: "client"}, nil
+	}
+
+	var gotRole any
+	var gotUserID uuid.UUID
+	var gotOK bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRole = r.Context().Value("role")
+		gotUserID, gotOK = UserIDFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	AuthMiddleware(validateToken)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "client", gotRole)
+	assert.True(t, gotOK)
+	assert.Equal(t, userID, gotUserID)
+}
+
+func TestUserIDFromContext(t *testing.T) {
+	_, ok := UserIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = UserIDFromContext(context.WithValue(context.Background(), "userID", uuid.Nil))
+	assert.False(t, ok)
+}
+
 func TestRequireRole(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/kstsm/pvz-service/internal/repository"
+	"net/http"
+	"strconv"
+)
+
+// ReadYourWritesHeader — заголовок, которым клиент просит читать из основной
+// базы, а не из реплики, например сразу после собственной записи.
+const ReadYourWritesHeader = "X-Read-Your-Writes"
+
+func ReadYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if primary, _ := strconv.ParseBool(r.Header.Get(ReadYourWritesHeader)); primary {
+			r = r.WithContext(repository.ReadFromPrimary(r.Context()))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantPrimary bool
+	}{
+		{name: "Без заголовка", header: "", wantPrimary: false},
+		{name: "Заголовок true", header: "true", wantPrimary: true},
+		{name: "Заголовок 1", header: "1", wantPrimary: true},
+		{name: "Заголовок false", header: "false", wantPrimary: false},
+		{name: "Некорректный заголовок", header: "yes", wantPrimary: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var primary bool
+			handler := ReadYourWrites(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				primary, _ = r.Context().Value("readFromPrimary").(bool)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+			if tt.header != "" {
+				req.Header.Set(ReadYourWritesHeader, tt.header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantPrimary, primary)
+		})
+	}
+}
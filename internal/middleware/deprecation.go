@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Deprecated помечает ответы устаревшей версии API заголовками Deprecation
+// (RFC 9745) и Link со ссылкой на тот же ресурс в successor: путь запроса
+// без prefix дописывается к successor.
+func Deprecated(prefix, successor string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`,
+				successor, strings.TrimPrefix(r.URL.Path, prefix)))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
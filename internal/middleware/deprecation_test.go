@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeprecated(t *testing.T) {
+	tests := []struct {
+		name         string
+		prefix       string
+		path         string
+		expectedLink string
+	}{
+		{
+			name:         "Версия в пути",
+			prefix:       "/v1",
+			path:         "/v1/pvz/nearby",
+			expectedLink: `</v2/pvz/nearby>; rel="successor-version"`,
+		},
+		{
+			name:         "Путь без версии",
+			path:         "/login",
+			expectedLink: `</v2/login>; rel="successor-version"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Deprecated(tt.prefix, "/v2")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+			assert.Equal(t, tt.expectedLink, rec.Header().Get("Link"))
+		})
+	}
+}
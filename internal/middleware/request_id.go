@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/kstsm/pvz-service/internal/requestid"
+	"net/http"
+)
+
+// RequestID присваивает запросу идентификатор и возвращает его в заголовке
+// X-Request-ID. Корректный идентификатор из входящего заголовка сохраняется,
+// чтобы запрос можно было проследить от балансировщика до базы.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithID(r.Context(), id)))
+	})
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/go-chi/chi"
+	"net/http"
+)
+
+// probePaths — служебные маршруты, которые постоянно опрашивают балансировщик
+// и Prometheus. Они не трассируются и не пишутся в журнал доступа.
+var probePaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// statusRecorder запоминает код ответа и размер тела, которые записал обработчик.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// routePattern возвращает шаблон маршрута chi, например /pvz/{pvzId}. Шаблон
+// известен только после того, как запрос прошёл маршрутизацию.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
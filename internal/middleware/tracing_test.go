@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		status      int
+		wantSpan    bool
+		wantName    string
+		wantStatus  codes.Code
+	}{
+		{
+			name:       "Спан называется по шаблону маршрута",
+			path:       "/pvz/6f0c0a1e-9a1b-4a57-9c3e-2c6a5d0b8f11",
+			status:     http.StatusOK,
+			wantSpan:   true,
+			wantName:   "GET /pvz/{pvzId}",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:        "Трассировка продолжается из traceparent",
+			path:        "/pvz/6f0c0a1e-9a1b-4a57-9c3e-2c6a5d0b8f11",
+			traceparent: traceparent,
+			status:      http.StatusInternalServerError,
+			wantSpan:    true,
+			wantName:    "GET /pvz/{pvzId}",
+			wantStatus:  codes.Error,
+		},
+		{
+			name:     "Проверки состояния не трассируются",
+			path:     "/healthz",
+			status:   http.StatusOK,
+			wantSpan: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Reset()
+
+			var handlerSpan trace.SpanContext
+			r := chi.NewRouter()
+			r.Use(Tracing)
+			handle := func(w http.ResponseWriter, r *http.Request) {
+				handlerSpan = trace.SpanContextFromContext(r.Context())
+				w.WriteHeader(tt.status)
+			}
+			r.Get("/pvz/{pvzId}", handle)
+			r.Get("/healthz", handle)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			if !tt.wantSpan {
+				assert.Empty(t, spans)
+				return
+			}
+
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, tt.wantName, span.Name())
+			assert.Equal(t, tt.wantStatus, span.Status().Code)
+			assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", tt.status))
+			assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID(), "обработчик получает контекст спана")
+			if tt.traceparent != "" {
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+				assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package notifier
+
+import (
+	"context"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+)
+
+// Notifier доставляет доменные события внешним потребителям.
+type Notifier interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+// LogNotifier пишет события в лог. Используется, пока не подключён брокер сообщений.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Publish(ctx context.Context, event models.Event) error {
+	slog.Info("Событие", "type", event.Type, "occurredAt", event.OccurredAt, "payload", event.Payload)
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+)
+
+func scanAttachment(row pgx.Row, attachment *models.ProductAttachment) error {
+	return row.Scan(
+		&attachment.ID, &attachment.ProductID, &attachment.StorageKey, &attachment.FileName,
+		&attachment.ContentType, &attachment.Size, &attachment.UploadedBy, &attachment.UploadedAt,
+	)
+}
+
+// AddProductAttachments записывает метаданные вложений одной транзакцией:
+// сохраняются либо все вложения запроса, либо ни одного.
+func (r Repository) AddProductAttachments(ctx context.Context, attachments []models.ProductAttachment) ([]models.ProductAttachment, error) {
+	var saved []models.ProductAttachment
+	err := r.inTx(ctx, "addProductAttachments", func(ctx context.Context) (err error) {
+		saved, err = r.addProductAttachments(ctx, attachments)
+		return err
+	})
+
+	return saved, err
+}
+
+func (r Repository) addProductAttachments(ctx context.Context, attachments []models.ProductAttachment) ([]models.ProductAttachment, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	saved := make([]models.ProductAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		err = tx.QueryRow(ctx, queryInsertProductAttachment, attachment.ID, attachment.ProductID, attachment.StorageKey,
+			attachment.FileName, attachment.ContentType, attachment.Size, attachment.UploadedBy).Scan(&attachment.UploadedAt)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось сохранить вложение товара с ID %v: %w", attachment.ProductID, err)
+		}
+		saved = append(saved, attachment)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return saved, nil
+}
+
+func (r Repository) GetProductAttachments(ctx context.Context, productID uuid.UUID) ([]models.ProductAttachment, error) {
+	rows, err := r.reader(ctx).Query(ctx, queryGetProductAttachments, productID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вложений товара с ID %v: %w", productID, err)
+	}
+	defer rows.Close()
+
+	attachments := []models.ProductAttachment{}
+	for rows.Next() {
+		var attachment models.ProductAttachment
+		if err = scanAttachment(rows, &attachment); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении вложений товара с ID %v: %w", productID, err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r Repository) GetProductAttachment(ctx context.Context, productID, attachmentID uuid.UUID) (models.ProductAttachment, error) {
+	var attachment models.ProductAttachment
+	err := scanAttachment(r.reader(ctx).QueryRow(ctx, queryGetProductAttachment, productID, attachmentID), &attachment)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ProductAttachment{}, apperrors.ErrAttachmentNotFound
+		}
+		return models.ProductAttachment{}, fmt.Errorf("ошибка при получении вложения с ID %v: %w", attachmentID, err)
+	}
+
+	return attachment, nil
+}
@@ -18,7 +18,7 @@ func (r Repository) CreateUser(ctx context.Context, user models.UserRegisterReq)
 	err := r.conn.QueryRow(ctx, queryCreateUser, user.Email, user.Password, user.Role).Scan(&id)
 	if err != nil {
 		if errors.As(err, &pgError) {
-			if pgError.Code == "23505" && pgError.ConstraintName == "users_email_key" {
+			if pgError.Code == "23505" && pgError.ConstraintName == "users_tenant_id_email_key" {
 				return uuid.Nil, apperrors.ErrEmailAlreadyExists
 			}
 			return uuid.Nil, fmt.Errorf("не удалось зарегистрировать пользователя: %w", err)
@@ -27,14 +27,131 @@ func (r Repository) CreateUser(ctx context.Context, user models.UserRegisterReq)
 	return id, nil
 }
 
-func (r Repository) GetRoleByEmail(ctx context.Context, email string) (string, string, error) {
-	var hashedPassword, userRole string
-	err := r.conn.QueryRow(ctx, queryGetRoleByEmail, email).Scan(&hashedPassword, &userRole)
+// dummyUserPassword — пароль тестовых пользователей. Это не bcrypt-хеш,
+// поэтому войти с ним по паролю нельзя.
+const dummyUserPassword = "!dummy"
+
+// EnsureDummyUser возвращает тестового пользователя с email и ролью role,
+// создавая его при первом обращении.
+func (r Repository) EnsureDummyUser(ctx context.Context, email, role string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.conn.QueryRow(ctx, queryEnsureDummyUser, email, dummyUserPassword, role).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, apperrors.ErrEmailAlreadyExists
+		}
+		return uuid.Nil, fmt.Errorf("не удалось получить тестового пользователя %s: %w", email, err)
+	}
+
+	return id, nil
+}
+
+func (r Repository) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	err := r.conn.QueryRow(ctx, queryGetUserByEmail, email).Scan(&user.ID, &user.Email, &user.Password, &user.Role, &user.EmailVerifiedAt, &user.PasswordChangedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, apperrors.ErrEmailNotFound
+		}
+		return models.User{}, fmt.Errorf("ошибка при получении данных пользователя по email: %w", err)
+	}
+	return user, nil
+}
+
+func (r Repository) GetUserByID(ctx context.Context, userID uuid.UUID) (models.User, error) {
+	var user models.User
+	err := r.conn.QueryRow(ctx, queryGetUserByID, userID).Scan(&user.ID, &user.Email, &user.Password, &user.Role, &user.EmailVerifiedAt, &user.PasswordChangedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, apperrors.ErrUserNotFound
+		}
+		return models.User{}, fmt.Errorf("ошибка при получении пользователя с ID %v: %w", userID, err)
+	}
+	return user, nil
+}
+
+func (r Repository) UpdateUserPassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	tag, err := r.conn.Exec(ctx, queryUpdateUserPassword, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить пароль пользователя с ID %v: %w", userID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkUserEmailVerified подтверждает email пользователя без кода. Уже
+// подтверждённый адрес не меняется.
+func (r Repository) MarkUserEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	tag, err := r.conn.Exec(ctx, queryMarkUserEmailVerified, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось подтвердить email пользователя с ID %v: %w", userID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) CreateUserToken(ctx context.Context, token models.UserToken) error {
+	_, err := r.conn.Exec(ctx, queryCreateUserToken, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить код пользователя с ID %v: %w", token.UserID, err)
+	}
+
+	return nil
+}
+
+// VerifyUserEmail погашает код подтверждения и подтверждает email его владельца.
+func (r Repository) VerifyUserEmail(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.inTx(ctx, "verifyUserEmail", func(ctx context.Context) (err error) {
+		userID, err = r.useUserToken(ctx, models.UserTokenEmailVerification, tokenHash, queryMarkUserEmailVerified)
+		return err
+	})
+
+	return userID, err
+}
+
+// ResetUserPassword погашает код сброса и задаёт его владельцу новый пароль.
+func (r Repository) ResetUserPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.inTx(ctx, "resetUserPassword", func(ctx context.Context) (err error) {
+		userID, err = r.useUserToken(ctx, models.UserTokenPasswordReset, tokenHash, queryUpdateUserPassword, passwordHash)
+		return err
+	})
+
+	return userID, err
+}
+
+// useUserToken в одной транзакции погашает действующий код и выполняет query
+// для его владельца; первым аргументом query получает ID пользователя.
+// Код, который уже использован или истёк, даёт apperrors.ErrInvalidUserToken.
+func (r Repository) useUserToken(ctx context.Context, purpose, tokenHash, query string, args ...any) (uuid.UUID, error) {
+	tx, err := r.conn.Begin(ctx)
 	if err != nil {
+		return uuid.Nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	if err = tx.QueryRow(ctx, queryConsumeUserToken, tokenHash, purpose).Scan(&userID); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", "", apperrors.ErrEmailNotFound
+			return uuid.Nil, apperrors.ErrInvalidUserToken
 		}
-		return "", "", fmt.Errorf("ошибка при получении данных пользователя по email: %w", err)
+		return uuid.Nil, fmt.Errorf("не удалось проверить код: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, query, append([]any{userID}, args...)...); err != nil {
+		return uuid.Nil, fmt.Errorf("не удалось обновить пользователя с ID %v: %w", userID, err)
 	}
-	return hashedPassword, userRole, nil
+
+	if err = tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return userID, nil
 }
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+)
+
+func (r Repository) LinkParcel(ctx context.Context, clientID uuid.UUID, barcode, pickupCodeHash string) (models.Parcel, error) {
+	var parcel models.Parcel
+	var pgError *pgconn.PgError
+
+	err := r.conn.QueryRow(ctx, queryLinkParcel, clientID, barcode, pickupCodeHash).Scan(&parcel.ID, &parcel.Barcode, &parcel.LinkedAt)
+	if err != nil {
+		if errors.As(err, &pgError) {
+			if pgError.Code == "23503" && pgError.ConstraintName == "parcels_client_id_fkey" {
+				return models.Parcel{}, apperrors.ErrUserNotFound
+			}
+		}
+		return models.Parcel{}, fmt.Errorf("не удалось привязать посылку: %w", err)
+	}
+
+	return parcel, nil
+}
+
+func (r Repository) GetClientParcels(ctx context.Context, clientID uuid.UUID) ([]models.Parcel, error) {
+	rows, err := r.reader(ctx).Query(ctx, queryGetClientParcels, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении посылок клиента: %w", err)
+	}
+	defer rows.Close()
+
+	parcels := []models.Parcel{}
+	for rows.Next() {
+		var parcel models.Parcel
+		var pvzID *uuid.UUID
+		var pvzCity, pvzAddress, pvzTimezone *string
+		err = rows.Scan(
+			&parcel.ID, &parcel.Barcode, &parcel.LinkedAt,
+			&parcel.ProductID, &parcel.ProductType, &parcel.ReceivedAt, &parcel.ProductStatus, &parcel.IssuedAt,
+			&parcel.ReceptionID, &parcel.ReceptionStatus, &parcel.ReadyAt,
+			&pvzID, &pvzCity, &pvzAddress, &pvzTimezone,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении посылок клиента: %w", err)
+		}
+
+		if pvzID != nil {
+			parcel.PVZ = &models.ParcelPVZ{ID: *pvzID, City: *pvzCity, Address: *pvzAddress, Timezone: *pvzTimezone}
+		}
+		parcels = append(parcels, parcel)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return parcels, nil
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"time"
+)
+
+func scanProduct(row pgx.Row, product *models.Product) error {
+	return row.Scan(
+		&product.ID, &product.DateTime, &product.Type, &product.ReceptionID,
+		&product.Barcode, &product.Status, &product.Condition, &product.Note, &product.IssuedAt, &product.ExpiredAt,
+	)
+}
+
+// expireProductsLockKey — ключ advisory-блокировки задачи истечения сроков хранения.
+// Блокировка не даёт нескольким репликам сервиса обрабатывать товары одновременно.
+const expireProductsLockKey int64 = 0x7076_7a01
+
+// ReleaseProduct снимает товар с полки ПВЗ, переводя его в статус issued или returned.
+// Товар должен находиться в закрытой приёмке ПВЗ, за которым закреплён
+// сотрудник, и ещё не быть выданным. Товар с истёкшим сроком хранения можно
+// только вернуть отправителю.
+func (r Repository) ReleaseProduct(ctx context.Context, productID uuid.UUID, status string, employeeID uuid.UUID) (models.Product, error) {
+	var product models.Product
+	err := r.inTx(ctx, "releaseProduct", func(ctx context.Context) (err error) {
+		product, err = r.releaseProduct(ctx, productID, status, employeeID)
+		return err
+	})
+
+	return product, err
+}
+
+func (r Repository) releaseProduct(ctx context.Context, productID uuid.UUID, status string, employeeID uuid.UUID) (models.Product, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return models.Product{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var productStatus, receptionStatus string
+	var assigned bool
+	err = tx.QueryRow(ctx, queryLockProductForRelease, productID, employeeID).Scan(&productStatus, &receptionStatus, &assigned)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Product{}, r.notFound(ctx, "product", productID, apperrors.ErrProductNotFound)
+		}
+		return models.Product{}, fmt.Errorf("ошибка при получении товара: %w", err)
+	}
+
+	if !assigned {
+		slog.WarnContext(ctx, "Сотрудник не закреплён за ПВЗ товара", "productId", productID, "employeeId", employeeID)
+		return models.Product{}, apperrors.ErrEmployeeNotAssigned
+	}
+	if productStatus == models.ProductStatusExpired && status != models.ProductStatusReturned {
+		slog.WarnContext(ctx, "Попытка выдать товар с истёкшим сроком хранения", "productId", productID)
+		return models.Product{}, apperrors.ErrProductExpired
+	}
+	if productStatus != models.ProductStatusReceived && productStatus != models.ProductStatusExpired {
+		slog.WarnContext(ctx, "Товар уже снят с полки", "productId", productID, "status", productStatus)
+		return models.Product{}, apperrors.ErrProductAlreadyReleased
+	}
+	if receptionStatus != "close" {
+		slog.WarnContext(ctx, "Товар в незакрытой приёмке", "productId", productID)
+		return models.Product{}, apperrors.ErrProductNotReady
+	}
+
+	var product models.Product
+	err = scanProduct(tx.QueryRow(ctx, queryReleaseProduct, productID, status, employeeID), &product)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка при снятии товара с полки", "productId", productID, "status", status, "error", err)
+		return models.Product{}, fmt.Errorf("ошибка при снятии товара с полки: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Product{}, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return product, nil
+}
+
+func (r Repository) GetPVZStock(ctx context.Context, pvzID uuid.UUID, condition string) ([]models.Product, error) {
+	products, err := r.queryProducts(ctx, queryGetPVZStock, pvzID, condition)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении остатков ПВЗ: %w", err)
+	}
+
+	return products, nil
+}
+
+func (r Repository) GetExpiredProducts(ctx context.Context, pvzID uuid.UUID) ([]models.Product, error) {
+	products, err := r.queryProducts(ctx, queryGetExpiredProducts, pvzID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении товаров с истёкшим сроком хранения: %w", err)
+	}
+
+	return products, nil
+}
+
+// GetProductPVZID возвращает ПВЗ, в приёмку которого принят товар.
+func (r Repository) GetProductPVZID(ctx context.Context, productID uuid.UUID) (uuid.UUID, error) {
+	var pvzID uuid.UUID
+	err := r.reader(ctx).QueryRow(ctx, queryGetProductPVZID, productID).Scan(&pvzID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, r.notFound(ctx, "product", productID, apperrors.ErrProductNotFound)
+		}
+		return uuid.Nil, fmt.Errorf("ошибка при получении ПВЗ товара с ID %v: %w", productID, err)
+	}
+
+	return pvzID, nil
+}
+
+// GetReceptionProducts возвращает товары приёмки; непустой condition оставляет
+// только товары в этом состоянии.
+func (r Repository) GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, condition string) ([]models.Product, error) {
+	products, err := r.queryProducts(ctx, queryGetReceptionProducts, receptionID, condition)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении товаров приёмки с ID %v: %w", receptionID, err)
+	}
+
+	return products, nil
+}
+
+// GetDeletedProducts возвращает товары приёмки, помеченные удалёнными, начиная с последнего удалённого.
+func (r Repository) GetDeletedProducts(ctx context.Context, receptionID uuid.UUID) ([]models.DeletedProduct, error) {
+	rows, err := r.reader(ctx).Query(ctx, queryGetDeletedProducts, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении удалённых товаров приёмки с ID %v: %w", receptionID, err)
+	}
+	defer rows.Close()
+
+	products := []models.DeletedProduct{}
+	for rows.Next() {
+		var product models.DeletedProduct
+		err = rows.Scan(
+			&product.ID, &product.DateTime, &product.Type, &product.ReceptionID, &product.Barcode, &product.Status,
+			&product.Condition, &product.Note, &product.IssuedAt, &product.ExpiredAt, &product.DeletedAt, &product.DeletedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении удалённого товара: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении удалённых товаров приёмки с ID %v: %w", receptionID, err)
+	}
+
+	return products, nil
+}
+
+// RestoreProduct снимает с товара пометку об удалении. Восстановить можно только
+// товар, приёмка которого ещё открыта.
+func (r Repository) RestoreProduct(ctx context.Context, productID uuid.UUID) (models.Product, error) {
+	var product models.Product
+	err := r.inTx(ctx, "restoreProduct", func(ctx context.Context) (err error) {
+		product, err = r.restoreProduct(ctx, productID)
+		return err
+	})
+
+	return product, err
+}
+
+func (r Repository) restoreProduct(ctx context.Context, productID uuid.UUID) (models.Product, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return models.Product{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deleted bool
+	var receptionStatus string
+	err = tx.QueryRow(ctx, queryLockProductForRestore, productID).Scan(&deleted, &receptionStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Product{}, r.notFound(ctx, "product", productID, apperrors.ErrProductNotFound)
+		}
+		return models.Product{}, fmt.Errorf("ошибка при получении товара: %w", err)
+	}
+
+	if !deleted {
+		return models.Product{}, apperrors.ErrProductNotDeleted
+	}
+	if receptionStatus != models.ReceptionStatusInProgress {
+		slog.WarnContext(ctx, "Попытка восстановить товар в закрытой приёмке", "productId", productID, "status", receptionStatus)
+		return models.Product{}, apperrors.ErrReceptionAlreadyClosed
+	}
+
+	var product models.Product
+	if err = scanProduct(tx.QueryRow(ctx, queryRestoreProduct, productID), &product); err != nil {
+		slog.ErrorContext(ctx, "Ошибка при восстановлении товара", "productId", productID, "error", err)
+		return models.Product{}, fmt.Errorf("ошибка при восстановлении товара: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Product{}, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return product, nil
+}
+
+func (r Repository) queryProducts(ctx context.Context, query string, args ...any) ([]models.Product, error) {
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		var product models.Product
+		if err = scanProduct(rows, &product); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// ExpireProducts переводит в статус expired товары, пролежавшие в ПВЗ дольше срока хранения
+// для своего типа. Если задачу уже выполняет другая реплика, возвращается ErrLockNotAcquired.
+func (r Repository) ExpireProducts(ctx context.Context, storagePeriods map[string]time.Duration) ([]models.ExpiredProduct, error) {
+	var expired []models.ExpiredProduct
+	err := r.inTx(ctx, "expireProducts", func(ctx context.Context) (err error) {
+		expired, err = r.expireProducts(ctx, storagePeriods)
+		return err
+	})
+
+	return expired, err
+}
+
+func (r Repository) expireProducts(ctx context.Context, storagePeriods map[string]time.Duration) ([]models.ExpiredProduct, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var acquired bool
+	if err = tx.QueryRow(ctx, queryTryAdvisoryLock, expireProductsLockKey).Scan(&acquired); err != nil {
+		return nil, fmt.Errorf("ошибка при получении advisory-блокировки: %w", err)
+	}
+	if !acquired {
+		return nil, apperrors.ErrLockNotAcquired
+	}
+
+	types := make([]string, 0, len(storagePeriods))
+	seconds := make([]float64, 0, len(storagePeriods))
+	for productType, period := range storagePeriods {
+		types = append(types, productType)
+		seconds = append(seconds, period.Seconds())
+	}
+
+	rows, err := tx.Query(ctx, queryExpireProducts, types, seconds)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при истечении сроков хранения: %w", err)
+	}
+	defer rows.Close()
+
+	expired := []models.ExpiredProduct{}
+	for rows.Next() {
+		var product models.ExpiredProduct
+		err = rows.Scan(
+			&product.ID, &product.DateTime, &product.Type, &product.ReceptionID, &product.Barcode,
+			&product.Status, &product.Condition, &product.Note, &product.IssuedAt, &product.ExpiredAt, &product.PVZID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении товаров с истёкшим сроком хранения: %w", err)
+		}
+		expired = append(expired, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return expired, nil
+}
@@ -2,60 +2,271 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/gookit/slog"
+	"github.com/jackc/pgx/v5"
+	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"math"
+	"time"
 )
 
+func scanPVZ(row pgx.Row, pvz *models.PVZ) error {
+	return row.Scan(
+		&pvz.ID, &pvz.RegistrationDate, &pvz.City, &pvz.Address,
+		&pvz.Latitude, &pvz.Longitude, &pvz.Timezone, &pvz.WorkingHours, &pvz.Status,
+	)
+}
+
 func (r Repository) CreatePVZ(ctx context.Context, city string) (models.PVZ, error) {
 	var pvz models.PVZ
-	err := r.conn.QueryRow(ctx, queryCreatePVZ, city).Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City)
+	err := scanPVZ(r.conn.QueryRow(ctx, queryCreatePVZ, city), &pvz)
 	if err != nil {
-		slog.Error("Ошибка при заведении ПВЗ", "error", err)
+		slog.ErrorContext(ctx, "Ошибка при заведении ПВЗ", "error", err)
 		return models.PVZ{}, fmt.Errorf("r.conn.QueryRow: %w", err)
 	}
 
 	return pvz, nil
 }
 
-func (r Repository) GetPVZList(ctx context.Context, params models.PVZFilterParams) ([]models.PVZWithReceptions, error) {
+func (r Repository) GetPVZByID(ctx context.Context, pvzID uuid.UUID) (models.PVZ, error) {
+	var pvz models.PVZ
+	err := scanPVZ(r.reader(ctx).QueryRow(ctx, queryGetPVZByID, pvzID), &pvz)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PVZ{}, r.notFound(ctx, "pvz", pvzID, apperrors.ErrPVZNotFound)
+		}
+		return models.PVZ{}, fmt.Errorf("не удалось получить ПВЗ с ID %v: %w", pvzID, err)
+	}
+
+	return pvz, nil
+}
+
+func (r Repository) UpdatePVZ(ctx context.Context, pvzID uuid.UUID, req models.UpdatePVZRequest) (models.PVZ, error) {
+	var pvz models.PVZ
+	err := scanPVZ(r.conn.QueryRow(ctx, queryUpdatePVZ, pvzID,
+		req.Address, req.Latitude, req.Longitude, req.Timezone, req.WorkingHours, req.Status), &pvz)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PVZ{}, r.notFound(ctx, "pvz", pvzID, apperrors.ErrPVZNotFound)
+		}
+		slog.ErrorContext(ctx, "Ошибка при обновлении ПВЗ", "pvzId", pvzID, "error", err)
+		return models.PVZ{}, fmt.Errorf("не удалось обновить ПВЗ с ID %v: %w", pvzID, err)
+	}
+
+	return pvz, nil
+}
+
+// ImportPVZ заводит ПВЗ из items в одной транзакции: при ошибке в любой
+// записи в базе не остаётся ни одного ПВЗ из импорта.
+func (r Repository) ImportPVZ(ctx context.Context, items []models.PVZImportItem) ([]models.PVZ, error) {
+	var imported []models.PVZ
+	err := r.inTx(ctx, "importPVZ", func(ctx context.Context) error {
+		var err error
+		imported, err = r.importPVZ(ctx, items)
+		return err
+	})
+
+	return imported, err
+}
+
+func (r Repository) importPVZ(ctx context.Context, items []models.PVZImportItem) ([]models.PVZ, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	imported := make([]models.PVZ, 0, len(items))
+	for i, item := range items {
+		var pvz models.PVZ
+		if err = scanPVZ(tx.QueryRow(ctx, queryCreatePVZ, item.City), &pvz); err != nil {
+			return nil, fmt.Errorf("ПВЗ №%d: не удалось завести: %w", i+1, err)
+		}
+
+		params := item.Params
+		err = scanPVZ(tx.QueryRow(ctx, queryUpdatePVZ, pvz.ID, params.Address, params.Latitude,
+			params.Longitude, params.Timezone, params.WorkingHours, params.Status), &pvz)
+		if err != nil {
+			return nil, fmt.Errorf("ПВЗ №%d: не удалось сохранить параметры: %w", i+1, err)
+		}
+
+		imported = append(imported, pvz)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+
+	return imported, nil
+}
+
+// DecommissionPVZ выводит ПВЗ из эксплуатации и помечает его удалённым.
+// ПВЗ не должен иметь открытой приёмки и товаров на полках.
+func (r Repository) DecommissionPVZ(ctx context.Context, pvzID, moderatorID uuid.UUID) error {
+	return r.inTx(ctx, "decommissionPVZ", func(ctx context.Context) error {
+		return r.decommissionPVZ(ctx, pvzID, moderatorID)
+	})
+}
+
+func (r Repository) decommissionPVZ(ctx context.Context, pvzID, moderatorID uuid.UUID) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка строки ПВЗ не даёт параллельно завести в нём приёмку.
+	if err = tx.QueryRow(ctx, queryLockPVZ, pvzID).Scan(&pvzID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.notFound(ctx, "pvz", pvzID, apperrors.ErrPVZNotFound)
+		}
+		return fmt.Errorf("не удалось заблокировать ПВЗ с ID %v: %w", pvzID, err)
+	}
+
+	var hasOpenReception bool
+	if err = tx.QueryRow(ctx, queryCheckOpenReception, pvzID).Scan(&hasOpenReception); err != nil {
+		return fmt.Errorf("не удалось проверить открытые приёмки ПВЗ с ID %v: %w", pvzID, err)
+	}
+	if hasOpenReception {
+		return apperrors.ErrPVZHasOpenReception
+	}
+
+	var hasStock bool
+	if err = tx.QueryRow(ctx, queryCheckPVZStock, pvzID).Scan(&hasStock); err != nil {
+		return fmt.Errorf("не удалось проверить остатки ПВЗ с ID %v: %w", pvzID, err)
+	}
+	if hasStock {
+		return apperrors.ErrPVZHasStock
+	}
+
+	var deletedBy *uuid.UUID
+	if moderatorID != uuid.Nil {
+		deletedBy = &moderatorID
+	}
+
+	if _, err = tx.Exec(ctx, queryDecommissionPVZ, pvzID, deletedBy); err != nil {
+		slog.ErrorContext(ctx, "Ошибка при выводе ПВЗ из эксплуатации", "pvzId", pvzID, "error", err)
+		return fmt.Errorf("не удалось вывести ПВЗ с ID %v из эксплуатации: %w", pvzID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return nil
+}
+
+// AssignEmployee закрепляет сотрудника за ПВЗ. Повторное закрепление ничего не меняет.
+func (r Repository) AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	if _, err := r.conn.Exec(ctx, queryAssignEmployee, pvzID, userID); err != nil {
+		return fmt.Errorf("не удалось закрепить сотрудника %v за ПВЗ с ID %v: %w", userID, pvzID, err)
+	}
+
+	return nil
+}
+
+func (r Repository) UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	if _, err := r.conn.Exec(ctx, queryUnassignEmployee, pvzID, userID); err != nil {
+		return fmt.Errorf("не удалось открепить сотрудника %v от ПВЗ с ID %v: %w", userID, pvzID, err)
+	}
+
+	return nil
+}
+
+// GetPVZTimezones возвращает часовые пояса ПВЗ по идентификаторам, включая
+// выведенные из эксплуатации: их приёмки и товары по-прежнему показываются.
+func (r Repository) GetPVZTimezones(ctx context.Context, pvzIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	rows, err := r.reader(ctx).Query(ctx, queryGetPVZTimezones, pvzIDs)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить часовые пояса ПВЗ: %w", err)
+	}
+	defer rows.Close()
+
+	timezones := make(map[uuid.UUID]string, len(pvzIDs))
+	for rows.Next() {
+		var pvzID uuid.UUID
+		var timezone string
+		if err = rows.Scan(&pvzID, &timezone); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать часовой пояс ПВЗ: %w", err)
+		}
+		timezones[pvzID] = timezone
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось получить часовые пояса ПВЗ: %w", err)
+	}
+
+	return timezones, nil
+}
+
+// IsEmployeeAssigned сообщает, закреплён ли сотрудник за ПВЗ. Закрепление
+// проверяет доступ, поэтому читается с основного узла, а не с реплики.
+func (r Repository) IsEmployeeAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error) {
+	var assigned bool
+	if err := r.conn.QueryRow(ctx, queryIsEmployeeAssigned, pvzID, userID).Scan(&assigned); err != nil {
+		return false, fmt.Errorf("не удалось проверить закрепление сотрудника %v за ПВЗ с ID %v: %w", userID, pvzID, err)
+	}
+
+	return assigned, nil
+}
+
+func (r Repository) GetPVZList(ctx context.Context, params models.PVZFilterParams) (models.PVZPage, error) {
 	var query string
 	var args []interface{}
-	query = `SELECT pvz.id, pvz.registration_date, pvz.city, receptions.id, receptions.date_time, receptions.status
+	query = `SELECT pvz.id, pvz.registration_date, pvz.city, pvz.address, pvz.latitude, pvz.longitude,
+		pvz.timezone, pvz.working_hours, pvz.status, receptions.id, receptions.date_time, receptions.status,
+		count(*) OVER ()
 	FROM pvz
 	LEFT JOIN receptions ON pvz.id = receptions.pvz_id
-	WHERE 1=1`
+	WHERE pvz.deleted_at IS NULL`
 
 	if params.StartDate != nil {
-		query += " AND receptions.date_time >= $1"
 		args = append(args, *params.StartDate)
+		query += fmt.Sprintf(" AND receptions.date_time >= $%d", len(args))
 	}
 	if params.EndDate != nil {
-		query += " AND receptions.date_time <= $2"
 		args = append(args, *params.EndDate)
+		query += fmt.Sprintf(" AND receptions.date_time <= $%d", len(args))
+	}
+	if params.StartDay != "" {
+		args = append(args, params.StartDay)
+		query += fmt.Sprintf(" AND receptions.date_time >= ($%d::date::timestamp AT TIME ZONE pvz.timezone)", len(args))
+	}
+	if params.EndDay != "" {
+		args = append(args, params.EndDay)
+		query += fmt.Sprintf(" AND receptions.date_time < (($%d::date + 1)::timestamp AT TIME ZONE pvz.timezone)", len(args))
 	}
 
-	query += " LIMIT $3 OFFSET $4"
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
 	args = append(args, params.Limit, (params.Page-1)*params.Limit)
 
-	rows, err := r.conn.Query(ctx, query, args...)
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return models.PVZPage{}, err
 	}
 	defer rows.Close()
 
+	// Страница отсчитывается по строкам соединения ПВЗ с приёмками, поэтому
+	// и следующая страница определяется по общему числу этих строк.
+	var total int
 	pvzMap := make(map[uuid.UUID]*models.PVZWithReceptions)
 
 	for rows.Next() {
 		var pvz models.PVZ
-		var reception models.Reception
-		err = rows.Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City, &reception.ID, &reception.DateTime, &reception.Status)
+		var receptionID *uuid.UUID
+		var receptionDateTime *time.Time
+		var receptionStatus *string
+		err = rows.Scan(
+			&pvz.ID, &pvz.RegistrationDate, &pvz.City, &pvz.Address, &pvz.Latitude, &pvz.Longitude,
+			&pvz.Timezone, &pvz.WorkingHours, &pvz.Status, &receptionID, &receptionDateTime, &receptionStatus,
+			&total,
+		)
 		if err != nil {
-			return nil, err
+			return models.PVZPage{}, err
 		}
 
-		reception.PVZID = pvz.ID
 		if _, exists := pvzMap[pvz.ID]; !exists {
 			pvzMap[pvz.ID] = &models.PVZWithReceptions{
 				PVZ:        pvz,
@@ -63,11 +274,20 @@ func (r Repository) GetPVZList(ctx context.Context, params models.PVZFilterParam
 			}
 		}
 
-		pvzMap[pvz.ID].Receptions = append(pvzMap[pvz.ID].Receptions, reception)
+		if receptionID == nil {
+			continue
+		}
+
+		pvzMap[pvz.ID].Receptions = append(pvzMap[pvz.ID].Receptions, models.Reception{
+			ID:       *receptionID,
+			DateTime: *receptionDateTime,
+			PVZID:    pvz.ID,
+			Status:   *receptionStatus,
+		})
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return models.PVZPage{}, err
 	}
 
 	var pvzList []models.PVZWithReceptions
@@ -75,5 +295,66 @@ func (r Repository) GetPVZList(ctx context.Context, params models.PVZFilterParam
 		pvzList = append(pvzList, *pvzWithReceptions)
 	}
 
+	return models.PVZPage{Items: pvzList, HasNext: params.Page*params.Limit < total}, nil
+}
+
+// GetNearbyPVZ возвращает ПВЗ в радиусе params.RadiusKm, отсортированные по расстоянию.
+// Limit <= 0 снимает ограничение на количество строк.
+func (r Repository) GetNearbyPVZ(ctx context.Context, params models.NearbyPVZParams) ([]models.PVZWithDistance, error) {
+	minLat, maxLat, minLon, maxLon := boundingBox(params.Latitude, params.Longitude, params.RadiusKm)
+
+	var limit *int
+	if params.Limit > 0 {
+		limit = &params.Limit
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, queryGetNearbyPVZ,
+		params.Latitude, params.Longitude, minLat, maxLat, minLon, maxLon, params.RadiusKm, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске ближайших ПВЗ: %w", err)
+	}
+	defer rows.Close()
+
+	pvzList := []models.PVZWithDistance{}
+	for rows.Next() {
+		var pvz models.PVZWithDistance
+		err = rows.Scan(
+			&pvz.ID, &pvz.RegistrationDate, &pvz.City, &pvz.Address, &pvz.Latitude, &pvz.Longitude,
+			&pvz.Timezone, &pvz.WorkingHours, &pvz.Status, &pvz.DistanceKm,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении ближайших ПВЗ: %w", err)
+		}
+		pvzList = append(pvzList, pvz)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return pvzList, nil
 }
+
+// boundingBox возвращает границы прямоугольника, гарантированно содержащего
+// окружность радиусом radiusKm. Вблизи полюсов и антимеридиана ограничение
+// по долготе снимается.
+func boundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, maxLon float64) {
+	const kmPerDegree = 111.045
+
+	deltaLat := radiusKm / kmPerDegree
+	minLat = math.Max(lat-deltaLat, -90)
+	maxLat = math.Min(lat+deltaLat, 90)
+
+	cosLat := math.Cos(lat * math.Pi / 180)
+	if minLat == -90 || maxLat == 90 || cosLat < 1e-6 {
+		return minLat, maxLat, -180, 180
+	}
+
+	deltaLon := radiusKm / (kmPerDegree * cosLat)
+	minLon, maxLon = lon-deltaLon, lon+deltaLon
+	if minLon < -180 || maxLon > 180 {
+		return minLat, maxLat, -180, 180
+	}
+
+	return minLat, maxLat, minLon, maxLon
+}
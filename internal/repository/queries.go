@@ -4,28 +4,200 @@ const (
 	queryCreatePVZ = `
 		INSERT INTO pvz (city)
 		VALUES ($1)
-		RETURNING id, registration_date, city;`
+		RETURNING id, registration_date, city, address, latitude, longitude, timezone, working_hours, status;`
 
+	queryGetPVZByID = `
+		SELECT id, registration_date, city, address, latitude, longitude, timezone, working_hours, status
+		FROM pvz
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	queryUpdatePVZ = `
+		UPDATE pvz
+		SET address       = COALESCE($2, address),
+		    latitude      = COALESCE($3, latitude),
+		    longitude     = COALESCE($4, longitude),
+		    timezone      = COALESCE($5, timezone),
+		    working_hours = COALESCE($6, working_hours),
+		    status        = COALESCE($7, status)
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, registration_date, city, address, latitude, longitude, timezone, working_hours, status`
+
+	// Расстояние считается по формуле гаверсинуса (радиус Земли 6371 км).
+	// Ограничивающий прямоугольник $3-$6 отсекает заведомо далёкие точки
+	// по индексу idx_pvz_coordinates до вычисления расстояния.
+	queryGetNearbyPVZ = `
+		SELECT id, registration_date, city, address, latitude, longitude, timezone, working_hours, status, distance_km
+		FROM (
+			SELECT *,
+				2 * 6371 * asin(LEAST(1, sqrt(
+					power(sin(radians(latitude - $1) / 2), 2) +
+					cos(radians($1)) * cos(radians(latitude)) * power(sin(radians(longitude - $2) / 2), 2)
+				))) AS distance_km
+			FROM pvz
+			WHERE status != 'decommissioned' AND deleted_at IS NULL
+				AND latitude BETWEEN $3 AND $4
+				AND longitude BETWEEN $5 AND $6
+		) AS candidates
+		WHERE distance_km <= $7
+		ORDER BY distance_km
+		LIMIT $8`
+
+	// Единственность открытой приёмки в ПВЗ обеспечивает частичный уникальный
+	// индекс uniq_receptions_pvz_in_progress. FOR SHARE заставляет дождаться
+	// параллельного вывода ПВЗ из эксплуатации и перепроверить условие.
 	queryCreateReception = `
-    	INSERT INTO receptions (pvz_id, status)
-		SELECT $1, 'in_progress'
-		WHERE NOT EXISTS (
-    	SELECT 1 FROM receptions WHERE pvz_id = $1 AND status != 'close'
-		)
-		RETURNING id, date_time, pvz_id, status;`
+		INSERT INTO receptions (pvz_id, status)
+		SELECT id, 'in_progress'
+		FROM pvz
+		WHERE id = $1 AND status = 'active' AND deleted_at IS NULL
+		FOR SHARE
+		RETURNING id, date_time, pvz_id, status, closed_at, stale_at, close_reason, closed_by;`
 
 	queryGetLastOpenReception = `
 		SELECT id, pvz_id, status
 		FROM receptions
 		WHERE pvz_id = $1 AND status = 'in_progress'
 		ORDER BY date_time DESC
-		LIMIT 1`
+		LIMIT 1
+		FOR UPDATE`
+
+	queryInsertManifestItems = `
+		INSERT INTO reception_manifest_items (reception_id, barcode, type, pickup_code_hash)
+		SELECT $1, barcode, type, NULLIF(pickup_code_hash, '')
+		FROM unnest($2::text[], $3::text[], $4::text[]) AS m(barcode, type, pickup_code_hash)`
+
+	queryGetManifestItems = `
+		SELECT barcode, type
+		FROM reception_manifest_items
+		WHERE reception_id = $1
+		ORDER BY barcode`
+
+	queryGetReceptionProductItems = `
+		SELECT COALESCE(barcode, ''), type
+		FROM products
+		WHERE reception_id = $1 AND deleted_at IS NULL
+		ORDER BY date_time`
+
+	queryUpsertDiscrepancyReport = `
+		INSERT INTO reception_discrepancies (reception_id, missing, extra, mismatched, acknowledged)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (reception_id) DO UPDATE
+		SET missing      = EXCLUDED.missing,
+		    extra        = EXCLUDED.extra,
+		    mismatched   = EXCLUDED.mismatched,
+		    acknowledged = EXCLUDED.acknowledged,
+		    created_at   = now()
+		RETURNING created_at`
+
+	queryGetDiscrepancyReport = `
+		SELECT reception_id, missing, extra, mismatched, acknowledged, created_at
+		FROM reception_discrepancies
+		WHERE reception_id = $1`
 
 	queryCloseReception = `
 		UPDATE receptions
-		SET status = 'close'
+		SET status = 'close', closed_at = now()
+		WHERE id = $1 AND status = 'in_progress'
+		RETURNING id, date_time, pvz_id, status, closed_at, stale_at, close_reason, closed_by`
+
+	queryLockReception = `
+		SELECT status
+		FROM receptions
+		WHERE id = $1
+		FOR UPDATE`
+
+	queryForceCloseReception = `
+		UPDATE receptions
+		SET status = $2, closed_at = now(), close_reason = $3, closed_by = $4
+		WHERE id = $1
+		RETURNING id, date_time, pvz_id, status, closed_at, stale_at, close_reason, closed_by`
+
+	// Блокировка строки ПВЗ конфликтует с блокировкой, которую внешний ключ
+	// берёт при вставке новой приёмки, поэтому создание приёмки в ПВЗ ждёт
+	// завершения переоткрытия.
+	queryLockPVZ = `
+		SELECT id
+		FROM pvz
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE`
+
+	queryCheckOpenReception = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM receptions
+			WHERE pvz_id = $1 AND status = 'in_progress'
+		)`
+
+	queryCheckPVZStock = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM products p
+			JOIN receptions r ON r.id = p.reception_id
+			WHERE r.pvz_id = $1 AND p.status IN ('received', 'expired') AND p.deleted_at IS NULL
+		)`
+
+	queryDecommissionPVZ = `
+		UPDATE pvz
+		SET status = 'decommissioned', deleted_at = now(), deleted_by = $2
+		WHERE id = $1`
+
+	queryGetReceptionForReopen = `
+		SELECT pvz_id, status, closed_at, COALESCE(closed_at >= now() - make_interval(secs => $2), false)
+		FROM receptions
+		WHERE id = $1`
+
+	queryCheckNewerReception = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM receptions
+			WHERE pvz_id = $1 AND id != $2 AND date_time >= (SELECT date_time FROM receptions WHERE id = $2)
+		)`
+
+	queryCheckReceptionProductsReleased = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM products
+			WHERE reception_id = $1 AND status != 'received' AND deleted_at IS NULL
+		)`
+
+	queryReopenReception = `
+		UPDATE receptions
+		SET status = 'in_progress', closed_at = NULL, close_reason = NULL, closed_by = NULL
 		WHERE id = $1
-		RETURNING id, pvz_id, status, date_time`
+		RETURNING id, date_time, pvz_id, status, closed_at, stale_at, close_reason, closed_by`
+
+	queryInsertReceptionReopen = `
+		INSERT INTO reception_reopens (reception_id, reopened_by, reason, closed_at)
+		VALUES ($1, $2, $3, $4)`
+
+	queryGetStaleReceptions = `
+		SELECT id, date_time, pvz_id, status, closed_at, stale_at, close_reason, closed_by
+		FROM receptions
+		WHERE status = 'in_progress' AND (stale_at IS NOT NULL OR date_time < now() - make_interval(secs => $1))
+			AND ($2::uuid IS NULL OR pvz_id IN (SELECT pvz_id FROM pvz_employees WHERE user_id = $2))
+		ORDER BY date_time`
+
+	// Местный день ПВЗ [$2, $3] переводится в моменты по его часовому поясу:
+	// полночь $2 и полночь дня после $3 по местному времени.
+	queryGetReceptionDailyStats = `
+		SELECT to_char((r.date_time AT TIME ZONE p.timezone)::date, 'YYYY-MM-DD') AS day,
+			count(DISTINCT r.id), count(pr.id)
+		FROM receptions r
+		JOIN pvz p ON p.id = r.pvz_id
+		LEFT JOIN products pr ON pr.reception_id = r.id AND pr.deleted_at IS NULL
+		WHERE r.pvz_id = $1
+			AND r.date_time >= ($2::date::timestamp AT TIME ZONE p.timezone)
+			AND r.date_time < (($3::date + 1)::timestamp AT TIME ZONE p.timezone)
+		GROUP BY day
+		ORDER BY day`
+
+	// Условие stale_at IS NULL делает пометку идемпотентной: при одновременном
+	// запуске на нескольких репликах каждая приёмка помечается ровно один раз.
+	queryFlagStaleReceptions = `
+		UPDATE receptions
+		SET stale_at = now()
+		WHERE status = 'in_progress' AND stale_at IS NULL AND date_time < now() - make_interval(secs => $1)
+		RETURNING id, date_time, pvz_id, status, closed_at, stale_at, close_reason, closed_by`
 
 	queryCheckEmailExists = `
 		SELECT EXISTS(
@@ -37,41 +209,303 @@ const (
 		INSERT INTO users(email, password, role) 
 		VALUES($1, $2, $3) RETURNING id`
 
-	queryGetRoleByEmail = `
-		SELECT password, role 
-		FROM users 
+	// Тестовый пользователь dummyLogin отличается паролем-заглушкой: обычную
+	// учётную запись с тем же email запрос не вернёт.
+	queryEnsureDummyUser = `
+		INSERT INTO users (email, password, role, email_verified_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (tenant_id, email) DO UPDATE SET role = EXCLUDED.role
+		WHERE users.password = EXCLUDED.password
+		RETURNING id`
+
+	queryGetUserByEmail = `
+		SELECT id, email, password, role, email_verified_at, password_changed_at
+		FROM users
 		WHERE email = $1`
 
 	queryGetActiveReception = `
 		SELECT id
 		FROM receptions
-		WHERE pvz_id = $1 AND status != 'close'
+		WHERE pvz_id = $1 AND status = 'in_progress'
 		ORDER BY date_time DESC
 		LIMIT 1
 		FOR UPDATE SKIP LOCKED
 	`
 	queryInsertProduct = `
-		INSERT INTO products (id, type, reception_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, date_time, type, reception_id
-	`
-
-	checkActiveReceptionQuery = `
-		SELECT EXISTS (
-			SELECT 1
-			FROM receptions r
-			WHERE r.pvz_id = $1 AND r.status != 'close'
-			LIMIT 1
-		)
+		INSERT INTO products (id, type, reception_id, barcode, condition, note)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''))
+		RETURNING id, date_time, type, reception_id, COALESCE(barcode, ''), status, condition, COALESCE(note, ''),
+			issued_at, expired_at
 	`
 
 	getLastProductQuery = `
 		SELECT p.id
     	FROM products p
     	JOIN receptions r ON p.reception_id = r.id
-    	WHERE r.pvz_id = $1 AND r.status != 'close'
+    	WHERE r.pvz_id = $1 AND r.status = 'in_progress' AND p.deleted_at IS NULL
     	ORDER BY p.date_time DESC
     	LIMIT 1
     	FOR UPDATE SKIP LOCKED
 	`
+
+	querySoftDeleteProduct = `
+		UPDATE products
+		SET deleted_at = now(), deleted_by = $2
+		WHERE id = $1`
+
+	queryGetDeletedProducts = `
+		SELECT id, date_time, type, reception_id, COALESCE(barcode, ''), status,
+			condition, COALESCE(note, ''), issued_at, expired_at, deleted_at, deleted_by
+		FROM products
+		WHERE reception_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
+
+	queryLockProductForRestore = `
+		SELECT p.deleted_at IS NOT NULL, r.status
+		FROM products p
+		JOIN receptions r ON r.id = p.reception_id
+		WHERE p.id = $1
+		FOR UPDATE OF p, r`
+
+	queryRestoreProduct = `
+		UPDATE products
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1
+		RETURNING id, date_time, type, reception_id, COALESCE(barcode, ''), status, condition, COALESCE(note, ''),
+			issued_at, expired_at`
+
+	queryLockProductForRelease = `
+		SELECT p.status, r.status,
+			EXISTS (SELECT 1 FROM pvz_employees e WHERE e.pvz_id = r.pvz_id AND e.user_id = $2)
+		FROM products p
+		JOIN receptions r ON r.id = p.reception_id
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE OF p`
+
+	queryReleaseProduct = `
+		UPDATE products
+		SET status = $2, issued_at = now(), issued_by = $3
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, date_time, type, reception_id, COALESCE(barcode, ''), status, condition, COALESCE(note, ''),
+			issued_at, expired_at`
+
+	queryGetPVZStock = `
+		SELECT p.id, p.date_time, p.type, p.reception_id, COALESCE(p.barcode, ''), p.status,
+			p.condition, COALESCE(p.note, ''), p.issued_at, p.expired_at
+		FROM products p
+		JOIN receptions r ON r.id = p.reception_id
+		WHERE r.pvz_id = $1 AND r.status = 'close' AND p.status IN ('received', 'expired') AND p.deleted_at IS NULL
+			AND ($2 = '' OR p.condition = $2)
+		ORDER BY p.date_time`
+
+	queryGetExpiredProducts = `
+		SELECT p.id, p.date_time, p.type, p.reception_id, COALESCE(p.barcode, ''), p.status,
+			p.condition, COALESCE(p.note, ''), p.issued_at, p.expired_at
+		FROM products p
+		JOIN receptions r ON r.id = p.reception_id
+		WHERE r.pvz_id = $1 AND p.status = 'expired' AND p.deleted_at IS NULL
+		ORDER BY p.expired_at`
+
+	queryGetProductPVZID = `
+		SELECT r.pvz_id
+		FROM products p
+		JOIN receptions r ON r.id = p.reception_id
+		WHERE p.id = $1 AND p.deleted_at IS NULL`
+
+	queryGetReceptionByID = `
+		SELECT id, date_time, pvz_id, status, closed_at, stale_at, close_reason, closed_by
+		FROM receptions
+		WHERE id = $1`
+
+	queryGetReceptionProducts = `
+		SELECT id, date_time, type, reception_id, COALESCE(barcode, ''), status,
+			condition, COALESCE(note, ''), issued_at, expired_at
+		FROM products
+		WHERE reception_id = $1 AND deleted_at IS NULL AND ($2 = '' OR condition = $2)
+		ORDER BY date_time`
+
+	queryInsertProductAttachment = `
+		INSERT INTO product_attachments (id, product_id, storage_key, file_name, content_type, size_bytes, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING uploaded_at`
+
+	queryGetProductAttachments = `
+		SELECT id, product_id, storage_key, file_name, content_type, size_bytes, uploaded_by, uploaded_at
+		FROM product_attachments
+		WHERE product_id = $1
+		ORDER BY uploaded_at`
+
+	queryGetProductAttachment = `
+		SELECT id, product_id, storage_key, file_name, content_type, size_bytes, uploaded_by, uploaded_at
+		FROM product_attachments a
+		WHERE product_id = $1 AND id = $2
+			AND EXISTS (SELECT 1 FROM products p WHERE p.id = a.product_id AND p.deleted_at IS NULL)`
+
+	queryTryAdvisoryLock = `SELECT pg_try_advisory_xact_lock($1)`
+
+	// Срок хранения отсчитывается с момента закрытия приёмки, то есть
+	// с того момента, когда товар стал доступен для выдачи.
+	queryExpireProducts = `
+		UPDATE products p
+		SET status = 'expired', expired_at = now()
+		FROM receptions r
+		JOIN unnest($1::text[], $2::float8[]) AS sp(type, seconds) ON true
+		WHERE p.reception_id = r.id
+			AND p.type = sp.type
+			AND p.status = 'received'
+			AND p.deleted_at IS NULL
+			AND r.status = 'close'
+			AND r.closed_at < now() - make_interval(secs => sp.seconds)
+		RETURNING p.id, p.date_time, p.type, p.reception_id, COALESCE(p.barcode, ''),
+			p.status, p.condition, COALESCE(p.note, ''), p.issued_at, p.expired_at, r.pvz_id`
+
+	// Повторная привязка того же штрихкода заменяет код получения: так клиент
+	// исправляет опечатку в коде или добавляет код к посылке, привязанной без него.
+	queryLinkParcel = `
+		INSERT INTO parcels (client_id, barcode, pickup_code_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (client_id, barcode) DO UPDATE
+			SET pickup_code_hash = EXCLUDED.pickup_code_hash, linked_at = now()
+		RETURNING id, barcode, linked_at`
+
+	// Для каждой посылки берётся последний принятый товар с тем же штрихкодом,
+	// код получения которого в манифесте приёмки совпал с кодом клиента.
+	queryGetClientParcels = `
+		SELECT pa.id, pa.barcode, pa.linked_at,
+			p.id, p.type, p.date_time, p.status, p.issued_at,
+			r.id, r.status, r.closed_at,
+			pvz.id, pvz.city, pvz.address, pvz.timezone
+		FROM parcels pa
+		LEFT JOIN LATERAL (
+			SELECT pr.id, pr.type, pr.date_time, pr.reception_id, pr.status, pr.issued_at
+			FROM products pr
+			JOIN reception_manifest_items m ON m.reception_id = pr.reception_id AND m.barcode = pr.barcode
+			WHERE pr.barcode = pa.barcode AND pr.deleted_at IS NULL
+				AND m.pickup_code_hash = pa.pickup_code_hash
+			ORDER BY pr.date_time DESC
+			LIMIT 1
+		) p ON true
+		LEFT JOIN receptions r ON r.id = p.reception_id
+		LEFT JOIN pvz ON pvz.id = r.pvz_id AND pvz.deleted_at IS NULL
+		WHERE pa.client_id = $1
+		ORDER BY pa.linked_at DESC`
+
+	queryGetUserByID = `
+		SELECT id, email, password, role, email_verified_at, password_changed_at
+		FROM users
+		WHERE id = $1`
+
+	// Смена пароля отзывает неиспользованные коды сброса: письмо, отправленное
+	// до смены, больше не должно менять пароль. password_changed_at отзывает
+	// выпущенные ранее JWT.
+	queryUpdateUserPassword = `
+		WITH revoked AS (
+			UPDATE user_tokens
+			SET used_at = now()
+			WHERE user_id = $1 AND purpose = 'password_reset' AND used_at IS NULL
+		)
+		UPDATE users
+		SET password = $2, password_changed_at = now()
+		WHERE id = $1`
+
+	queryMarkUserEmailVerified = `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, now())
+		WHERE id = $1`
+
+	// Действует только последний выданный код: прежние коды того же
+	// назначения отзываются.
+	queryCreateUserToken = `
+		WITH revoked AS (
+			UPDATE user_tokens
+			SET used_at = now()
+			WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+		)
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)`
+
+	queryConsumeUserToken = `
+		UPDATE user_tokens
+		SET used_at = now()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id`
+
+	queryAssignEmployee = `
+		INSERT INTO pvz_employees (pvz_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	queryUnassignEmployee = `
+		DELETE FROM pvz_employees
+		WHERE pvz_id = $1 AND user_id = $2`
+
+	queryGetPVZTimezones = `
+		SELECT id, timezone
+		FROM pvz
+		WHERE id = ANY($1)`
+
+	queryIsEmployeeAssigned = `
+		SELECT EXISTS (SELECT 1 FROM pvz_employees WHERE pvz_id = $1 AND user_id = $2)`
+
+	// Совпадения собираются из трёх источников: полнотекстовый и триграммный
+	// поиск по городу и адресу ПВЗ, префикс идентификатора приёмки и
+	// триграммный поиск по штрихкоду товара. $2 ограничивает выдачу ПВЗ
+	// сотрудника, $7 и $8 пусты, если запрос не похож на идентификатор
+	// или штрихкод.
+	searchHitsCTE = `
+		WITH scope AS (
+			SELECT id, COALESCE(city, '') AS city, address, search_text, search_vector
+			FROM pvz
+			WHERE deleted_at IS NULL
+				AND ($2::uuid IS NULL OR id IN (SELECT pvz_id FROM pvz_employees WHERE user_id = $2))
+		),
+		hits AS (
+			SELECT 'pvz' AS kind, s.id, s.id AS pvz_id, concat_ws(', ', NULLIF(s.city, ''), NULLIF(s.address, '')) AS title, s.city,
+				'' AS product_type, '' AS reception_status,
+				(ts_rank(s.search_vector, plainto_tsquery('russian', $1)) + word_similarity($1, s.search_text))::float8 AS rank
+			FROM scope s
+			WHERE s.search_vector @@ plainto_tsquery('russian', $1) OR $1 <% s.search_text
+			UNION ALL
+			SELECT 'reception', r.id, r.pvz_id, r.id::text, s.city, '', r.status, 1::float8
+			FROM receptions r
+			JOIN scope s ON s.id = r.pvz_id
+			WHERE $7 != '' AND r.id::text LIKE $7
+			UNION ALL
+			SELECT 'product', p.id, r.pvz_id, p.barcode, s.city, p.type, r.status,
+				(similarity(p.barcode, $1) + CASE WHEN p.barcode = $1 THEN 1 ELSE 0 END)::float8
+			FROM products p
+			JOIN receptions r ON r.id = p.reception_id
+			JOIN scope s ON s.id = r.pvz_id
+			WHERE $8 != '' AND p.deleted_at IS NULL AND (p.barcode % $1 OR p.barcode LIKE $8)
+		),
+		filtered AS (
+			SELECT *
+			FROM hits
+			WHERE ($3 = '' OR kind = $3)
+				AND ($4 = '' OR city = $4)
+				AND ($5 = '' OR product_type = $5)
+				AND ($6 = '' OR reception_status = $6)
+		)`
+
+	querySearchHits = searchHitsCTE + `
+		SELECT kind, id, pvz_id, title, city, product_type, reception_status, rank
+		FROM filtered
+		ORDER BY rank DESC, title
+		LIMIT $9`
+
+	querySearchFacets = searchHitsCTE + `
+		SELECT 'kind', kind, count(*) FROM filtered GROUP BY kind
+		UNION ALL
+		SELECT 'city', city, count(*) FROM filtered WHERE city != '' GROUP BY city
+		UNION ALL
+		SELECT 'productType', product_type, count(*) FROM filtered WHERE product_type != '' GROUP BY product_type
+		UNION ALL
+		SELECT 'receptionStatus', reception_status, count(*) FROM filtered WHERE reception_status != '' GROUP BY reception_status`
+
+	queryGetTenantBySlug = `
+		SELECT id, slug, name
+		FROM tenants
+		WHERE slug = $1`
+
+	queryResourceTenant = `SELECT resource_tenant_id($1, $2)`
 )
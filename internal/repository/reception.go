@@ -5,32 +5,112 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/gookit/slog"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"time"
 )
 
-func (r Repository) CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
+const receptionInProgressIndex = "uniq_receptions_pvz_in_progress"
+
+// isOpenReceptionConflict сообщает, что запрос нарушил правило
+// «не больше одной открытой приёмки в ПВЗ».
+func isOpenReceptionConflict(err error) bool {
+	var pgError *pgconn.PgError
+	return errors.As(err, &pgError) && pgError.Code == "23505" && pgError.ConstraintName == receptionInProgressIndex
+}
+
+func scanReception(row pgx.Row, reception *models.Reception) error {
+	return row.Scan(
+		&reception.ID, &reception.DateTime, &reception.PVZID, &reception.Status,
+		&reception.ClosedAt, &reception.StaleAt, &reception.CloseReason, &reception.ClosedBy,
+	)
+}
+
+func (r Repository) CreateReception(ctx context.Context, pvzID uuid.UUID, manifest []models.ManifestItem) (models.Reception, error) {
 	var reception models.Reception
+	err := r.inTx(ctx, "createReception", func(ctx context.Context) (err error) {
+		reception, err = r.createReception(ctx, pvzID, manifest)
+		return err
+	})
 
-	err := r.conn.QueryRow(ctx, queryCreateReception, pvzID).
-		Scan(&reception.ID, &reception.DateTime, &reception.PVZID, &reception.Status)
+	return reception, err
+}
+
+func (r Repository) createReception(ctx context.Context, pvzID uuid.UUID, manifest []models.ManifestItem) (models.Reception, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var reception models.Reception
+
+	err = scanReception(tx.QueryRow(ctx, queryCreateReception, pvzID), &reception)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			slog.Info("Приёмка не создана: существует незакрытая приемка", "pvzId", pvzID)
+			return models.Reception{}, r.pvzNotActive(ctx, tx, pvzID)
+		}
+		if isOpenReceptionConflict(err) {
+			slog.InfoContext(ctx, "Приёмка не создана: существует незакрытая приемка", "pvzId", pvzID)
 			return models.Reception{}, apperrors.ErrReceptionAlreadyInProgress
 		}
 
-		slog.Error("Ошибка при заведении приемки", "error", err, "pvzId", pvzID)
+		slog.ErrorContext(ctx, "Ошибка при заведении приемки", "error", err, "pvzId", pvzID)
 		return models.Reception{}, fmt.Errorf("не удалось создать приёмку: %w", err)
 	}
 
+	if len(manifest) > 0 {
+		barcodes := make([]string, len(manifest))
+		types := make([]string, len(manifest))
+		pickupCodeHashes := make([]string, len(manifest))
+		for i, item := range manifest {
+			barcodes[i], types[i], pickupCodeHashes[i] = item.Barcode, item.Type, item.PickupCodeHash
+		}
+
+		if _, err = tx.Exec(ctx, queryInsertManifestItems, reception.ID, barcodes, types, pickupCodeHashes); err != nil {
+			slog.ErrorContext(ctx, "Ошибка при сохранении манифеста приёмки", "error", err, "receptionId", reception.ID)
+			return models.Reception{}, fmt.Errorf("не удалось сохранить манифест приёмки: %w", err)
+		}
+		reception.Manifest = manifest
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
 	return reception, nil
 }
 
-func (r Repository) AddProductToActiveReception(ctx context.Context, productType string, pvzID uuid.UUID) (models.Product, error) {
+// pvzNotActive объясняет, почему queryCreateReception не вставил приёмку:
+// ПВЗ нет (или он удалён) либо он не в статусе active.
+func (r Repository) pvzNotActive(ctx context.Context, tx pgx.Tx, pvzID uuid.UUID) error {
+	var pvz models.PVZ
+	if err := scanPVZ(tx.QueryRow(ctx, queryGetPVZByID, pvzID), &pvz); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.notFound(ctx, "pvz", pvzID, apperrors.ErrPVZNotFound)
+		}
+		return fmt.Errorf("не удалось получить ПВЗ с ID %v: %w", pvzID, err)
+	}
+
+	slog.InfoContext(ctx, "Приёмка не создана: ПВЗ не активен", "pvzId", pvzID, "status", pvz.Status)
+	return apperrors.ErrPVZNotActive
+}
+
+func (r Repository) AddProductToActiveReception(ctx context.Context, req models.AddProductRequest) (models.Product, error) {
+	var product models.Product
+	err := r.inTx(ctx, "addProductToActiveReception", func(ctx context.Context) (err error) {
+		product, err = r.addProductToActiveReception(ctx, req)
+		return err
+	})
+
+	return product, err
+}
+
+func (r Repository) addProductToActiveReception(ctx context.Context, req models.AddProductRequest) (models.Product, error) {
 	tx, err := r.conn.Begin(ctx)
 	if err != nil {
 		return models.Product{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
@@ -38,7 +118,7 @@ func (r Repository) AddProductToActiveReception(ctx context.Context, productType
 	defer tx.Rollback(ctx)
 
 	var receptionID uuid.UUID
-	err = tx.QueryRow(ctx, queryGetActiveReception, pvzID).Scan(&receptionID)
+	err = tx.QueryRow(ctx, queryGetActiveReception, req.PVZID).Scan(&receptionID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.Product{}, apperrors.ErrNoActiveReception
@@ -48,8 +128,7 @@ func (r Repository) AddProductToActiveReception(ctx context.Context, productType
 
 	newID := uuid.New()
 	var product models.Product
-	err = tx.QueryRow(ctx, queryInsertProduct, newID, productType, receptionID).
-		Scan(&product.ID, &product.DateTime, &product.Type, &product.ReceptionID)
+	err = scanProduct(tx.QueryRow(ctx, queryInsertProduct, newID, req.Type, receptionID, req.Barcode, req.Condition, req.Note), &product)
 	if err != nil {
 		return models.Product{}, fmt.Errorf("ошибка при добавлении товара: %w", err)
 	}
@@ -61,57 +140,94 @@ func (r Repository) AddProductToActiveReception(ctx context.Context, productType
 	return product, nil
 }
 
-func (r Repository) DeleteLastProductInReception(ctx context.Context, pvzID uuid.UUID) error {
+// DeleteLastProductInReception помечает последний товар открытой приёмки удалённым.
+// Строка товара и его вложения остаются в базе и могут быть восстановлены модератором.
+func (r Repository) DeleteLastProductInReception(ctx context.Context, pvzID, employeeID uuid.UUID) error {
+	return r.inTx(ctx, "deleteLastProductInReception", func(ctx context.Context) error {
+		return r.deleteLastProductInReception(ctx, pvzID, employeeID)
+	})
+}
+
+func (r Repository) deleteLastProductInReception(ctx context.Context, pvzID, employeeID uuid.UUID) error {
 	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		slog.Error("Ошибка при начале транзакции", "pvzId", pvzID, "error", err)
+		slog.ErrorContext(ctx, "Ошибка при начале транзакции", "pvzId", pvzID, "error", err)
 		return fmt.Errorf("ошибка при начале транзакции: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
 	var productID uuid.UUID
 
-	var receptionExists bool
-	err = tx.QueryRow(ctx, checkActiveReceptionQuery, pvzID).Scan(&receptionExists)
+	// Блокировка приёмки не даёт закрыть её, пока из неё удаляется товар.
+	var reception models.Reception
+	err = tx.QueryRow(ctx, queryGetLastOpenReception, pvzID).Scan(&reception.ID, &reception.PVZID, &reception.Status)
 	if err != nil {
-		slog.Error("Ошибка при проверке активной приемки", "pvzId", pvzID, "error", err)
-		return fmt.Errorf("ошибка при проверке активной приемки: %w", err)
-	}
+		if errors.Is(err, pgx.ErrNoRows) {
+			slog.WarnContext(ctx, "Нет активной приемки", "pvzId", pvzID)
+			return apperrors.ErrNoActiveReception
+		}
 
-	if !receptionExists {
-		slog.Warn("Нет активной приемки", "pvzId", pvzID)
-		return apperrors.ErrNoActiveReception
+		slog.ErrorContext(ctx, "Ошибка при проверке активной приемки", "pvzId", pvzID, "error", err)
+		return fmt.Errorf("ошибка при проверке активной приемки: %w", err)
 	}
 
 	err = tx.QueryRow(ctx, getLastProductQuery, pvzID).Scan(&productID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			slog.Warn("Нет товаров для удаления", "pvzId", pvzID)
+			slog.WarnContext(ctx, "Нет товаров для удаления", "pvzId", pvzID)
 			return apperrors.ErrNoProductToDelete
 		}
 
-		slog.Error("Ошибка при получении последнего товара", "pvzId", pvzID, "error", err)
+		slog.ErrorContext(ctx, "Ошибка при получении последнего товара", "pvzId", pvzID, "error", err)
 		return fmt.Errorf("ошибка при получении последнего товара: %w", err)
 	}
 
-	_, err = tx.Exec(ctx, `DELETE FROM products WHERE id = $1`, productID)
+	var deletedBy *uuid.UUID
+	if employeeID != uuid.Nil {
+		deletedBy = &employeeID
+	}
+
+	_, err = tx.Exec(ctx, querySoftDeleteProduct, productID, deletedBy)
 	if err != nil {
-		slog.Error("Ошибка при удалении товара", "pvzId", pvzID, "productID", productID, "error", err)
+		slog.ErrorContext(ctx, "Ошибка при удалении товара", "pvzId", pvzID, "productID", productID, "error", err)
 		return fmt.Errorf("ошибка при удалении товара: %w", err)
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		slog.Error("Ошибка при фиксации транзакции", "pvzId", pvzID, "error", err)
+		slog.ErrorContext(ctx, "Ошибка при фиксации транзакции", "pvzId", pvzID, "error", err)
 		return fmt.Errorf("ошибка при фиксации транзакции: %w", err)
 	}
 
 	return nil
 }
 
-func (r Repository) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
+// ReconcileFunc сверяет принятые товары с манифестом приёмки и сообщает,
+// можно ли закрыть приёмку с получившимся отчётом.
+type ReconcileFunc func(manifest, scanned []models.ManifestItem) (report models.DiscrepancyReport, canClose bool)
+
+// CloseLastReception закрывает открытую приёмку ПВЗ. Если к приёмке приложен манифест,
+// принятые товары сверяются с ним через reconcile и отчёт сохраняется. Если
+// reconcile запрещает закрытие, отчёт всё равно фиксируется, но приёмка остаётся открытой.
+func (r Repository) CloseLastReception(ctx context.Context, pvzID uuid.UUID, reconcile ReconcileFunc) (models.Reception, error) {
+	var reception models.Reception
+	err := r.inTx(ctx, "closeLastReception", func(ctx context.Context) (err error) {
+		reception, err = r.closeLastReception(ctx, pvzID, reconcile)
+		return err
+	})
+
+	return reception, err
+}
+
+func (r Repository) closeLastReception(ctx context.Context, pvzID uuid.UUID, reconcile ReconcileFunc) (models.Reception, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var reception models.Reception
 
-	err := r.conn.QueryRow(ctx, queryGetLastOpenReception, pvzID).Scan(&reception.ID, &reception.PVZID, &reception.Status)
+	err = tx.QueryRow(ctx, queryGetLastOpenReception, pvzID).Scan(&reception.ID, &reception.PVZID, &reception.Status)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.Reception{}, apperrors.ErrReceptionAlreadyClosed
@@ -119,10 +235,314 @@ func (r Repository) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (mo
 		return models.Reception{}, fmt.Errorf("не удалось получить последнюю открытую приёмку для ПВЗ с ID %v: %w", pvzID, err)
 	}
 
-	err = r.conn.QueryRow(ctx, queryCloseReception, reception.ID).Scan(&reception.ID, &reception.PVZID, &reception.Status, &reception.DateTime)
+	report, canClose, err := saveDiscrepancyReport(ctx, tx, reception.ID, reconcile)
 	if err != nil {
+		return models.Reception{}, err
+	}
+	if !canClose {
+		if err = tx.Commit(ctx); err != nil {
+			return models.Reception{}, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+		}
+		slog.InfoContext(ctx, "Приёмка не закрыта: есть неподтверждённые расхождения с манифестом", "receptionId", reception.ID,
+			"missing", len(report.Missing), "extra", len(report.Extra), "mismatched", len(report.Mismatched))
+		return models.Reception{}, apperrors.ErrReceptionHasDiscrepancies
+	}
+
+	err = scanReception(tx.QueryRow(ctx, queryCloseReception, reception.ID), &reception)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Reception{}, apperrors.ErrReceptionAlreadyClosed
+		}
 		return models.Reception{}, fmt.Errorf("не удалось закрыть приемку с ID %v для ПВЗ с ID %v: %w", reception.ID, pvzID, err)
 	}
 
+	if err = tx.Commit(ctx); err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return reception, nil
+}
+
+// saveDiscrepancyReport сверяет приёмку с манифестом и сохраняет отчёт.
+// Приёмку без манифеста сверять не с чем, её можно закрыть без отчёта.
+func saveDiscrepancyReport(ctx context.Context, tx pgx.Tx, receptionID uuid.UUID, reconcile ReconcileFunc) (models.DiscrepancyReport, bool, error) {
+	manifest, err := queryManifestItems(ctx, tx, queryGetManifestItems, receptionID)
+	if err != nil {
+		return models.DiscrepancyReport{}, false, fmt.Errorf("не удалось получить манифест приёмки с ID %v: %w", receptionID, err)
+	}
+	if len(manifest) == 0 {
+		return models.DiscrepancyReport{}, true, nil
+	}
+
+	scanned, err := queryManifestItems(ctx, tx, queryGetReceptionProductItems, receptionID)
+	if err != nil {
+		return models.DiscrepancyReport{}, false, fmt.Errorf("не удалось получить товары приёмки с ID %v: %w", receptionID, err)
+	}
+
+	report, canClose := reconcile(manifest, scanned)
+	report.ReceptionID = receptionID
+
+	err = tx.QueryRow(ctx, queryUpsertDiscrepancyReport, report.ReceptionID,
+		report.Missing, report.Extra, report.Mismatched, report.Acknowledged).Scan(&report.CreatedAt)
+	if err != nil {
+		return models.DiscrepancyReport{}, false, fmt.Errorf("не удалось сохранить отчёт о расхождениях приёмки с ID %v: %w", receptionID, err)
+	}
+
+	return report, canClose, nil
+}
+
+func (r Repository) GetDiscrepancyReport(ctx context.Context, receptionID uuid.UUID) (models.DiscrepancyReport, error) {
+	var report models.DiscrepancyReport
+	err := r.reader(ctx).QueryRow(ctx, queryGetDiscrepancyReport, receptionID).Scan(
+		&report.ReceptionID, &report.Missing, &report.Extra, &report.Mismatched, &report.Acknowledged, &report.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.DiscrepancyReport{}, apperrors.ErrDiscrepancyReportNotFound
+		}
+		return models.DiscrepancyReport{}, fmt.Errorf("не удалось получить отчёт о расхождениях приёмки с ID %v: %w", receptionID, err)
+	}
+
+	return report, nil
+}
+
+func queryManifestItems(ctx context.Context, tx pgx.Tx, query string, receptionID uuid.UUID) ([]models.ManifestItem, error) {
+	rows, err := tx.Query(ctx, query, receptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.ManifestItem
+	for rows.Next() {
+		var item models.ManifestItem
+		if err = rows.Scan(&item.Barcode, &item.Type); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ForceCloseReception закрывает или отменяет незакрытую приёмку с указанием причины.
+// Приёмка с манифестом сверяется через reconcile, как и при обычном закрытии, но
+// отчёт о расхождениях закрытию не мешает.
+func (r Repository) ForceCloseReception(ctx context.Context, receptionID uuid.UUID, status, reason string, moderatorID uuid.UUID, reconcile ReconcileFunc) (models.Reception, error) {
+	var reception models.Reception
+	err := r.inTx(ctx, "forceCloseReception", func(ctx context.Context) (err error) {
+		reception, err = r.forceCloseReception(ctx, receptionID, status, reason, moderatorID, reconcile)
+		return err
+	})
+
+	return reception, err
+}
+
+func (r Repository) forceCloseReception(ctx context.Context, receptionID uuid.UUID, status, reason string, moderatorID uuid.UUID, reconcile ReconcileFunc) (models.Reception, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var currentStatus string
+	err = tx.QueryRow(ctx, queryLockReception, receptionID).Scan(&currentStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Reception{}, r.notFound(ctx, "reception", receptionID, apperrors.ErrReceptionNotFound)
+		}
+		return models.Reception{}, fmt.Errorf("ошибка при получении приёмки с ID %v: %w", receptionID, err)
+	}
+
+	if currentStatus != models.ReceptionStatusInProgress {
+		return models.Reception{}, apperrors.ErrReceptionAlreadyClosed
+	}
+
+	if _, _, err = saveDiscrepancyReport(ctx, tx, receptionID, reconcile); err != nil {
+		return models.Reception{}, err
+	}
+
+	var closedBy *uuid.UUID
+	if moderatorID != uuid.Nil {
+		closedBy = &moderatorID
+	}
+
+	var reception models.Reception
+	err = scanReception(tx.QueryRow(ctx, queryForceCloseReception, receptionID, status, reason, closedBy), &reception)
+	if err != nil {
+		slog.ErrorContext(ctx, "Ошибка при принудительном закрытии приёмки", "receptionId", receptionID, "error", err)
+		return models.Reception{}, fmt.Errorf("не удалось закрыть приёмку с ID %v: %w", receptionID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return reception, nil
+}
+
+// ReopenReception возвращает закрытую приёмку в работу, если с момента закрытия
+// прошло не больше gracePeriod и в ПВЗ после неё не заводилось новых приёмок.
+func (r Repository) ReopenReception(ctx context.Context, receptionID uuid.UUID, gracePeriod time.Duration, reason string, moderatorID uuid.UUID) (models.Reception, error) {
+	var reception models.Reception
+	err := r.inTx(ctx, "reopenReception", func(ctx context.Context) (err error) {
+		reception, err = r.reopenReception(ctx, receptionID, gracePeriod, reason, moderatorID)
+		return err
+	})
+
+	return reception, err
+}
+
+func (r Repository) reopenReception(ctx context.Context, receptionID uuid.UUID, gracePeriod time.Duration, reason string, moderatorID uuid.UUID) (models.Reception, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var pvzID uuid.UUID
+	var status string
+	var closedAt *time.Time
+	var withinGracePeriod bool
+	err = tx.QueryRow(ctx, queryGetReceptionForReopen, receptionID, gracePeriod.Seconds()).
+		Scan(&pvzID, &status, &closedAt, &withinGracePeriod)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Reception{}, r.notFound(ctx, "reception", receptionID, apperrors.ErrReceptionNotFound)
+		}
+		return models.Reception{}, fmt.Errorf("ошибка при получении приёмки с ID %v: %w", receptionID, err)
+	}
+
+	if err = tx.QueryRow(ctx, queryLockPVZ, pvzID).Scan(&pvzID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Reception{}, apperrors.ErrPVZNotActive
+		}
+		return models.Reception{}, fmt.Errorf("не удалось заблокировать ПВЗ с ID %v: %w", pvzID, err)
+	}
+
+	if _, err = tx.Exec(ctx, queryLockReception, receptionID); err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось заблокировать приёмку с ID %v: %w", receptionID, err)
+	}
+
+	// Статус перечитывается уже под блокировкой: приёмку могли переоткрыть параллельно.
+	err = tx.QueryRow(ctx, queryGetReceptionForReopen, receptionID, gracePeriod.Seconds()).
+		Scan(&pvzID, &status, &closedAt, &withinGracePeriod)
+	if err != nil {
+		return models.Reception{}, fmt.Errorf("ошибка при получении приёмки с ID %v: %w", receptionID, err)
+	}
+
+	if status != models.ReceptionStatusClose || closedAt == nil {
+		return models.Reception{}, apperrors.ErrReceptionNotClosed
+	}
+	if !withinGracePeriod {
+		return models.Reception{}, apperrors.ErrReopenWindowExpired
+	}
+
+	var newerExists bool
+	if err = tx.QueryRow(ctx, queryCheckNewerReception, pvzID, receptionID).Scan(&newerExists); err != nil {
+		return models.Reception{}, fmt.Errorf("ошибка при проверке новых приёмок ПВЗ с ID %v: %w", pvzID, err)
+	}
+	if newerExists {
+		return models.Reception{}, apperrors.ErrNewerReceptionExists
+	}
+
+	var released bool
+	if err = tx.QueryRow(ctx, queryCheckReceptionProductsReleased, receptionID).Scan(&released); err != nil {
+		return models.Reception{}, fmt.Errorf("ошибка при проверке товаров приёмки с ID %v: %w", receptionID, err)
+	}
+	if released {
+		return models.Reception{}, apperrors.ErrReceptionProductsReleased
+	}
+
+	var reception models.Reception
+	if err = scanReception(tx.QueryRow(ctx, queryReopenReception, receptionID), &reception); err != nil {
+		if isOpenReceptionConflict(err) {
+			return models.Reception{}, apperrors.ErrReceptionAlreadyInProgress
+		}
+		slog.ErrorContext(ctx, "Ошибка при переоткрытии приёмки", "receptionId", receptionID, "error", err)
+		return models.Reception{}, fmt.Errorf("не удалось переоткрыть приёмку с ID %v: %w", receptionID, err)
+	}
+
+	var reopenedBy *uuid.UUID
+	if moderatorID != uuid.Nil {
+		reopenedBy = &moderatorID
+	}
+
+	if _, err = tx.Exec(ctx, queryInsertReceptionReopen, receptionID, reopenedBy, reason, *closedAt); err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось записать переоткрытие приёмки с ID %v: %w", receptionID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Reception{}, fmt.Errorf("не удалось завершить транзакцию: %w", err)
+	}
+
+	return reception, nil
+}
+
+func (r Repository) GetReceptionByID(ctx context.Context, receptionID uuid.UUID) (models.Reception, error) {
+	var reception models.Reception
+	err := scanReception(r.reader(ctx).QueryRow(ctx, queryGetReceptionByID, receptionID), &reception)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Reception{}, r.notFound(ctx, "reception", receptionID, apperrors.ErrReceptionNotFound)
+		}
+		return models.Reception{}, fmt.Errorf("ошибка при получении приёмки с ID %v: %w", receptionID, err)
+	}
+
 	return reception, nil
 }
+
+// GetStaleReceptions возвращает зависшие приёмки. Если задан employeeID,
+// только в ПВЗ, за которыми закреплён сотрудник.
+func (r Repository) GetStaleReceptions(ctx context.Context, maxDuration time.Duration, employeeID *uuid.UUID) ([]models.Reception, error) {
+	return queryReceptions(ctx, r.reader(ctx), queryGetStaleReceptions, maxDuration.Seconds(), employeeID)
+}
+
+// GetReceptionDailyStats возвращает приёмки ПВЗ по местным дням с startDay по
+// endDay включительно. Дни без приёмок в результат не попадают.
+func (r Repository) GetReceptionDailyStats(ctx context.Context, pvzID uuid.UUID, startDay, endDay string) ([]models.DailyReceptionStats, error) {
+	rows, err := r.reader(ctx).Query(ctx, queryGetReceptionDailyStats, pvzID, startDay, endDay)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении приёмок по дням: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.DailyReceptionStats{}
+	for rows.Next() {
+		var day models.DailyReceptionStats
+		if err = rows.Scan(&day.Date, &day.Receptions, &day.Products); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении приёмок по дням: %w", err)
+		}
+		stats = append(stats, day)
+	}
+
+	return stats, rows.Err()
+}
+
+// FlagStaleReceptions помечает приёмки, открытые дольше maxDuration, и возвращает только что помеченные.
+func (r Repository) FlagStaleReceptions(ctx context.Context, maxDuration time.Duration) ([]models.Reception, error) {
+	return queryReceptions(ctx, r.conn, queryFlagStaleReceptions, maxDuration.Seconds())
+}
+
+func queryReceptions(ctx context.Context, db querier, query string, args ...any) ([]models.Reception, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении приёмок: %w", err)
+	}
+	defer rows.Close()
+
+	receptions := []models.Reception{}
+	for rows.Next() {
+		var reception models.Reception
+		if err = scanReception(rows, &reception); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении приёмок: %w", err)
+		}
+		receptions = append(receptions, reception)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return receptions, nil
+}
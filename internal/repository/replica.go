@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// replicaRetryAfter — сколько чтения идут в основную базу после того, как
+// реплика не ответила.
+const replicaRetryAfter = 10 * time.Second
+
+// querier — общая часть пула и транзакции, нужная методам чтения.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type Option func(*Repository)
+
+// WithReplica направляет методы чтения в пул реплики. Запись и чтение внутри
+// транзакций всегда идут в основную базу.
+func WithReplica(pool *pgxpool.Pool) Option {
+	return func(r *Repository) {
+		r.replica = &replicaReader{primary: r.conn, replica: pool}
+	}
+}
+
+// ReadFromPrimary заставляет методы чтения с этим контекстом обращаться к
+// основной базе, чтобы запрос увидел только что записанные данные.
+func ReadFromPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, "readFromPrimary", true)
+}
+
+func readsFromPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value("readFromPrimary").(bool)
+	return primary
+}
+
+// reader возвращает, куда направить запрос на чтение: в реплику, если она
+// настроена, доступна и контекст не требует основной базы.
+func (r Repository) reader(ctx context.Context) querier {
+	if r.replica == nil || readsFromPrimary(ctx) || r.replica.isDown() {
+		return r.conn
+	}
+
+	return r.replica
+}
+
+// replicaReader читает из реплики и повторяет запрос в основной базе, если
+// к реплике не удалось подключиться. После такой ошибки реплика пропускается
+// на replicaRetryAfter.
+type replicaReader struct {
+	primary   *pgxpool.Pool
+	replica   *pgxpool.Pool
+	downUntil atomic.Int64
+}
+
+func (rr *replicaReader) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := rr.replica.Query(ctx, sql, args...)
+	if err != nil && rr.failover(err) {
+		return rr.primary.Query(ctx, sql, args...)
+	}
+
+	return rows, err
+}
+
+func (rr *replicaReader) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &replicaRow{reader: rr, ctx: ctx, sql: sql, args: args}
+}
+
+func (rr *replicaReader) isDown() bool {
+	return time.Now().UnixNano() < rr.downUntil.Load()
+}
+
+// failover сообщает, стоит ли повторить запрос в основной базе, и при
+// необходимости выводит реплику из работы.
+func (rr *replicaReader) failover(err error) bool {
+	if !isConnectionError(err) {
+		return false
+	}
+
+	if !rr.isDown() {
+		slog.Warn("Реплика недоступна, чтение переключено на основную базу", "retryAfter", replicaRetryAfter, "error", err)
+	}
+	rr.downUntil.Store(time.Now().Add(replicaRetryAfter).UnixNano())
+
+	return true
+}
+
+// replicaRow откладывает запрос до Scan, как и pgx.Row, чтобы ошибку
+// подключения к реплике можно было обработать повтором в основной базе.
+type replicaRow struct {
+	reader *replicaReader
+	ctx    context.Context
+	sql    string
+	args   []any
+}
+
+func (row *replicaRow) Scan(dest ...any) error {
+	err := row.reader.replica.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	if err != nil && row.reader.failover(err) {
+		return row.reader.primary.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	}
+
+	return err
+}
@@ -5,25 +5,72 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kstsm/pvz-service/models"
+	"time"
 )
 
 type RepositoryI interface {
 	CreatePVZ(ctx context.Context, city string) (models.PVZ, error)
-	CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error)
-	AddProductToActiveReception(ctx context.Context, productType string, pvzID uuid.UUID) (models.Product, error)
-	DeleteLastProductInReception(ctx context.Context, pvzID uuid.UUID) error
-	CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error)
-	GetPVZList(ctx context.Context, params models.PVZFilterParams) ([]models.PVZWithReceptions, error)
+	GetPVZByID(ctx context.Context, pvzID uuid.UUID) (models.PVZ, error)
+	UpdatePVZ(ctx context.Context, pvzID uuid.UUID, req models.UpdatePVZRequest) (models.PVZ, error)
+	ImportPVZ(ctx context.Context, items []models.PVZImportItem) ([]models.PVZ, error)
+	DecommissionPVZ(ctx context.Context, pvzID, moderatorID uuid.UUID) error
+	AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error
+	UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error
+	IsEmployeeAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error)
+	GetPVZTimezones(ctx context.Context, pvzIDs []uuid.UUID) (map[uuid.UUID]string, error)
+	CreateReception(ctx context.Context, pvzID uuid.UUID, manifest []models.ManifestItem) (models.Reception, error)
+	AddProductToActiveReception(ctx context.Context, req models.AddProductRequest) (models.Product, error)
+	DeleteLastProductInReception(ctx context.Context, pvzID, employeeID uuid.UUID) error
+	GetDeletedProducts(ctx context.Context, receptionID uuid.UUID) ([]models.DeletedProduct, error)
+	RestoreProduct(ctx context.Context, productID uuid.UUID) (models.Product, error)
+	CloseLastReception(ctx context.Context, pvzID uuid.UUID, reconcile ReconcileFunc) (models.Reception, error)
+	GetDiscrepancyReport(ctx context.Context, receptionID uuid.UUID) (models.DiscrepancyReport, error)
+	GetReceptionByID(ctx context.Context, receptionID uuid.UUID) (models.Reception, error)
+	GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, condition string) ([]models.Product, error)
+	GetPVZList(ctx context.Context, params models.PVZFilterParams) (models.PVZPage, error)
+	GetReceptionDailyStats(ctx context.Context, pvzID uuid.UUID, startDay, endDay string) ([]models.DailyReceptionStats, error)
+	GetNearbyPVZ(ctx context.Context, params models.NearbyPVZParams) ([]models.PVZWithDistance, error)
 	CreateUser(ctx context.Context, user models.UserRegisterReq) (uuid.UUID, error)
-	GetRoleByEmail(ctx context.Context, email string) (string, string, error)
+	EnsureDummyUser(ctx context.Context, email, role string) (uuid.UUID, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetUserByID(ctx context.Context, userID uuid.UUID) (models.User, error)
+	UpdateUserPassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	MarkUserEmailVerified(ctx context.Context, userID uuid.UUID) error
+	CreateUserToken(ctx context.Context, token models.UserToken) error
+	VerifyUserEmail(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	ResetUserPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error)
+	GetTenantBySlug(ctx context.Context, slug string) (models.Tenant, error)
+	Search(ctx context.Context, params models.SearchParams) (models.SearchResult, error)
+	LinkParcel(ctx context.Context, clientID uuid.UUID, barcode, pickupCodeHash string) (models.Parcel, error)
+	GetClientParcels(ctx context.Context, clientID uuid.UUID) ([]models.Parcel, error)
+	ReleaseProduct(ctx context.Context, productID uuid.UUID, status string, employeeID uuid.UUID) (models.Product, error)
+	GetPVZStock(ctx context.Context, pvzID uuid.UUID, condition string) ([]models.Product, error)
+	GetProductPVZID(ctx context.Context, productID uuid.UUID) (uuid.UUID, error)
+	AddProductAttachments(ctx context.Context, attachments []models.ProductAttachment) ([]models.ProductAttachment, error)
+	GetProductAttachments(ctx context.Context, productID uuid.UUID) ([]models.ProductAttachment, error)
+	GetProductAttachment(ctx context.Context, productID, attachmentID uuid.UUID) (models.ProductAttachment, error)
+	GetExpiredProducts(ctx context.Context, pvzID uuid.UUID) ([]models.Product, error)
+	ExpireProducts(ctx context.Context, storagePeriods map[string]time.Duration) ([]models.ExpiredProduct, error)
+	ForceCloseReception(ctx context.Context, receptionID uuid.UUID, status, reason string, moderatorID uuid.UUID, reconcile ReconcileFunc) (models.Reception, error)
+	GetStaleReceptions(ctx context.Context, maxDuration time.Duration, employeeID *uuid.UUID) ([]models.Reception, error)
+	ReopenReception(ctx context.Context, receptionID uuid.UUID, gracePeriod time.Duration, reason string, moderatorID uuid.UUID) (models.Reception, error)
+	FlagStaleReceptions(ctx context.Context, maxDuration time.Duration) ([]models.Reception, error)
 }
 
 type Repository struct {
-	conn *pgxpool.Pool
+	conn    *pgxpool.Pool
+	replica *replicaReader
+	retry   retryPolicy
 }
 
-func NewRepository(conn *pgxpool.Pool) RepositoryI {
-	return &Repository{
-		conn: conn,
+func NewRepository(conn *pgxpool.Pool, opts ...Option) RepositoryI {
+	r := &Repository{
+		conn:  conn,
+		retry: defaultRetryPolicy,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
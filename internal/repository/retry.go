@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kstsm/pvz-service/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// retryPolicy задаёт, сколько раз и с какой паузой повторять транзакцию,
+// прерванную конфликтом сериализации, взаимоблокировкой или обрывом соединения.
+type retryPolicy struct {
+	attempts  int
+	baseDelay time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{attempts: 3, baseDelay: 50 * time.Millisecond}
+
+// WithTxRetry задаёт число попыток транзакции и начальную паузу между ними.
+// Пауза удваивается с каждой попыткой, а фактическая выбирается случайно
+// от половины до полутора её значений, чтобы конкурирующие запросы не
+// повторялись одновременно. attempts <= 1 отключает повторы.
+func WithTxRetry(attempts int, baseDelay time.Duration) Option {
+	return func(r *Repository) {
+		r.retry = retryPolicy{attempts: attempts, baseDelay: baseDelay}
+	}
+}
+
+// isConnectionError сообщает, что запрос не дошёл до базы и его можно безопасно
+// повторить.
+func isConnectionError(err error) bool {
+	var connectErr *pgconn.ConnectError
+	return errors.As(err, &connectErr) || pgconn.SafeToRetry(err)
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+	}
+
+	return isConnectionError(err)
+}
+
+// inTx выполняет fn и повторяет его, пока ошибка временная и попытки не исчерпаны.
+// fn должен целиком открывать и завершать свою транзакцию в переданном ctx:
+// тогда запросы всех попыток попадают в трассировке внутрь спана транзакции.
+func (r Repository) inTx(ctx context.Context, name string, fn func(ctx context.Context) error) (err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "tx "+name)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	delay := r.retry.baseDelay
+	for attempt := 1; ; attempt++ {
+		span.SetAttributes(attribute.Int("tx.attempts", attempt))
+		err = fn(ctx)
+		if err == nil || attempt >= r.retry.attempts || !isRetryable(err) {
+			return err
+		}
+
+		pause := delay
+		if delay > 0 {
+			pause = rand.N(delay) + delay/2
+		}
+		slog.WarnContext(ctx, "Повтор транзакции после временной ошибки", "tx", name, "attempt", attempt,
+			"pause", pause, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(pause):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestInTx(t *testing.T) {
+	serialization := fmt.Errorf("не удалось завершить транзакцию: %w", &pgconn.PgError{Code: pgSerializationFailure})
+	deadlock := &pgconn.PgError{Code: pgDeadlockDetected}
+	uniqueViolation := &pgconn.PgError{Code: "23505"}
+
+	tests := []struct {
+		name         string
+		policy       retryPolicy
+		errs         []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{
+			name:         "Успех с первой попытки",
+			policy:       retryPolicy{attempts: 3, baseDelay: time.Millisecond},
+			errs:         []error{nil},
+			wantAttempts: 1,
+		},
+		{
+			name:         "Конфликт сериализации повторяется",
+			policy:       retryPolicy{attempts: 3, baseDelay: time.Millisecond},
+			errs:         []error{serialization, deadlock, nil},
+			wantAttempts: 3,
+		},
+		{
+			name:         "Попытки исчерпаны",
+			policy:       retryPolicy{attempts: 2, baseDelay: time.Millisecond},
+			errs:         []error{serialization, serialization, nil},
+			wantErr:      serialization,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Нарушение уникальности не повторяется",
+			policy:       retryPolicy{attempts: 3, baseDelay: time.Millisecond},
+			errs:         []error{uniqueViolation, nil},
+			wantErr:      uniqueViolation,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Бизнес-ошибка не повторяется",
+			policy:       retryPolicy{attempts: 3, baseDelay: time.Millisecond},
+			errs:         []error{apperrors.ErrReceptionAlreadyClosed, nil},
+			wantErr:      apperrors.ErrReceptionAlreadyClosed,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Повторы отключены",
+			policy:       retryPolicy{attempts: 1, baseDelay: time.Millisecond},
+			errs:         []error{serialization, nil},
+			wantErr:      serialization,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Repository{retry: tt.policy}
+
+			attempts := 0
+			err := r.inTx(context.Background(), "test", func(ctx context.Context) error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+
+			assert.Equal(t, tt.wantAttempts, attempts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInTxStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := Repository{retry: retryPolicy{attempts: 5, baseDelay: time.Hour}}
+	attempts := 0
+	err := r.inTx(ctx, "test", func(ctx context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: pgSerializationFailure}
+	})
+
+	assert.Equal(t, 1, attempts)
+	var pgErr *pgconn.PgError
+	assert.True(t, errors.As(err, &pgErr))
+}
+
+func TestInTxSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	r := Repository{retry: retryPolicy{attempts: 3, baseDelay: time.Millisecond}}
+	var inner []trace.SpanContext
+	err := r.inTx(context.Background(), "closeLastReception", func(ctx context.Context) error {
+		inner = append(inner, trace.SpanContextFromContext(ctx))
+		if len(inner) == 1 {
+			return &pgconn.PgError{Code: pgDeadlockDetected}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "tx closeLastReception", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("tx.attempts", 2))
+	for _, sc := range inner {
+		assert.Equal(t, spans[0].SpanContext().SpanID(), sc.SpanID(), "запросы попыток идут внутри спана транзакции")
+	}
+}
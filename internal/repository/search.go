@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/kstsm/pvz-service/models"
+	"regexp"
+	"strings"
+)
+
+var (
+	receptionIDPrefixPattern = regexp.MustCompile(`^[0-9a-f-]{4,36}$`)
+	barcodeQueryPattern      = regexp.MustCompile(`^[0-9A-Za-z-]{4,64}$`)
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search ищет ПВЗ по городу и адресу, приёмки по префиксу идентификатора и
+// товары по штрихкоду. Фасеты считаются по всем совпадениям, а не только по
+// первым params.Limit.
+func (r Repository) Search(ctx context.Context, params models.SearchParams) (models.SearchResult, error) {
+	var receptionPrefix, barcodePrefix string
+	if query := strings.ToLower(params.Query); receptionIDPrefixPattern.MatchString(query) {
+		receptionPrefix = likeEscaper.Replace(query) + "%"
+	}
+	if barcodeQueryPattern.MatchString(params.Query) {
+		barcodePrefix = likeEscaper.Replace(params.Query) + "%"
+	}
+
+	args := []any{
+		params.Query, params.EmployeeID, params.Kind, params.City, params.ProductType, params.ReceptionStatus,
+		receptionPrefix, barcodePrefix,
+	}
+
+	result := models.SearchResult{
+		Hits: []models.SearchHit{},
+		Facets: models.SearchFacets{
+			Kind:            map[string]int{},
+			City:            map[string]int{},
+			ProductType:     map[string]int{},
+			ReceptionStatus: map[string]int{},
+		},
+	}
+
+	db := r.reader(ctx)
+	rows, err := db.Query(ctx, querySearchHits, append(args, params.Limit)...)
+	if err != nil {
+		return models.SearchResult{}, fmt.Errorf("ошибка при поиске: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hit models.SearchHit
+		err = rows.Scan(&hit.Kind, &hit.ID, &hit.PVZID, &hit.Title, &hit.City, &hit.ProductType,
+			&hit.ReceptionStatus, &hit.Rank)
+		if err != nil {
+			return models.SearchResult{}, fmt.Errorf("ошибка при чтении результата поиска: %w", err)
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	if err = rows.Err(); err != nil {
+		return models.SearchResult{}, fmt.Errorf("ошибка при поиске: %w", err)
+	}
+
+	facetRows, err := db.Query(ctx, querySearchFacets, args...)
+	if err != nil {
+		return models.SearchResult{}, fmt.Errorf("ошибка при подсчёте фасетов: %w", err)
+	}
+	defer facetRows.Close()
+
+	facets := map[string]map[string]int{
+		"kind":            result.Facets.Kind,
+		"city":            result.Facets.City,
+		"productType":     result.Facets.ProductType,
+		"receptionStatus": result.Facets.ReceptionStatus,
+	}
+	for facetRows.Next() {
+		var facet, value string
+		var count int
+		if err = facetRows.Scan(&facet, &value, &count); err != nil {
+			return models.SearchResult{}, fmt.Errorf("ошибка при чтении фасетов: %w", err)
+		}
+		facets[facet][value] = count
+		if facet == "kind" {
+			result.Total += count
+		}
+	}
+	if err = facetRows.Err(); err != nil {
+		return models.SearchResult{}, fmt.Errorf("ошибка при подсчёте фасетов: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+)
+
+func (r Repository) GetTenantBySlug(ctx context.Context, slug string) (models.Tenant, error) {
+	var t models.Tenant
+	err := r.conn.QueryRow(ctx, queryGetTenantBySlug, slug).Scan(&t.ID, &t.Slug, &t.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Tenant{}, apperrors.ErrTenantNotFound
+		}
+		return models.Tenant{}, fmt.Errorf("ошибка при получении арендатора %q: %w", slug, err)
+	}
+	return t, nil
+}
+
+// notFound возвращает err, предварительно проверив, не принадлежит ли
+// ненайденная строка другому арендатору. Такие обращения журналируются,
+// но вызывающему отдаётся то же «не найдено», чтобы не раскрывать чужие данные.
+func (r Repository) notFound(ctx context.Context, resource string, id uuid.UUID, err error) error {
+	current, ok := tenant.FromContext(ctx)
+	if !ok {
+		return err
+	}
+
+	var owner *uuid.UUID
+	if scanErr := r.conn.QueryRow(ctx, queryResourceTenant, resource, id).Scan(&owner); scanErr != nil {
+		slog.ErrorContext(ctx, "Не удалось определить арендатора строки", "resource", resource, "id", id, "error", scanErr)
+		return err
+	}
+
+	if owner != nil && *owner != current {
+		slog.WarnContext(ctx, "Попытка доступа к данным другого арендатора", "resource", resource, "id", id,
+			"tenantId", current, "ownerTenantId", *owner)
+	}
+
+	return err
+}
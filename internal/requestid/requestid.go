@@ -0,0 +1,42 @@
+// Package requestid переносит идентификатор запроса через контекст, чтобы
+// все записи лога одного запроса можно было найти по нему.
+package requestid
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+// Header — заголовок, в котором идентификатор приходит от балансировщика или
+// клиента и возвращается в ответе.
+const Header = "X-Request-ID"
+
+// maxLength ограничивает чужой идентификатор, чтобы он не раздувал логи.
+const maxLength = 128
+
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid сообщает, можно ли принять идентификатор из заголовка: он непустой,
+// не длиннее maxLength и состоит из видимых ASCII-символов.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, "requestID", id)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value("requestID").(string)
+	return id, ok && id != ""
+}
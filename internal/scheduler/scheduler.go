@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job — периодическая фоновая задача. Run вызывается сразу после запуска
+// планировщика и далее раз в Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs []Job
+	// stopLoops прекращает новые запуски, cancelRuns отменяет текущие.
+	stopLoops  context.CancelFunc
+	cancelRuns context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func New(jobs ...Job) *Scheduler {
+	return &Scheduler{jobs: jobs}
+}
+
+// Start запускает задачи в фоне. Задачи останавливаются при отмене ctx или вызове Stop.
+func (s *Scheduler) Start(ctx context.Context) {
+	runCtx, cancelRuns := context.WithCancel(ctx)
+	loopCtx, stopLoops := context.WithCancel(runCtx)
+	s.stopLoops, s.cancelRuns = stopLoops, cancelRuns
+
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			slog.Warn("Фоновая задача отключена: не задан интервал", "job", job.Name)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(loopCtx, runCtx, job)
+		}(job)
+	}
+}
+
+// Stop прекращает новые запуски и ждёт, пока текущие запуски завершатся сами.
+// Если ctx истекает раньше, текущие запуски отменяются и Stop возвращает ошибку ctx.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.stopLoops == nil {
+		return nil
+	}
+	s.stopLoops()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelRuns()
+		return nil
+	case <-ctx.Done():
+		slog.Warn("Фоновые задачи не завершились вовремя, отменяем", "error", ctx.Err())
+		s.cancelRuns()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx, runCtx context.Context, job Job) {
+	slog.Info("Фоновая задача запущена", "job", job.Name, "interval", job.Interval)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(runCtx); err != nil && runCtx.Err() == nil {
+			slog.Error("Ошибка фоновой задачи", "job", job.Name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Фоновая задача остановлена", "job", job.Name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRunsJobsUntilStopped(t *testing.T) {
+	var runs atomic.Int32
+	s := New(Job{
+		Name:     "test",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return errors.New("ошибка задачи не останавливает планировщик")
+		},
+	})
+
+	s.Start(context.Background())
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+
+	s.Stop(context.Background())
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func TestSchedulerStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+
+	s := New(Job{
+		Name:     "blocking",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	s.Start(ctx)
+	<-started
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.Stop(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("планировщик не остановился после отмены контекста")
+	}
+}
+
+func TestSchedulerSkipsJobsWithoutInterval(t *testing.T) {
+	s := New(Job{
+		Name: "disabled",
+		Run: func(ctx context.Context) error {
+			t.Fatal("задача без интервала не должна запускаться")
+			return nil
+		},
+	})
+
+	s.Start(context.Background())
+	s.Stop(context.Background())
+}
+
+func TestSchedulerStopWaitsForRunningJob(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+
+	s := New(Job{
+		Name:     "slow",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			close(started)
+			select {
+			case <-release:
+				finished.Store(true)
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	s.Start(context.Background())
+	<-started
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	assert.NoError(t, s.Stop(context.Background()))
+	assert.True(t, finished.Load(), "текущий запуск должен завершиться, а не отмениться")
+}
+
+func TestSchedulerStopCancelsRunsAfterTimeout(t *testing.T) {
+	started := make(chan struct{})
+
+	s := New(Job{
+		Name:     "stuck",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	s.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+}
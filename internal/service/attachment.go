@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+	"io"
+	"log/slog"
+)
+
+var attachmentExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// AddProductAttachments сохраняет фотографии товара в хранилище и записывает их метаданные.
+// Загрузка атомарна: если не сохранился хотя бы один файл или метаданные, уже
+// записанные файлы запроса удаляются и к товару ничего не привязывается.
+func (s Service) AddProductAttachments(ctx context.Context, productID uuid.UUID, uploads []models.AttachmentUpload, employeeID uuid.UUID) ([]models.ProductAttachment, error) {
+	if s.blobs == nil {
+		return nil, apperrors.ErrAttachmentsDisabled
+	}
+
+	pvzID, err := s.checkProductAccess(ctx, productID, &employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]models.ProductAttachment, 0, len(uploads))
+	for _, upload := range uploads {
+		attachment := models.ProductAttachment{
+			ID:          uuid.New(),
+			ProductID:   productID,
+			FileName:    upload.FileName,
+			ContentType: upload.ContentType,
+			Size:        upload.Size,
+			UploadedBy:  &employeeID,
+		}
+		attachment.StorageKey = fmt.Sprintf("%s/%s%s", productID, attachment.ID, attachmentExtensions[upload.ContentType])
+
+		// Файл попадает в список до записи: хранилище могло сохранить его частично.
+		attachments = append(attachments, attachment)
+		if err := s.blobs.Put(ctx, attachment.StorageKey, upload.Content); err != nil {
+			s.deleteAttachmentFiles(ctx, attachments)
+			return nil, fmt.Errorf("не удалось сохранить файл %q: %w", upload.FileName, err)
+		}
+	}
+
+	saved, err := s.repo.AddProductAttachments(ctx, attachments)
+	if err != nil {
+		s.deleteAttachmentFiles(ctx, attachments)
+		return nil, err
+	}
+	s.localizeAttachments(ctx, pvzID, saved)
+
+	slog.InfoContext(ctx, "К товару добавлены вложения", "productId", productID, "count", len(saved), "employeeId", employeeID)
+
+	return saved, nil
+}
+
+// GetProductAttachments возвращает вложения товара. Непустой employeeID
+// ограничивает доступ товарами ПВЗ, за которыми закреплён сотрудник.
+func (s Service) GetProductAttachments(ctx context.Context, productID uuid.UUID, employeeID *uuid.UUID) ([]models.ProductAttachment, error) {
+	pvzID, err := s.checkProductAccess(ctx, productID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.GetProductAttachments(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	s.localizeAttachments(ctx, pvzID, attachments)
+
+	return attachments, nil
+}
+
+// OpenProductAttachment возвращает метаданные вложения и его содержимое.
+// Вызывающий обязан закрыть возвращённый io.ReadCloser.
+func (s Service) OpenProductAttachment(ctx context.Context, productID, attachmentID uuid.UUID, employeeID *uuid.UUID) (models.ProductAttachment, io.ReadCloser, error) {
+	if s.blobs == nil {
+		return models.ProductAttachment{}, nil, apperrors.ErrAttachmentsDisabled
+	}
+
+	if _, err := s.checkProductAccess(ctx, productID, employeeID); err != nil {
+		return models.ProductAttachment{}, nil, err
+	}
+
+	attachment, err := s.repo.GetProductAttachment(ctx, productID, attachmentID)
+	if err != nil {
+		return models.ProductAttachment{}, nil, err
+	}
+
+	content, err := s.blobs.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return models.ProductAttachment{}, nil, fmt.Errorf("не удалось открыть вложение с ID %v: %w", attachmentID, err)
+	}
+
+	return attachment, content, nil
+}
+
+// checkProductAccess проверяет, что товар существует и, если запрос делает
+// сотрудник, что он закреплён за ПВЗ товара. Возвращает ПВЗ товара.
+func (s Service) checkProductAccess(ctx context.Context, productID uuid.UUID, employeeID *uuid.UUID) (uuid.UUID, error) {
+	pvzID, err := s.repo.GetProductPVZID(ctx, productID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if employeeID == nil {
+		return pvzID, nil
+	}
+
+	return pvzID, s.checkEmployeeAssigned(ctx, pvzID, *employeeID)
+}
+
+func (s Service) localizeAttachments(ctx context.Context, pvzID uuid.UUID, attachments []models.ProductAttachment) {
+	loc := s.pvzLocationByID(ctx, pvzID)
+	for i := range attachments {
+		localizeTime(&attachments[i].UploadedAt, loc)
+	}
+}
+
+// deleteAttachmentFiles удаляет файлы вложений из хранилища. Ошибки только
+// логируются: запись в базе к этому моменту уже удалена или не создана.
+func (s Service) deleteAttachmentFiles(ctx context.Context, attachments []models.ProductAttachment) {
+	if s.blobs == nil {
+		return
+	}
+
+	for _, attachment := range attachments {
+		if err := s.blobs.Delete(ctx, attachment.StorageKey); err != nil {
+			slog.ErrorContext(ctx, "Не удалось удалить файл вложения", "attachmentId", attachment.ID,
+				"key", attachment.StorageKey, "error", err)
+		}
+	}
+}
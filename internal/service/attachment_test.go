@@ -0,0 +1,207 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/storage"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+type memoryStorage struct {
+	files map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: make(map[string][]byte)}
+}
+
+func (s *memoryStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.files[key] = data
+	return nil
+}
+
+func (s *memoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.files[key]
+	if !ok {
+		return nil, storage.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+// failingStorage отказывает в записи failOn-го файла, успев сохранить его частично.
+type failingStorage struct {
+	*memoryStorage
+	failOn int
+	puts   int
+}
+
+func (s *failingStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	s.puts++
+	if s.puts == s.failOn {
+		s.files[key] = []byte("частично")
+		return errors.New("диск переполнен")
+	}
+	return s.memoryStorage.Put(ctx, key, r)
+}
+
+func TestAddProductAttachments(t *testing.T) {
+	productID, employeeID, pvzID := uuid.New(), uuid.New(), uuid.New()
+	uploads := func() []models.AttachmentUpload {
+		return []models.AttachmentUpload{
+			{FileName: "box.png", ContentType: "image/png", Size: 4, Content: strings.NewReader("png!")},
+			{FileName: "label.jpg", ContentType: "image/jpeg", Size: 4, Content: strings.NewReader("jpg!")},
+		}
+	}
+	allowAccess := func(mockRepo *MockRepo) {
+		mockRepo.On("GetProductPVZID", mock.Anything, productID).Return(pvzID, nil)
+		mockRepo.On("IsEmployeeAssigned", mock.Anything, pvzID, employeeID).Return(true, nil)
+	}
+
+	t.Run("успешная загрузка", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		blobs := newMemoryStorage()
+		service := NewService(mockRepo, WithBlobStorage(blobs))
+		allowPVZTimezones(mockRepo, nil)
+
+		allowAccess(mockRepo)
+		var saved []models.ProductAttachment
+		mockRepo.On("AddProductAttachments", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { saved = args.Get(1).([]models.ProductAttachment) }).
+			Return([]models.ProductAttachment{{FileName: "box.png"}, {FileName: "label.jpg"}}, nil)
+
+		attachments, err := service.AddProductAttachments(context.Background(), productID, uploads(), employeeID)
+
+		require.NoError(t, err)
+		require.Len(t, attachments, 2)
+		require.Len(t, saved, 2)
+		assert.Equal(t, "box.png", saved[0].FileName)
+		assert.Equal(t, &employeeID, saved[0].UploadedBy)
+		assert.True(t, strings.HasPrefix(saved[0].StorageKey, productID.String()+"/"))
+		assert.True(t, strings.HasSuffix(saved[0].StorageKey, ".png"))
+		assert.Equal(t, []byte("png!"), blobs.files[saved[0].StorageKey])
+		assert.Equal(t, []byte("jpg!"), blobs.files[saved[1].StorageKey])
+	})
+
+	t.Run("файлы удаляются, если метаданные не сохранились", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		blobs := newMemoryStorage()
+		service := NewService(mockRepo, WithBlobStorage(blobs))
+
+		allowAccess(mockRepo)
+		mockRepo.On("AddProductAttachments", mock.Anything, mock.Anything).
+			Return([]models.ProductAttachment(nil), errors.New("db error"))
+
+		_, err := service.AddProductAttachments(context.Background(), productID, uploads(), employeeID)
+
+		assert.Error(t, err)
+		assert.Empty(t, blobs.files)
+	})
+
+	t.Run("файлы удаляются, если не сохранился один из них", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		blobs := &failingStorage{memoryStorage: newMemoryStorage(), failOn: 2}
+		service := NewService(mockRepo, WithBlobStorage(blobs))
+
+		allowAccess(mockRepo)
+
+		_, err := service.AddProductAttachments(context.Background(), productID, uploads(), employeeID)
+
+		assert.Error(t, err)
+		assert.Empty(t, blobs.files)
+		mockRepo.AssertNotCalled(t, "AddProductAttachments", mock.Anything, mock.Anything)
+	})
+
+	t.Run("сотрудник не закреплён за ПВЗ товара", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		blobs := newMemoryStorage()
+		service := NewService(mockRepo, WithBlobStorage(blobs))
+
+		mockRepo.On("GetProductPVZID", mock.Anything, productID).Return(pvzID, nil)
+		mockRepo.On("IsEmployeeAssigned", mock.Anything, pvzID, employeeID).Return(false, nil)
+
+		_, err := service.AddProductAttachments(context.Background(), productID, uploads(), employeeID)
+
+		assert.ErrorIs(t, err, apperrors.ErrEmployeeNotAssigned)
+		assert.Empty(t, blobs.files)
+	})
+
+	t.Run("хранилище не настроено", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := NewService(mockRepo)
+
+		_, err := service.AddProductAttachments(context.Background(), productID, uploads(), employeeID)
+
+		assert.ErrorIs(t, err, apperrors.ErrAttachmentsDisabled)
+		mockRepo.AssertNotCalled(t, "GetProductPVZID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOpenProductAttachment(t *testing.T) {
+	productID, attachmentID, pvzID := uuid.New(), uuid.New(), uuid.New()
+	employeeID := uuid.New()
+
+	t.Run("модератору доступны вложения любого ПВЗ", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		blobs := newMemoryStorage()
+		blobs.files["product/photo.png"] = []byte("png!")
+		service := NewService(mockRepo, WithBlobStorage(blobs))
+
+		mockRepo.On("GetProductPVZID", mock.Anything, productID).Return(pvzID, nil)
+		mockRepo.On("GetProductAttachment", mock.Anything, productID, attachmentID).
+			Return(models.ProductAttachment{ID: attachmentID, StorageKey: "product/photo.png"}, nil)
+
+		_, content, err := service.OpenProductAttachment(context.Background(), productID, attachmentID, nil)
+
+		require.NoError(t, err)
+		defer content.Close()
+		data, _ := io.ReadAll(content)
+		assert.Equal(t, []byte("png!"), data)
+		mockRepo.AssertNotCalled(t, "IsEmployeeAssigned", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("сотрудник не закреплён за ПВЗ товара", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := NewService(mockRepo, WithBlobStorage(newMemoryStorage()))
+
+		mockRepo.On("GetProductPVZID", mock.Anything, productID).Return(pvzID, nil)
+		mockRepo.On("IsEmployeeAssigned", mock.Anything, pvzID, employeeID).Return(false, nil)
+
+		_, _, err := service.OpenProductAttachment(context.Background(), productID, attachmentID, &employeeID)
+
+		assert.ErrorIs(t, err, apperrors.ErrEmployeeNotAssigned)
+		mockRepo.AssertNotCalled(t, "GetProductAttachment", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteLastProductKeepsAttachmentFiles(t *testing.T) {
+	mockRepo := new(MockRepo)
+	blobs := newMemoryStorage()
+	blobs.files["product/photo.jpg"] = []byte("jpg")
+	service := NewService(mockRepo, WithBlobStorage(blobs))
+
+	pvzID, employeeID := uuid.New(), uuid.New()
+	mockRepo.On("DeleteLastProductInReception", mock.Anything, pvzID, employeeID).Return(nil)
+
+	err := service.DeleteLastProductInReception(context.Background(), pvzID, employeeID)
+
+	assert.NoError(t, err)
+	assert.Contains(t, blobs.files, "product/photo.jpg")
+}
@@ -2,25 +2,85 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/gookit/slog"
+	"github.com/google/uuid"
 	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/mailer"
+	"github.com/kstsm/pvz-service/internal/tenant"
 	"github.com/kstsm/pvz-service/models"
 	"golang.org/x/crypto/bcrypt"
+	"log/slog"
+	"sync"
+	"time"
 )
 
+// GetTenant возвращает арендатора по его коду; пустой код означает арендатора по умолчанию.
+func (s Service) GetTenant(ctx context.Context, slug string) (models.Tenant, error) {
+	if slug == "" {
+		slug = tenant.DefaultSlug
+	}
+
+	return s.repo.GetTenantBySlug(ctx, slug)
+}
+
+// EnsureDummyUser возвращает пользователя, от имени которого dummyLogin
+// выдаёт токен роли role. В арендаторе у каждой роли один тестовый
+// пользователь: за ним можно закрепить ПВЗ и привязать к нему посылки.
+func (s Service) EnsureDummyUser(ctx context.Context, role string) (uuid.UUID, error) {
+	return s.repo.EnsureDummyUser(ctx, "dummy-"+role+"@pvz-service.invalid", role)
+}
+
 func (s Service) RegisterUser(ctx context.Context, req models.UserRegisterReq) (models.UserRegisterResp, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	ctx, err := s.withTenant(ctx, req.Tenant)
+	if err != nil {
+		return models.UserRegisterResp{}, err
+	}
+
+	user, err := s.createUser(ctx, req)
+	if err != nil {
+		return models.UserRegisterResp{}, err
+	}
+
+	// Пользователь уже создан; если письмо не ушло, он запросит его повторно.
+	if err = s.sendEmailVerification(ctx, user.ID, user.Email); err != nil {
+		slog.ErrorContext(ctx, "Не удалось отправить письмо для подтверждения email", "userId", user.ID, "error", err)
+	}
+
+	return user, nil
+}
+
+// CreateUser создаёт пользователя с уже подтверждённым email, без письма.
+// Используется администратором из командной строки.
+func (s Service) CreateUser(ctx context.Context, req models.UserRegisterReq) (models.UserRegisterResp, error) {
+	ctx, err := s.withTenant(ctx, req.Tenant)
+	if err != nil {
+		return models.UserRegisterResp{}, err
+	}
+
+	user, err := s.createUser(ctx, req)
+	if err != nil {
+		return models.UserRegisterResp{}, err
+	}
+
+	if err = s.repo.MarkUserEmailVerified(ctx, user.ID); err != nil {
+		return models.UserRegisterResp{}, err
+	}
+
+	return user, nil
+}
+
+func (s Service) createUser(ctx context.Context, req models.UserRegisterReq) (models.UserRegisterResp, error) {
+	hashedPassword, err := s.hashPassword(req.Password)
 	if err != nil {
-		slog.Error("Ошибка хеширования пароля", "email", req.Email, "error", err)
-		return models.UserRegisterResp{}, fmt.Errorf("не удалось хешировать пароль: %w", err)
+		return models.UserRegisterResp{}, err
 	}
-	req.Password = string(hashedPassword)
+	req.Password = hashedPassword
 
 	userID, err := s.repo.CreateUser(ctx, req)
 	if err != nil {
-		slog.Error("Ошибка регистрации пользователя", "email", req.Email, "error", err)
+		slog.ErrorContext(ctx, "Ошибка регистрации пользователя", "email", req.Email, "error", err)
 		return models.UserRegisterResp{}, fmt.Errorf("не удалось зарегистрировать пользователя: %w", err)
 	}
 
@@ -34,21 +94,346 @@ func (s Service) RegisterUser(ctx context.Context, req models.UserRegisterReq) (
 }
 
 func (s Service) LoginUser(ctx context.Context, req models.UserLoginReq) (string, error) {
-	hashedPassword, userRole, err := s.repo.GetRoleByEmail(ctx, req.Email)
+	t, err := s.GetTenant(ctx, req.Tenant)
+	if err != nil {
+		return "", err
+	}
+	ctx = tenant.WithID(ctx, t.ID)
+
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrEmailNotFound) {
+			// Сравнение с пустышкой выравнивает время ответа с неверным паролем.
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+		}
 		return "", err
 	}
 
-	if err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.Password)); err != nil {
-		slog.Warn("Неверный пароль", "email", req.Email)
+	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		slog.WarnContext(ctx, "Неверный пароль", "email", req.Email)
 		return "", apperrors.ErrInvalidCredentials
 	}
 
-	token, err := auth.GenerateToken(userRole)
+	if user.EmailVerifiedAt == nil {
+		return "", apperrors.ErrEmailNotVerified
+	}
+
+	if s.tokens == nil {
+		return "", errors.New("выпуск токенов не настроен")
+	}
+
+	token, err := s.tokens.Generate(ctx, user.ID, user.Role, t.ID)
 	if err != nil {
-		slog.Error("Ошибка генерации токена", "error", err)
+		slog.ErrorContext(ctx, "Ошибка генерации токена", "error", err)
 		return "", fmt.Errorf("ошибка генерации токена: %w", err)
 	}
 
 	return token, nil
 }
+
+// SetUserPassword задаёт пользователю арендатора tenantSlug новый пароль.
+// Используется администратором из командной строки, старый пароль не проверяется.
+func (s Service) SetUserPassword(ctx context.Context, tenantSlug, email, password string) error {
+	ctx, err := s.withTenant(ctx, tenantSlug)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.UpdateUserPassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+	s.forgetPasswordChange(ctx, user.ID)
+
+	slog.InfoContext(ctx, "Пароль пользователя изменён", "userId", user.ID)
+
+	return nil
+}
+
+// ChangePassword меняет пароль вошедшего пользователя после проверки текущего.
+func (s Service) ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordReq) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		slog.WarnContext(ctx, "Неверный текущий пароль при смене пароля", "userId", userID)
+		return apperrors.ErrInvalidCredentials
+	}
+
+	hashedPassword, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.UpdateUserPassword(ctx, userID, hashedPassword); err != nil {
+		return err
+	}
+	s.forgetPasswordChange(ctx, userID)
+
+	slog.InfoContext(ctx, "Пароль пользователя изменён", "userId", userID)
+
+	return nil
+}
+
+// CheckTokenRevoked отклоняет токен, выпущенный до последней смены или сброса
+// пароля владельца: смена пароля завершает все прежние сессии. Токены без
+// user_id выпущены до появления пользователей в токенах и не проверяются.
+func (s Service) CheckTokenRevoked(ctx context.Context, claims models.TokenClaims) error {
+	if claims.UserID == uuid.Nil {
+		return nil
+	}
+
+	changedAt, err := s.passwordChangedAt(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return apperrors.ErrTokenRevoked
+		}
+		return err
+	}
+
+	// iat хранится с точностью до миллисекунды, поэтому момент смены
+	// округляется до неё же: токен, выпущенный в ту же миллисекунду, что и
+	// смена, отклоняется.
+	if changedAt != nil && !claims.IssuedAt.After(changedAt.Truncate(time.Millisecond)) {
+		return apperrors.ErrTokenRevoked
+	}
+
+	return nil
+}
+
+// VerifyEmail подтверждает email по коду из письма. Код одноразовый.
+func (s Service) VerifyEmail(ctx context.Context, req models.EmailVerificationReq) error {
+	ctx, err := s.withTenant(ctx, req.Tenant)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.repo.VerifyUserEmail(ctx, auth.HashUserToken(req.Token))
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Email пользователя подтверждён", "userId", userID)
+
+	return nil
+}
+
+// ResendEmailVerification отправляет новый код подтверждения, отзывая прежний.
+// Неизвестный или уже подтверждённый email не считается ошибкой, чтобы
+// ответ не выдавал, зарегистрирован ли адрес. По той же причине код
+// выдаётся и отправляется в фоне: иначе известный адрес отвечал бы дольше.
+func (s Service) ResendEmailVerification(ctx context.Context, req models.ResendVerificationReq) error {
+	ctx, err := s.withTenant(ctx, req.Tenant)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrEmailNotFound) {
+			slog.InfoContext(ctx, "Запрошено подтверждение неизвестного email")
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	s.inBackground(ctx, "Не удалось отправить письмо для подтверждения email", user.ID, func(ctx context.Context) error {
+		return s.sendEmailVerification(ctx, user.ID, user.Email)
+	})
+
+	return nil
+}
+
+// RequestPasswordReset отправляет код сброса пароля. Как и
+// ResendEmailVerification, для неизвестного email ничего не делает, а
+// известному отправляет письмо в фоне.
+func (s Service) RequestPasswordReset(ctx context.Context, req models.PasswordResetReq) error {
+	ctx, err := s.withTenant(ctx, req.Tenant)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrEmailNotFound) {
+			slog.InfoContext(ctx, "Запрошен сброс пароля для неизвестного email")
+			return nil
+		}
+		return err
+	}
+
+	s.inBackground(ctx, "Не удалось отправить письмо для сброса пароля", user.ID, func(ctx context.Context) error {
+		return s.sendPasswordReset(ctx, user.ID, user.Email)
+	})
+
+	return nil
+}
+
+// ResetPassword задаёт новый пароль по коду из письма. Код одноразовый,
+// неиспользованные коды сброса после этого тоже перестают действовать.
+func (s Service) ResetPassword(ctx context.Context, req models.PasswordResetConfirmReq) error {
+	ctx, err := s.withTenant(ctx, req.Tenant)
+	if err != nil {
+		return err
+	}
+
+	// Пароль проверяется до погашения кода, чтобы слабый пароль не сжигал код.
+	hashedPassword, err := s.hashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.repo.ResetUserPassword(ctx, auth.HashUserToken(req.Token), hashedPassword)
+	if err != nil {
+		return err
+	}
+	s.forgetPasswordChange(ctx, userID)
+
+	slog.InfoContext(ctx, "Пароль пользователя сброшен", "userId", userID)
+
+	return nil
+}
+
+const (
+	emailVerificationMailBody = `Здравствуйте!
+
+Чтобы подтвердить адрес и войти в сервис, отправьте этот код в POST /verify-email:
+
+%s
+
+Код действует до %s.`
+
+	passwordResetMailBody = `Здравствуйте!
+
+Чтобы задать новый пароль, отправьте этот код вместе с новым паролем в POST /password/reset/confirm:
+
+%s
+
+Код действует до %s. Если вы не запрашивали сброс пароля, не отвечайте на это письмо: пароль останется прежним.`
+)
+
+func (s Service) sendEmailVerification(ctx context.Context, userID uuid.UUID, email string) error {
+	token, expiresAt, err := s.issueUserToken(ctx, userID, models.UserTokenEmailVerification, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Подтверждение email",
+		Body:    fmt.Sprintf(emailVerificationMailBody, token, formatMailTime(expiresAt)),
+	})
+}
+
+func (s Service) sendPasswordReset(ctx context.Context, userID uuid.UUID, email string) error {
+	token, expiresAt, err := s.issueUserToken(ctx, userID, models.UserTokenPasswordReset, s.passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Сброс пароля",
+		Body:    fmt.Sprintf(passwordResetMailBody, token, formatMailTime(expiresAt)),
+	})
+}
+
+// inBackground выполняет send после ответа на запрос. Ошибка только
+// логируется: письмо пользователь запросит повторно.
+func (s Service) inBackground(ctx context.Context, errMsg string, userID uuid.UUID, send func(ctx context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		if err := send(ctx); err != nil {
+			slog.ErrorContext(ctx, errMsg, "userId", userID, "error", err)
+		}
+	}()
+}
+
+// WaitBackground ждёт отправки писем, начатых в фоне, но не дольше ctx.
+func (s Service) WaitBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// issueUserToken выдаёт пользователю новый одноразовый код на ttl.
+func (s Service) issueUserToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, time.Time, error) {
+	token, hash, err := auth.NewUserToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	err = s.repo.CreateUserToken(ctx, models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+func formatMailTime(t time.Time) string {
+	return t.UTC().Format("02.01.2006 15:04 MST")
+}
+
+// dummyPasswordHash — bcrypt-хеш, с которым сравнивается пароль неизвестного
+// пользователя. Стоимость совпадает с хешами настоящих паролей.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// hashPassword проверяет пароль по политике и хеширует его.
+func (s Service) hashPassword(password string) (string, error) {
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("не удалось хешировать пароль: %w", err)
+	}
+
+	return string(hashedPassword), nil
+}
+
+// withTenant добавляет в контекст арендатора с кодом slug.
+func (s Service) withTenant(ctx context.Context, slug string) (context.Context, error) {
+	t, err := s.GetTenant(ctx, slug)
+	if err != nil {
+		return ctx, err
+	}
+
+	return tenant.WithID(ctx, t.ID), nil
+}
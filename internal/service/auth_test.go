@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/cache"
+	"github.com/kstsm/pvz-service/internal/mailer"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingMailer запоминает отправленные письма.
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// failingMailer не может отправить ни одного письма.
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return errors.New("smtp unavailable")
+}
+
+// mailedToken достаёт код из письма: он стоит отдельной строкой.
+func mailedToken(t *testing.T, msg mailer.Message) string {
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if len(line) == 43 && !strings.Contains(line, " ") {
+			return line
+		}
+	}
+	t.Fatalf("в письме нет кода: %q", msg.Body)
+	return ""
+}
+
+func newAuthTestService(repo *MockRepo, mail *recordingMailer) *Service {
+	repo.On("GetTenantBySlug", mock.Anything, tenant.DefaultSlug).Return(models.Tenant{ID: tenant.DefaultID}, nil).Maybe()
+	return NewService(repo, WithMailer(mail), WithTokens(auth.NewTokens("test-secret")))
+}
+
+func hashOf(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return string(hash)
+}
+
+func TestEnsureDummyUser(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := Service{repo: mockRepo}
+	userID := uuid.New()
+
+	mockRepo.On("EnsureDummyUser", mock.Anything, "dummy-client@pvz-service.invalid", "client").Return(userID, nil)
+
+	got, err := service.EnsureDummyUser(context.Background(), "client")
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, got)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegisterUserSendsVerification(t *testing.T) {
+	mockRepo, mail := new(MockRepo), &recordingMailer{}
+	service := newAuthTestService(mockRepo, mail)
+	userID := uuid.New()
+
+	mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(req models.UserRegisterReq) bool {
+		return bcrypt.CompareHashAndPassword([]byte(req.Password), []byte("password123")) == nil
+	})).Return(userID, nil)
+	var stored models.UserToken
+	mockRepo.On("CreateUserToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(models.UserToken)
+	}).Return(nil)
+
+	user, err := service.RegisterUser(context.Background(), models.UserRegisterReq{
+		Email: "client@example.com", Password: "password123", Role: "client",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, user.ID)
+	require.Len(t, mail.sent, 1)
+	assert.Equal(t, "client@example.com", mail.sent[0].To)
+	assert.Equal(t, userID, stored.UserID)
+	assert.Equal(t, models.UserTokenEmailVerification, stored.Purpose)
+	assert.Equal(t, auth.HashUserToken(mailedToken(t, mail.sent[0])), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegisterUserRejectsWeakPassword(t *testing.T) {
+	mockRepo, mail := new(MockRepo), &recordingMailer{}
+	service := newAuthTestService(mockRepo, mail)
+
+	_, err := service.RegisterUser(context.Background(), models.UserRegisterReq{
+		Email: "client@example.com", Password: "123", Role: "client",
+	})
+
+	assert.ErrorIs(t, err, apperrors.ErrWeakPassword)
+	assert.Empty(t, mail.sent)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestLoginUserRequiresVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name          string
+		verifiedAt    *time.Time
+		expectedError error
+	}{
+		{name: "Email подтверждён", verifiedAt: &verifiedAt},
+		{name: "Email не подтверждён", expectedError: apperrors.ErrEmailNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			service := newAuthTestService(mockRepo, &recordingMailer{})
+			mockRepo.On("GetUserByEmail", mock.Anything, "user@example.com").Return(models.User{
+				ID: uuid.New(), Email: "user@example.com", Password: hashOf(t, "password123"),
+				Role: "client", EmailVerifiedAt: tt.verifiedAt,
+			}, nil)
+
+			token, err := service.LoginUser(context.Background(), models.UserLoginReq{Email: "user@example.com", Password: "password123"})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Empty(t, token)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, token)
+		})
+	}
+}
+
+func TestLoginUserUnknownEmail(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := newAuthTestService(mockRepo, &recordingMailer{})
+	mockRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return(models.User{}, apperrors.ErrEmailNotFound)
+
+	token, err := service.LoginUser(context.Background(), models.UserLoginReq{Email: "nobody@example.com", Password: "password123"})
+
+	assert.ErrorIs(t, err, apperrors.ErrEmailNotFound)
+	assert.Empty(t, token)
+}
+
+func TestCheckTokenRevoked(t *testing.T) {
+	changedAt := time.Date(2026, 10, 19, 12, 0, 0, 500_400_000, time.UTC)
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		claims        models.TokenClaims
+		changedAt     *time.Time
+		userErr       error
+		expectedError error
+	}{
+		{
+			name:   "Пароль не менялся",
+			claims: models.TokenClaims{UserID: userID, IssuedAt: changedAt.Add(-time.Hour)},
+		},
+		{
+			name:          "Токен выпущен до смены пароля",
+			claims:        models.TokenClaims{UserID: userID, IssuedAt: changedAt.Add(-time.Minute)},
+			changedAt:     &changedAt,
+			expectedError: apperrors.ErrTokenRevoked,
+		},
+		{
+			name:          "Токен выпущен в ту же секунду до смены",
+			claims:        models.TokenClaims{UserID: userID, IssuedAt: changedAt.Truncate(time.Second)},
+			changedAt:     &changedAt,
+			expectedError: apperrors.ErrTokenRevoked,
+		},
+		{
+			name:          "Токен выпущен в ту же миллисекунду, что и смена",
+			claims:        models.TokenClaims{UserID: userID, IssuedAt: changedAt.Truncate(time.Millisecond)},
+			changedAt:     &changedAt,
+			expectedError: apperrors.ErrTokenRevoked,
+		},
+		{
+			name:      "Токен выпущен в следующую миллисекунду после смены",
+			claims:    models.TokenClaims{UserID: userID, IssuedAt: changedAt.Truncate(time.Millisecond).Add(time.Millisecond)},
+			changedAt: &changedAt,
+		},
+		{
+			name:          "Токен без iat после смены пароля",
+			claims:        models.TokenClaims{UserID: userID},
+			changedAt:     &changedAt,
+			expectedError: apperrors.ErrTokenRevoked,
+		},
+		{
+			name:          "Пользователь удалён",
+			claims:        models.TokenClaims{UserID: userID, IssuedAt: changedAt},
+			userErr:       apperrors.ErrUserNotFound,
+			expectedError: apperrors.ErrTokenRevoked,
+		},
+		{
+			name:   "Токен без user_id",
+			claims: models.TokenClaims{Role: "moderator"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			service := Service{repo: mockRepo}
+			mockRepo.On("GetUserByID", mock.Anything, userID).
+				Return(models.User{ID: userID, PasswordChangedAt: tt.changedAt}, tt.userErr).Maybe()
+
+			err := service.CheckTokenRevoked(context.Background(), tt.claims)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckTokenRevokedCache(t *testing.T) {
+	userID := uuid.New()
+	issuedAt := time.Now()
+	changedAt := issuedAt.Add(time.Second)
+	claims := models.TokenClaims{UserID: userID, IssuedAt: issuedAt}
+
+	mockRepo := new(MockRepo)
+	service := newAuthTestService(mockRepo, &recordingMailer{})
+	WithPasswordChangeCache(cache.NewLRU(10), time.Minute)(service)
+	mockRepo.On("GetUserByID", mock.Anything, userID).Return(models.User{ID: userID}, nil).Once()
+	mockRepo.On("GetUserByID", mock.Anything, userID).Return(models.User{ID: userID, PasswordChangedAt: &changedAt}, nil).Once()
+	mockRepo.On("ResetUserPassword", mock.Anything, auth.HashUserToken("code"), mock.Anything).Return(userID, nil)
+
+	assert.NoError(t, service.CheckTokenRevoked(context.Background(), claims))
+	assert.NoError(t, service.CheckTokenRevoked(context.Background(), claims))
+	mockRepo.AssertNumberOfCalls(t, "GetUserByID", 1)
+
+	// Сброс пароля удаляет запись, и прежний токен отклоняется сразу.
+	err := service.ResetPassword(context.Background(), models.PasswordResetConfirmReq{Token: "code", Password: "new-password1"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.CheckTokenRevoked(context.Background(), claims), apperrors.ErrTokenRevoked)
+	assert.ErrorIs(t, service.CheckTokenRevoked(context.Background(), claims), apperrors.ErrTokenRevoked)
+	mockRepo.AssertNumberOfCalls(t, "GetUserByID", 2)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := newAuthTestService(mockRepo, &recordingMailer{})
+	mockRepo.On("VerifyUserEmail", mock.Anything, auth.HashUserToken("good")).Return(uuid.New(), nil)
+	mockRepo.On("VerifyUserEmail", mock.Anything, auth.HashUserToken("used")).Return(uuid.Nil, apperrors.ErrInvalidUserToken)
+
+	assert.NoError(t, service.VerifyEmail(context.Background(), models.EmailVerificationReq{Token: "good"}))
+	assert.ErrorIs(t, service.VerifyEmail(context.Background(), models.EmailVerificationReq{Token: "used"}), apperrors.ErrInvalidUserToken)
+}
+
+func TestResendEmailVerification(t *testing.T) {
+	verifiedAt := time.Now()
+	unverifiedID := uuid.New()
+
+	tests := []struct {
+		name         string
+		email        string
+		expectedMail bool
+	}{
+		{name: "Неподтверждённый email", email: "new@example.com", expectedMail: true},
+		{name: "Уже подтверждённый email", email: "old@example.com"},
+		{name: "Неизвестный email", email: "nobody@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, mail := new(MockRepo), &recordingMailer{}
+			service := newAuthTestService(mockRepo, mail)
+			mockRepo.On("GetUserByEmail", mock.Anything, "new@example.com").Return(models.User{ID: unverifiedID, Email: "new@example.com"}, nil).Maybe()
+			mockRepo.On("GetUserByEmail", mock.Anything, "old@example.com").Return(models.User{ID: uuid.New(), EmailVerifiedAt: &verifiedAt}, nil).Maybe()
+			mockRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return(models.User{}, apperrors.ErrEmailNotFound).Maybe()
+			mockRepo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(token models.UserToken) bool {
+				return token.UserID == unverifiedID && token.Purpose == models.UserTokenEmailVerification
+			})).Return(nil).Maybe()
+
+			err := service.ResendEmailVerification(context.Background(), models.ResendVerificationReq{Email: tt.email})
+
+			assert.NoError(t, err)
+			require.NoError(t, service.WaitBackground(context.Background()))
+			assert.Equal(t, tt.expectedMail, len(mail.sent) == 1)
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	mockRepo, mail := new(MockRepo), &recordingMailer{}
+	service := newAuthTestService(mockRepo, mail)
+	userID := uuid.New()
+
+	mockRepo.On("GetUserByEmail", mock.Anything, "user@example.com").Return(models.User{ID: userID, Email: "user@example.com"}, nil)
+	mockRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return(models.User{}, apperrors.ErrEmailNotFound)
+	var stored models.UserToken
+	mockRepo.On("CreateUserToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(models.UserToken)
+	}).Return(nil)
+
+	require.NoError(t, service.RequestPasswordReset(context.Background(), models.PasswordResetReq{Email: "nobody@example.com"}))
+	assert.Empty(t, mail.sent)
+
+	require.NoError(t, service.RequestPasswordReset(context.Background(), models.PasswordResetReq{Email: "user@example.com"}))
+	require.NoError(t, service.WaitBackground(context.Background()))
+	require.Len(t, mail.sent, 1)
+	assert.Equal(t, models.UserTokenPasswordReset, stored.Purpose)
+	assert.Equal(t, auth.HashUserToken(mailedToken(t, mail.sent[0])), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+}
+
+func TestRequestPasswordResetMailError(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := newAuthTestService(mockRepo, &recordingMailer{})
+	service.mailer = failingMailer{}
+
+	mockRepo.On("GetUserByEmail", mock.Anything, "user@example.com").Return(models.User{ID: uuid.New(), Email: "user@example.com"}, nil)
+	mockRepo.On("CreateUserToken", mock.Anything, mock.Anything).Return(nil)
+
+	err := service.RequestPasswordReset(context.Background(), models.PasswordResetReq{Email: "user@example.com"})
+
+	assert.NoError(t, err, "сбой отправки не отличает известный email от неизвестного")
+	require.NoError(t, service.WaitBackground(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResetPassword(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := newAuthTestService(mockRepo, &recordingMailer{})
+
+	mockRepo.On("ResetUserPassword", mock.Anything, auth.HashUserToken("code"), mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password1")) == nil
+	})).Return(uuid.New(), nil)
+
+	err := service.ResetPassword(context.Background(), models.PasswordResetConfirmReq{Token: "code", Password: "short"})
+	assert.ErrorIs(t, err, apperrors.ErrWeakPassword, "слабый пароль не тратит код")
+
+	err = service.ResetPassword(context.Background(), models.PasswordResetConfirmReq{Token: "code", Password: "new-password1"})
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "ResetUserPassword", 1)
+}
+
+func TestChangePassword(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		req           models.ChangePasswordReq
+		expectedError error
+	}{
+		{
+			name: "Пароль изменён",
+			req:  models.ChangePasswordReq{CurrentPassword: "password123", NewPassword: "new-password1"},
+		},
+		{
+			name:          "Неверный текущий пароль",
+			req:           models.ChangePasswordReq{CurrentPassword: "wrong", NewPassword: "new-password1"},
+			expectedError: apperrors.ErrInvalidCredentials,
+		},
+		{
+			name:          "Слабый новый пароль",
+			req:           models.ChangePasswordReq{CurrentPassword: "password123", NewPassword: "qwerty"},
+			expectedError: apperrors.ErrWeakPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			service := newAuthTestService(mockRepo, &recordingMailer{})
+			mockRepo.On("GetUserByID", mock.Anything, userID).Return(models.User{ID: userID, Password: hashOf(t, "password123")}, nil)
+			mockRepo.On("UpdateUserPassword", mock.Anything, userID, mock.Anything).Return(nil).Maybe()
+
+			err := service.ChangePassword(context.Background(), userID, tt.req)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				mockRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockRepo.AssertCalled(t, "UpdateUserPassword", mock.Anything, userID, mock.Anything)
+		})
+	}
+}
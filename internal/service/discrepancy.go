@@ -0,0 +1,69 @@
+package service
+
+import (
+	"github.com/kstsm/pvz-service/internal/repository"
+	"github.com/kstsm/pvz-service/models"
+)
+
+// reconcileManifest сверяет отсканированные товары с манифестом.
+// Повторно отсканированный штрихкод и товары без штрихкода считаются лишними.
+func reconcileManifest(manifest, scanned []models.ManifestItem) models.DiscrepancyReport {
+	report := models.DiscrepancyReport{
+		Missing:    []models.ManifestItem{},
+		Extra:      []models.ManifestItem{},
+		Mismatched: []models.TypeMismatch{},
+	}
+
+	expected := make(map[string]string, len(manifest))
+	for _, item := range manifest {
+		expected[item.Barcode] = item.Type
+	}
+
+	seen := make(map[string]bool, len(scanned))
+	for _, item := range scanned {
+		expectedType, ok := expected[item.Barcode]
+		if item.Barcode == "" || !ok || seen[item.Barcode] {
+			report.Extra = append(report.Extra, item)
+			continue
+		}
+		seen[item.Barcode] = true
+
+		if item.Type != expectedType {
+			report.Mismatched = append(report.Mismatched, models.TypeMismatch{
+				Barcode:      item.Barcode,
+				ExpectedType: expectedType,
+				ActualType:   item.Type,
+			})
+		}
+	}
+
+	for _, item := range manifest {
+		if !seen[item.Barcode] {
+			report.Missing = append(report.Missing, item)
+		}
+	}
+
+	return report
+}
+
+func hasDiscrepancies(report models.DiscrepancyReport) bool {
+	return len(report.Missing) > 0 || len(report.Extra) > 0 || len(report.Mismatched) > 0
+}
+
+// reconcileOnClose сверяет приёмку при обычном закрытии: с неподтверждёнными
+// расхождениями её закрыть нельзя.
+func reconcileOnClose(acknowledge bool) repository.ReconcileFunc {
+	return func(manifest, scanned []models.ManifestItem) (models.DiscrepancyReport, bool) {
+		report := reconcileManifest(manifest, scanned)
+		report.Acknowledged = acknowledge
+		return report, acknowledge || !hasDiscrepancies(report)
+	}
+}
+
+// reconcileOnForceClose сверяет приёмку при принудительном закрытии: модератор
+// закрывает её с причиной, и отчёт считается подтверждённым.
+func reconcileOnForceClose(manifest, scanned []models.ManifestItem) (models.DiscrepancyReport, bool) {
+	report := reconcileManifest(manifest, scanned)
+	report.Acknowledged = true
+	return report, true
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReconcileManifest(t *testing.T) {
+	manifest := []models.ManifestItem{
+		{Barcode: "A1", Type: "обувь"},
+		{Barcode: "B2", Type: "одежда"},
+		{Barcode: "C3", Type: "электроника"},
+	}
+
+	scanned := []models.ManifestItem{
+		{Barcode: "A1", Type: "обувь"},
+		{Barcode: "B2", Type: "обувь"},
+		{Barcode: "A1", Type: "обувь"},
+		{Barcode: "", Type: "одежда"},
+		{Barcode: "D4", Type: "одежда"},
+	}
+
+	report := reconcileManifest(manifest, scanned)
+
+	assert.Equal(t, []models.ManifestItem{{Barcode: "C3", Type: "электроника"}}, report.Missing)
+	assert.Equal(t, []models.ManifestItem{
+		{Barcode: "A1", Type: "обувь"},
+		{Barcode: "", Type: "одежда"},
+		{Barcode: "D4", Type: "одежда"},
+	}, report.Extra)
+	assert.Equal(t, []models.TypeMismatch{{Barcode: "B2", ExpectedType: "одежда", ActualType: "обувь"}}, report.Mismatched)
+}
+
+func TestReconcileOnClose(t *testing.T) {
+	manifest := []models.ManifestItem{{Barcode: "A1", Type: "обувь"}}
+
+	tests := []struct {
+		name        string
+		scanned     []models.ManifestItem
+		acknowledge bool
+		canClose    bool
+	}{
+		{"без расхождений", []models.ManifestItem{{Barcode: "A1", Type: "обувь"}}, false, true},
+		{"расхождения не подтверждены", nil, false, false},
+		{"расхождения подтверждены", nil, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, canClose := reconcileOnClose(tt.acknowledge)(manifest, tt.scanned)
+
+			assert.Equal(t, tt.canClose, canClose)
+			assert.Equal(t, tt.acknowledge, report.Acknowledged)
+		})
+	}
+}
@@ -3,41 +3,88 @@ package service
 import (
 	"context"
 	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/repository"
 	"github.com/kstsm/pvz-service/models"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type MockRepo struct {
 	mock.Mock
 }
 
-func (m *MockRepo) CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *MockRepo) CreateReception(ctx context.Context, pvzID uuid.UUID, manifest []models.ManifestItem) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, manifest)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
-func (m *MockRepo) AddProductToActiveReception(ctx context.Context, productType string, pvzID uuid.UUID) (models.Product, error) {
-	args := m.Called(ctx, productType, pvzID)
+func (m *MockRepo) AddProductToActiveReception(ctx context.Context, req models.AddProductRequest) (models.Product, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(models.Product), args.Error(1)
 }
 
-func (m *MockRepo) DeleteLastProductInReception(ctx context.Context, pvzID uuid.UUID) error {
-	args := m.Called(ctx, pvzID)
+func (m *MockRepo) DeleteLastProductInReception(ctx context.Context, pvzID, employeeID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, employeeID)
 	return args.Error(0)
 }
 
-func (m *MockRepo) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(ctx, pvzID)
+func (m *MockRepo) GetDeletedProducts(ctx context.Context, receptionID uuid.UUID) ([]models.DeletedProduct, error) {
+	args := m.Called(ctx, receptionID)
+	return args.Get(0).([]models.DeletedProduct), args.Error(1)
+}
+
+func (m *MockRepo) RestoreProduct(ctx context.Context, productID uuid.UUID) (models.Product, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockRepo) DecommissionPVZ(ctx context.Context, pvzID, moderatorID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, moderatorID)
+	return args.Error(0)
+}
+
+func (m *MockRepo) CloseLastReception(ctx context.Context, pvzID uuid.UUID, reconcile repository.ReconcileFunc) (models.Reception, error) {
+	args := m.Called(ctx, pvzID, reconcile)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
+func (m *MockRepo) GetDiscrepancyReport(ctx context.Context, receptionID uuid.UUID) (models.DiscrepancyReport, error) {
+	args := m.Called(ctx, receptionID)
+	return args.Get(0).(models.DiscrepancyReport), args.Error(1)
+}
+
 func (m *MockRepo) CreatePVZ(ctx context.Context, city string) (models.PVZ, error) {
 	args := m.Called(ctx, city)
 	return args.Get(0).(models.PVZ), args.Error(1)
 }
-func (m *MockRepo) GetPVZList(ctx context.Context, params models.PVZFilterParams) ([]models.PVZWithReceptions, error) {
+func (m *MockRepo) GetPVZByID(ctx context.Context, pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockRepo) UpdatePVZ(ctx context.Context, pvzID uuid.UUID, req models.UpdatePVZRequest) (models.PVZ, error) {
+	args := m.Called(ctx, pvzID, req)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockRepo) EnsureDummyUser(ctx context.Context, email, role string) (uuid.UUID, error) {
+	args := m.Called(ctx, email, role)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepo) ImportPVZ(ctx context.Context, items []models.PVZImportItem) ([]models.PVZ, error) {
+	args := m.Called(ctx, items)
+	return args.Get(0).([]models.PVZ), args.Error(1)
+}
+
+func (m *MockRepo) GetPVZList(ctx context.Context, params models.PVZFilterParams) (models.PVZPage, error) {
 	args := m.Called(ctx, params)
-	return args.Get(0).([]models.PVZWithReceptions), args.Error(1)
+	return args.Get(0).(models.PVZPage), args.Error(1)
+}
+
+func (m *MockRepo) GetReceptionDailyStats(ctx context.Context, pvzID uuid.UUID, startDay, endDay string) ([]models.DailyReceptionStats, error) {
+	args := m.Called(ctx, pvzID, startDay, endDay)
+	return args.Get(0).([]models.DailyReceptionStats), args.Error(1)
 }
 
 func (m *MockRepo) CheckEmailExists(ctx context.Context, email string) (bool, error) {
@@ -46,11 +93,156 @@ func (m *MockRepo) CheckEmailExists(ctx context.Context, email string) (bool, er
 }
 
 func (m *MockRepo) CreateUser(ctx context.Context, user models.UserRegisterReq) (uuid.UUID, error) {
-	//TODO implement me
-	panic("implement me")
+	args := m.Called(ctx, user)
+	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockRepo) GetRoleByEmail(ctx context.Context, email string) (string, string, error) {
-	//TODO implement me
-	panic("implement me")
+func (m *MockRepo) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockRepo) GetNearbyPVZ(ctx context.Context, params models.NearbyPVZParams) ([]models.PVZWithDistance, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]models.PVZWithDistance), args.Error(1)
+}
+
+func (m *MockRepo) LinkParcel(ctx context.Context, clientID uuid.UUID, barcode, pickupCodeHash string) (models.Parcel, error) {
+	args := m.Called(ctx, clientID, barcode, pickupCodeHash)
+	return args.Get(0).(models.Parcel), args.Error(1)
+}
+
+func (m *MockRepo) GetClientParcels(ctx context.Context, clientID uuid.UUID) ([]models.Parcel, error) {
+	args := m.Called(ctx, clientID)
+	return args.Get(0).([]models.Parcel), args.Error(1)
+}
+
+func (m *MockRepo) ReleaseProduct(ctx context.Context, productID uuid.UUID, status string, employeeID uuid.UUID) (models.Product, error) {
+	args := m.Called(ctx, productID, status, employeeID)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockRepo) GetPVZStock(ctx context.Context, pvzID uuid.UUID, condition string) ([]models.Product, error) {
+	args := m.Called(ctx, pvzID, condition)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockRepo) GetProductPVZID(ctx context.Context, productID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepo) GetReceptionByID(ctx context.Context, receptionID uuid.UUID) (models.Reception, error) {
+	args := m.Called(ctx, receptionID)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockRepo) GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, condition string) ([]models.Product, error) {
+	args := m.Called(ctx, receptionID, condition)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockRepo) AddProductAttachments(ctx context.Context, attachments []models.ProductAttachment) ([]models.ProductAttachment, error) {
+	args := m.Called(ctx, attachments)
+	return args.Get(0).([]models.ProductAttachment), args.Error(1)
+}
+
+func (m *MockRepo) GetProductAttachments(ctx context.Context, productID uuid.UUID) ([]models.ProductAttachment, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]models.ProductAttachment), args.Error(1)
+}
+
+func (m *MockRepo) GetProductAttachment(ctx context.Context, productID, attachmentID uuid.UUID) (models.ProductAttachment, error) {
+	args := m.Called(ctx, productID, attachmentID)
+	return args.Get(0).(models.ProductAttachment), args.Error(1)
+}
+
+func (m *MockRepo) GetExpiredProducts(ctx context.Context, pvzID uuid.UUID) ([]models.Product, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockRepo) ExpireProducts(ctx context.Context, storagePeriods map[string]time.Duration) ([]models.ExpiredProduct, error) {
+	args := m.Called(ctx, storagePeriods)
+	return args.Get(0).([]models.ExpiredProduct), args.Error(1)
+}
+
+func (m *MockRepo) ForceCloseReception(ctx context.Context, receptionID uuid.UUID, status, reason string, moderatorID uuid.UUID, reconcile repository.ReconcileFunc) (models.Reception, error) {
+	args := m.Called(ctx, receptionID, status, reason, moderatorID, reconcile)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockRepo) GetStaleReceptions(ctx context.Context, maxDuration time.Duration, employeeID *uuid.UUID) ([]models.Reception, error) {
+	args := m.Called(ctx, maxDuration, employeeID)
+	return args.Get(0).([]models.Reception), args.Error(1)
+}
+
+func (m *MockRepo) ReopenReception(ctx context.Context, receptionID uuid.UUID, gracePeriod time.Duration, reason string, moderatorID uuid.UUID) (models.Reception, error) {
+	args := m.Called(ctx, receptionID, gracePeriod, reason, moderatorID)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
+func (m *MockRepo) FlagStaleReceptions(ctx context.Context, maxDuration time.Duration) ([]models.Reception, error) {
+	args := m.Called(ctx, maxDuration)
+	return args.Get(0).([]models.Reception), args.Error(1)
+}
+
+func (m *MockRepo) AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepo) UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepo) IsEmployeeAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, pvzID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepo) GetPVZTimezones(ctx context.Context, pvzIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	args := m.Called(ctx, pvzIDs)
+	return args.Get(0).(map[uuid.UUID]string), args.Error(1)
+}
+
+func (m *MockRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (models.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockRepo) UpdateUserPassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	args := m.Called(ctx, userID, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockRepo) MarkUserEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepo) CreateUserToken(ctx context.Context, token models.UserToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRepo) VerifyUserEmail(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepo) ResetUserPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	args := m.Called(ctx, tokenHash, passwordHash)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepo) Search(ctx context.Context, params models.SearchParams) (models.SearchResult, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(models.SearchResult), args.Error(1)
+}
+
+func (m *MockRepo) GetTenantBySlug(ctx context.Context, slug string) (models.Tenant, error) {
+	args := m.Called(ctx, slug)
+	return args.Get(0).(models.Tenant), args.Error(1)
 }
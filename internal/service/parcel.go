@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/models"
+)
+
+// LinkParcel привязывает посылку к клиенту. Состояние посылки и её ПВЗ клиент
+// увидит, только если код получения совпадёт с кодом из манифеста курьера.
+func (s Service) LinkParcel(ctx context.Context, clientID uuid.UUID, req models.LinkParcelRequest) (models.Parcel, error) {
+	parcel, err := s.repo.LinkParcel(ctx, clientID, req.Barcode, hashPickupCode(req.PickupCode))
+	if err != nil {
+		return models.Parcel{}, err
+	}
+
+	// Товар мог поступить в ПВЗ ещё до привязки, поэтому состояние
+	// берётся из того же запроса, что и для списка посылок.
+	parcels, err := s.GetClientParcels(ctx, clientID)
+	if err != nil {
+		return models.Parcel{}, err
+	}
+	for _, linked := range parcels {
+		if linked.ID == parcel.ID {
+			return linked, nil
+		}
+	}
+
+	parcel.Status = models.ParcelStatusExpected
+	return parcel, nil
+}
+
+func (s Service) GetClientParcels(ctx context.Context, clientID uuid.UUID) ([]models.Parcel, error) {
+	parcels, err := s.repo.GetClientParcels(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range parcels {
+		parcels[i].Status = parcelStatus(parcels[i])
+		localizeParcel(&parcels[i])
+	}
+
+	return parcels, nil
+}
+
+// parcelStatus определяет состояние посылки по товару и приёмке, в которую он попал.
+// Посылка готова к выдаче, когда её приёмка закрыта, а товар ещё на полке.
+func parcelStatus(parcel models.Parcel) string {
+	switch {
+	case parcel.ReceptionStatus == nil:
+		return models.ParcelStatusExpected
+	case parcel.ProductStatus != nil && *parcel.ProductStatus == models.ProductStatusIssued:
+		return models.ParcelStatusIssued
+	case parcel.ProductStatus != nil && *parcel.ProductStatus == models.ProductStatusReturned:
+		return models.ParcelStatusReturned
+	case parcel.ProductStatus != nil && *parcel.ProductStatus == models.ProductStatusExpired:
+		return models.ParcelStatusExpired
+	case *parcel.ReceptionStatus == "close":
+		return models.ParcelStatusReadyForPickup
+	default:
+		return models.ParcelStatusReceiving
+	}
+}
+
+// hashPickupCode возвращает SHA-256 кода получения: в базе коды хранятся только так.
+func hashPickupCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestGetClientParcels(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := Service{repo: mockRepo}
+
+	clientID := uuid.New()
+	inProgress, closed := "in_progress", "close"
+
+	mockRepo.On("GetClientParcels", mock.Anything, clientID).Return([]models.Parcel{
+		{Barcode: "AWAITING1"},
+		{Barcode: "RECEIVING1", ReceptionStatus: &inProgress},
+		{Barcode: "READY0001", ReceptionStatus: &closed},
+	}, nil)
+
+	parcels, err := service.GetClientParcels(context.Background(), clientID)
+
+	assert.NoError(t, err)
+	assert.Len(t, parcels, 3)
+	assert.Equal(t, models.ParcelStatusExpected, parcels[0].Status)
+	assert.Equal(t, models.ParcelStatusReceiving, parcels[1].Status)
+	assert.Equal(t, models.ParcelStatusReadyForPickup, parcels[2].Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLinkParcel(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := Service{repo: mockRepo}
+
+	clientID := uuid.New()
+	parcelID := uuid.New()
+	closed := "close"
+	mockRepo.On("LinkParcel", mock.Anything, clientID, "RU123456789", hashPickupCode("483920")).
+		Return(models.Parcel{ID: parcelID, Barcode: "RU123456789"}, nil)
+	mockRepo.On("GetClientParcels", mock.Anything, clientID).Return([]models.Parcel{
+		{ID: uuid.New(), Barcode: "OTHER0001"},
+		{ID: parcelID, Barcode: "RU123456789", ReceptionStatus: &closed},
+	}, nil)
+
+	parcel, err := service.LinkParcel(context.Background(), clientID,
+		models.LinkParcelRequest{Barcode: "RU123456789", PickupCode: "483920"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, parcelID, parcel.ID)
+	assert.Equal(t, models.ParcelStatusReadyForPickup, parcel.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestParcelStatus(t *testing.T) {
+	closed := "close"
+	issued := models.ProductStatusIssued
+	returned := models.ProductStatusReturned
+	received := models.ProductStatusReceived
+
+	assert.Equal(t, models.ParcelStatusReadyForPickup, parcelStatus(models.Parcel{ReceptionStatus: &closed, ProductStatus: &received}))
+	assert.Equal(t, models.ParcelStatusIssued, parcelStatus(models.Parcel{ReceptionStatus: &closed, ProductStatus: &issued}))
+	assert.Equal(t, models.ParcelStatusReturned, parcelStatus(models.Parcel{ReceptionStatus: &closed, ProductStatus: &returned}))
+}
+
+func TestHashPickupCodes(t *testing.T) {
+	manifest := []models.ManifestItem{
+		{Barcode: "RU123456789", Type: "обувь", PickupCode: "483920"},
+		{Barcode: "RU987654321", Type: "одежда"},
+	}
+
+	hashed := hashPickupCodes(manifest)
+
+	assert.Equal(t, hashPickupCode("483920"), hashed[0].PickupCodeHash)
+	assert.Empty(t, hashed[0].PickupCode)
+	assert.Empty(t, hashed[1].PickupCodeHash)
+	assert.Equal(t, "483920", manifest[0].PickupCode, "исходный манифест не меняется")
+	assert.Len(t, hashPickupCode("483920"), 64)
+}
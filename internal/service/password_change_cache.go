@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+// Момент смены пароля проверяется на каждом запросе с токеном, поэтому он
+// кэшируется по пользователю. Пустое значение означает, что пароль ещё не
+// менялся. Смена пароля через сервис удаляет запись, а смена в обход кэша
+// (например, из другого процесса с кэшем в памяти) видна не позже TTL.
+const passwordChangeKeyPrefix = "password-changed-at"
+
+func passwordChangeKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", passwordChangeKeyPrefix, userID)
+}
+
+// passwordChangedAt возвращает момент последней смены пароля пользователя
+// или nil, если пароль не менялся.
+func (s Service) passwordChangedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	if s.passwordChanges == nil {
+		return s.loadPasswordChangedAt(ctx, userID)
+	}
+
+	key := passwordChangeKey(userID)
+	data, ok, err := s.passwordChanges.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "Ошибка чтения кэша смены пароля", "key", key, "error", err)
+	}
+	if ok {
+		if len(data) == 0 {
+			return nil, nil
+		}
+		changedAt, err := time.Parse(time.RFC3339Nano, string(data))
+		if err == nil {
+			return &changedAt, nil
+		}
+		slog.WarnContext(ctx, "Повреждённая запись в кэше смены пароля", "key", key, "error", err)
+	}
+
+	changedAt, err := s.loadPasswordChangedAt(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data = nil
+	if changedAt != nil {
+		data = []byte(changedAt.Format(time.RFC3339Nano))
+	}
+	if err = s.passwordChanges.Set(ctx, key, data, s.passwordChangeTTL); err != nil {
+		slog.WarnContext(ctx, "Ошибка записи в кэш смены пароля", "key", key, "error", err)
+	}
+
+	return changedAt, nil
+}
+
+func (s Service) loadPasswordChangedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.PasswordChangedAt, nil
+}
+
+// forgetPasswordChange сбрасывает закэшированный момент смены пароля, чтобы
+// прежние токены отклонялись сразу, а не после истечения TTL.
+func (s Service) forgetPasswordChange(ctx context.Context, userID uuid.UUID) {
+	if s.passwordChanges == nil {
+		return
+	}
+
+	key := passwordChangeKey(userID)
+	if err := s.passwordChanges.Delete(ctx, key); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сброса кэша смены пароля", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+)
+
+func (s Service) IssueProduct(ctx context.Context, productID uuid.UUID, employeeID uuid.UUID) (models.Product, error) {
+	return s.releaseProduct(ctx, productID, models.ProductStatusIssued, employeeID)
+}
+
+func (s Service) ReturnProduct(ctx context.Context, productID uuid.UUID, employeeID uuid.UUID) (models.Product, error) {
+	return s.releaseProduct(ctx, productID, models.ProductStatusReturned, employeeID)
+}
+
+func (s Service) releaseProduct(ctx context.Context, productID uuid.UUID, status string, employeeID uuid.UUID) (models.Product, error) {
+	product, err := s.repo.ReleaseProduct(ctx, productID, status, employeeID)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizeProduct(&product, s.productLocation(ctx, productID))
+
+	return product, nil
+}
+
+// GetPVZStock возвращает товары на полках ПВЗ; непустой condition оставляет
+// только товары в этом состоянии. Непустой employeeID ограничивает доступ
+// ПВЗ, за которыми закреплён сотрудник.
+func (s Service) GetPVZStock(ctx context.Context, pvzID uuid.UUID, condition string, employeeID *uuid.UUID) (models.PVZStock, error) {
+	pvz, err := s.repo.GetPVZByID(ctx, pvzID)
+	if err != nil {
+		return models.PVZStock{}, err
+	}
+
+	if err = s.checkEmployeeScope(ctx, pvzID, employeeID); err != nil {
+		return models.PVZStock{}, err
+	}
+
+	products, err := s.repo.GetPVZStock(ctx, pvzID, condition)
+	if err != nil {
+		return models.PVZStock{}, err
+	}
+
+	loc, _ := pvzLocation(pvz)
+	for i := range products {
+		localizeProduct(&products[i], loc)
+	}
+
+	stock := models.PVZStock{
+		PVZID:        pvzID,
+		Total:        len(products),
+		CountsByType: make(map[string]int),
+		Products:     products,
+	}
+	for _, product := range products {
+		stock.CountsByType[product.Type]++
+		switch product.Condition {
+		case models.ProductConditionDamaged:
+			stock.Damaged++
+		case models.ProductConditionOpened:
+			stock.Opened++
+		}
+	}
+
+	return stock, nil
+}
+
+func (s Service) GetExpiredProducts(ctx context.Context, pvzID uuid.UUID, employeeID *uuid.UUID) ([]models.Product, error) {
+	pvz, err := s.repo.GetPVZByID(ctx, pvzID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.checkEmployeeScope(ctx, pvzID, employeeID); err != nil {
+		return nil, err
+	}
+
+	products, err := s.repo.GetExpiredProducts(ctx, pvzID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, _ := pvzLocation(pvz)
+	for i := range products {
+		localizeProduct(&products[i], loc)
+	}
+
+	return products, nil
+}
+
+// ExpireProducts помечает товары с истёкшим сроком хранения и публикует событие
+// по каждому из них. Возвращает количество обработанных товаров.
+func (s Service) ExpireProducts(ctx context.Context) (int, error) {
+	if len(s.storagePeriods) == 0 {
+		return 0, nil
+	}
+
+	expired, err := s.repo.ExpireProducts(ctx, s.storagePeriods)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrLockNotAcquired) {
+			slog.DebugContext(ctx, "Истечение сроков хранения выполняет другой экземпляр сервиса")
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	for _, product := range expired {
+		event := models.Event{
+			Type:       models.EventProductExpired,
+			OccurredAt: *product.ExpiredAt,
+			Payload:    product,
+		}
+		if err = s.notifier.Publish(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "Ошибка публикации события", "type", event.Type, "productId", product.ID, "error", err)
+		}
+	}
+
+	if len(expired) > 0 {
+		s.invalidatePVZList(ctx)
+		slog.InfoContext(ctx, "Истёк срок хранения товаров", "count", len(expired))
+	}
+
+	return len(expired), nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestIssueAndReturnProduct(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := Service{repo: mockRepo}
+	allowPVZTimezones(mockRepo, nil)
+
+	productID, employeeID := uuid.New(), uuid.New()
+	mockRepo.On("GetProductPVZID", mock.Anything, productID).Return(uuid.New(), nil)
+	mockRepo.On("ReleaseProduct", mock.Anything, productID, models.ProductStatusIssued, employeeID).
+		Return(models.Product{ID: productID, Status: models.ProductStatusIssued}, nil)
+	mockRepo.On("ReleaseProduct", mock.Anything, productID, models.ProductStatusReturned, employeeID).
+		Return(models.Product{}, apperrors.ErrProductAlreadyReleased)
+
+	product, err := service.IssueProduct(context.Background(), productID, employeeID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProductStatusIssued, product.Status)
+
+	_, err = service.ReturnProduct(context.Background(), productID, employeeID)
+	assert.ErrorIs(t, err, apperrors.ErrProductAlreadyReleased)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetPVZStock(t *testing.T) {
+	t.Run("подсчёт по типам", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := Service{repo: mockRepo}
+
+		pvzID := uuid.New()
+		mockRepo.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{ID: pvzID}, nil)
+		mockRepo.On("GetPVZStock", mock.Anything, pvzID, "").Return([]models.Product{
+			{Type: "обувь", Condition: models.ProductConditionOK},
+			{Type: "одежда", Condition: models.ProductConditionDamaged},
+			{Type: "обувь", Condition: models.ProductConditionOpened},
+		}, nil)
+
+		stock, err := service.GetPVZStock(context.Background(), pvzID, "", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, stock.Total)
+		assert.Equal(t, map[string]int{"обувь": 2, "одежда": 1}, stock.CountsByType)
+		assert.Equal(t, 1, stock.Damaged)
+		assert.Equal(t, 1, stock.Opened)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ПВЗ не найден", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := Service{repo: mockRepo}
+
+		pvzID := uuid.New()
+		mockRepo.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{}, apperrors.ErrPVZNotFound)
+
+		_, err := service.GetPVZStock(context.Background(), pvzID, "", nil)
+
+		assert.ErrorIs(t, err, apperrors.ErrPVZNotFound)
+		mockRepo.AssertNotCalled(t, "GetPVZStock", mock.Anything, pvzID, mock.Anything)
+	})
+
+	t.Run("сотрудник закреплён за ПВЗ", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := Service{repo: mockRepo}
+
+		pvzID, employeeID := uuid.New(), uuid.New()
+		mockRepo.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{ID: pvzID}, nil)
+		mockRepo.On("IsEmployeeAssigned", mock.Anything, pvzID, employeeID).Return(true, nil)
+		mockRepo.On("GetPVZStock", mock.Anything, pvzID, "").Return([]models.Product{}, nil)
+
+		stock, err := service.GetPVZStock(context.Background(), pvzID, "", &employeeID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, stock.Total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("сотрудник не закреплён за ПВЗ", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := Service{repo: mockRepo}
+
+		pvzID, employeeID := uuid.New(), uuid.New()
+		mockRepo.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{ID: pvzID}, nil)
+		mockRepo.On("IsEmployeeAssigned", mock.Anything, pvzID, employeeID).Return(false, nil)
+
+		_, err := service.GetPVZStock(context.Background(), pvzID, "", &employeeID)
+
+		assert.ErrorIs(t, err, apperrors.ErrEmployeeNotAssigned)
+		mockRepo.AssertNotCalled(t, "GetPVZStock", mock.Anything, pvzID, mock.Anything)
+	})
+}
+
+func TestEmployeeScopedReads(t *testing.T) {
+	pvzID, receptionID, employeeID := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name string
+		call func(s Service) error
+	}{
+		{
+			name: "Просроченные товары",
+			call: func(s Service) error {
+				_, err := s.GetExpiredProducts(context.Background(), pvzID, &employeeID)
+				return err
+			},
+		},
+		{
+			name: "Дневной отчёт ПВЗ",
+			call: func(s Service) error {
+				_, err := s.GetPVZDailyReport(context.Background(), pvzID, "2026-10-01", "2026-10-01", &employeeID)
+				return err
+			},
+		},
+		{
+			name: "Приёмка",
+			call: func(s Service) error {
+				_, err := s.GetReception(context.Background(), receptionID, "", &employeeID)
+				return err
+			},
+		},
+		{
+			name: "Отчёт о расхождениях",
+			call: func(s Service) error {
+				_, err := s.GetDiscrepancyReport(context.Background(), receptionID, &employeeID)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			service := Service{repo: mockRepo}
+			mockRepo.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{ID: pvzID}, nil).Maybe()
+			mockRepo.On("GetReceptionByID", mock.Anything, receptionID).Return(models.Reception{ID: receptionID, PVZID: pvzID}, nil).Maybe()
+			mockRepo.On("GetDiscrepancyReport", mock.Anything, receptionID).Return(models.DiscrepancyReport{}, nil).Maybe()
+			mockRepo.On("IsEmployeeAssigned", mock.Anything, pvzID, employeeID).Return(false, nil)
+
+			err := tt.call(service)
+
+			assert.ErrorIs(t, err, apperrors.ErrEmployeeNotAssigned)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+type recordingNotifier struct {
+	events []models.Event
+}
+
+func (n *recordingNotifier) Publish(ctx context.Context, event models.Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestExpireProducts(t *testing.T) {
+	periods := map[string]time.Duration{"обувь": 72 * time.Hour}
+
+	t.Run("публикация события по каждому товару", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		events := &recordingNotifier{}
+		service := NewService(mockRepo, WithNotifier(events), WithStoragePeriods(periods))
+
+		expiredAt := time.Now()
+		mockRepo.On("ExpireProducts", mock.Anything, periods).Return([]models.ExpiredProduct{
+			{Product: models.Product{ID: uuid.New(), ExpiredAt: &expiredAt}, PVZID: uuid.New()},
+			{Product: models.Product{ID: uuid.New(), ExpiredAt: &expiredAt}, PVZID: uuid.New()},
+		}, nil)
+
+		count, err := service.ExpireProducts(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Len(t, events.events, 2)
+		assert.Equal(t, models.EventProductExpired, events.events[0].Type)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("блокировка занята другой репликой", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		events := &recordingNotifier{}
+		service := NewService(mockRepo, WithNotifier(events), WithStoragePeriods(periods))
+
+		mockRepo.On("ExpireProducts", mock.Anything, periods).
+			Return([]models.ExpiredProduct(nil), apperrors.ErrLockNotAcquired)
+
+		count, err := service.ExpireProducts(context.Background())
+
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		assert.Empty(t, events.events)
+	})
+
+	t.Run("сроки хранения не заданы", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := NewService(mockRepo)
+
+		count, err := service.ExpireProducts(context.Background())
+
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		mockRepo.AssertNotCalled(t, "ExpireProducts", mock.Anything, mock.Anything)
+	})
+}
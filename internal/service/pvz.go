@@ -2,13 +2,251 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"time"
 )
 
 func (s Service) CreatePVZ(ctx context.Context, city string) (models.PVZ, error) {
-	return s.repo.CreatePVZ(ctx, city)
+	pvz, err := s.repo.CreatePVZ(ctx, city)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizePVZ(&pvz)
+
+	return pvz, nil
+}
+
+func (s Service) GetPVZByID(ctx context.Context, pvzID uuid.UUID) (models.PVZ, error) {
+	pvz, err := s.repo.GetPVZByID(ctx, pvzID)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+
+	localizePVZ(&pvz)
+
+	return pvz, nil
+}
+
+func (s Service) UpdatePVZ(ctx context.Context, pvzID uuid.UUID, req models.UpdatePVZRequest) (models.PVZ, error) {
+	pvz, err := s.repo.UpdatePVZ(ctx, pvzID, req)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizePVZ(&pvz)
+
+	return pvz, nil
+}
+
+// ImportPVZ заводит ПВЗ из файла импорта целиком или не заводит ни одного.
+// Используется администратором из командной строки.
+func (s Service) ImportPVZ(ctx context.Context, items []models.PVZImportItem) ([]models.PVZ, error) {
+	imported, err := s.repo.ImportPVZ(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidatePVZList(ctx)
+	for i := range imported {
+		localizePVZ(&imported[i])
+	}
+
+	return imported, nil
+}
+
+func (s Service) DecommissionPVZ(ctx context.Context, pvzID, moderatorID uuid.UUID) error {
+	if err := s.repo.DecommissionPVZ(ctx, pvzID, moderatorID); err != nil {
+		return err
+	}
+
+	s.invalidatePVZList(ctx)
+	slog.InfoContext(ctx, "ПВЗ выведен из эксплуатации", "pvzId", pvzID, "moderatorId", moderatorID)
+
+	return nil
+}
+
+// AssignEmployee закрепляет сотрудника за ПВЗ. Закрепить можно только
+// пользователя с ролью employee за действующим ПВЗ.
+func (s Service) AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	if _, err := s.repo.GetPVZByID(ctx, pvzID); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Role != "employee" {
+		return apperrors.ErrUserNotEmployee
+	}
+
+	return s.repo.AssignEmployee(ctx, pvzID, userID)
+}
+
+func (s Service) UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	return s.repo.UnassignEmployee(ctx, pvzID, userID)
+}
+
+// checkEmployeeAssigned возвращает ErrEmployeeNotAssigned, если сотрудник не
+// закреплён за ПВЗ.
+func (s Service) checkEmployeeAssigned(ctx context.Context, pvzID, employeeID uuid.UUID) error {
+	assigned, err := s.repo.IsEmployeeAssigned(ctx, pvzID, employeeID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		slog.WarnContext(ctx, "Сотрудник не закреплён за ПВЗ", "pvzId", pvzID, "employeeId", employeeID)
+		return apperrors.ErrEmployeeNotAssigned
+	}
+
+	return nil
 }
 
-func (s Service) GetPVZList(ctx context.Context, params models.PVZFilterParams) ([]models.PVZWithReceptions, error) {
-	return s.repo.GetPVZList(ctx, params)
+// checkEmployeeScope проверяет закрепление за ПВЗ, если запрос делает
+// сотрудник; для остальных ролей employeeID равен nil.
+func (s Service) checkEmployeeScope(ctx context.Context, pvzID uuid.UUID, employeeID *uuid.UUID) error {
+	if employeeID == nil {
+		return nil
+	}
+
+	return s.checkEmployeeAssigned(ctx, pvzID, *employeeID)
+}
+
+func (s Service) GetPVZList(ctx context.Context, params models.PVZFilterParams) (models.PVZPage, error) {
+	var page models.PVZPage
+	var err error
+	if s.pvzListCache == nil {
+		page, err = s.repo.GetPVZList(ctx, params)
+	} else {
+		page, err = s.cachedPVZList(ctx, params)
+	}
+	if err != nil {
+		return models.PVZPage{}, err
+	}
+
+	for i := range page.Items {
+		localizePVZWithReceptions(&page.Items[i])
+	}
+
+	return page, nil
+}
+
+// GetPVZDailyReport считает приёмки ПВЗ по дням его часового пояса с startDay
+// по endDay включительно, в формате models.DateLayout.
+func (s Service) GetPVZDailyReport(ctx context.Context, pvzID uuid.UUID, startDay, endDay string, employeeID *uuid.UUID) (models.PVZDailyReport, error) {
+	start, err := time.Parse(models.DateLayout, startDay)
+	if err != nil {
+		return models.PVZDailyReport{}, fmt.Errorf("некорректная дата начала отчёта: %w", err)
+	}
+	end, err := time.Parse(models.DateLayout, endDay)
+	if err != nil {
+		return models.PVZDailyReport{}, fmt.Errorf("некорректная дата конца отчёта: %w", err)
+	}
+
+	pvz, err := s.repo.GetPVZByID(ctx, pvzID)
+	if err != nil {
+		return models.PVZDailyReport{}, err
+	}
+
+	if err = s.checkEmployeeScope(ctx, pvzID, employeeID); err != nil {
+		return models.PVZDailyReport{}, err
+	}
+
+	stats, err := s.repo.GetReceptionDailyStats(ctx, pvzID, startDay, endDay)
+	if err != nil {
+		return models.PVZDailyReport{}, err
+	}
+	byDay := make(map[string]models.DailyReceptionStats, len(stats))
+	for _, day := range stats {
+		byDay[day.Date] = day
+	}
+
+	report := models.PVZDailyReport{
+		PVZID:     pvz.ID,
+		Timezone:  pvz.Timezone,
+		StartDate: startDay,
+		EndDate:   endDay,
+		Days:      []models.DailyReceptionStats{},
+	}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(models.DateLayout)
+		stat, ok := byDay[date]
+		if !ok {
+			stat = models.DailyReceptionStats{Date: date}
+		}
+		report.Days = append(report.Days, stat)
+	}
+
+	return report, nil
+}
+
+func (s Service) GetNearbyPVZ(ctx context.Context, params models.NearbyPVZParams) ([]models.PVZWithDistance, error) {
+	pvzList, err := s.getNearbyPVZ(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pvzList {
+		localizePVZ(&pvzList[i].PVZ)
+	}
+
+	return pvzList, nil
+}
+
+func (s Service) getNearbyPVZ(ctx context.Context, params models.NearbyPVZParams) ([]models.PVZWithDistance, error) {
+	if !params.OpenNow {
+		return s.repo.GetNearbyPVZ(ctx, params)
+	}
+
+	// Часы работы хранятся в JSONB и зависят от часового пояса ПВЗ,
+	// поэтому фильтр "открыт сейчас" применяется после выборки по радиусу.
+	query := params
+	query.Limit = 0
+	candidates, err := s.repo.GetNearbyPVZ(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pvzList := []models.PVZWithDistance{}
+	for _, pvz := range candidates {
+		if !isPVZOpenAt(pvz.PVZ, now) {
+			continue
+		}
+		pvzList = append(pvzList, pvz)
+		if params.Limit > 0 && len(pvzList) == params.Limit {
+			break
+		}
+	}
+
+	return pvzList, nil
+}
+
+// isPVZOpenAt сообщает, работает ли активный ПВЗ в момент t по своему местному времени.
+func isPVZOpenAt(pvz models.PVZ, t time.Time) bool {
+	if pvz.Status != models.PVZStatusActive {
+		return false
+	}
+
+	loc, ok := pvzLocation(pvz)
+	if !ok {
+		return false
+	}
+
+	local := t.In(loc)
+	clock := local.Format("15:04")
+	for _, wh := range pvz.WorkingHours {
+		if wh.Weekday == int(local.Weekday()) && clock >= wh.Open && clock < wh.Close {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/repository"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"time"
+)
+
+// Ключи списка ПВЗ включают версию: общую и версию арендатора. Изменение
+// данных не удаляет записи, а меняет версию, и старые записи перестают
+// читаться и доживают до истечения TTL. Общая версия меняется фоновыми
+// задачами, которые затрагивают данные всех арендаторов.
+const (
+	pvzListGlobalVersionKey = "pvz-list:version"
+	pvzListKeyPrefix        = "pvz-list"
+)
+
+func pvzListTenantVersionKey(tenantID uuid.UUID) string {
+	return fmt.Sprintf("%s:version:%s", pvzListKeyPrefix, tenantID)
+}
+
+func (s Service) cachedPVZList(ctx context.Context, params models.PVZFilterParams) (models.PVZPage, error) {
+	key, err := s.pvzListKey(ctx, params)
+	if err != nil {
+		slog.WarnContext(ctx, "Кэш списка ПВЗ недоступен", "error", err)
+		return s.repo.GetPVZList(ctx, params)
+	}
+
+	data, ok, err := s.pvzListCache.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "Ошибка чтения кэша списка ПВЗ", "key", key, "error", err)
+	}
+	if ok {
+		var page models.PVZPage
+		if err = json.Unmarshal(data, &page); err == nil {
+			return page, nil
+		}
+		slog.WarnContext(ctx, "Повреждённая запись в кэше списка ПВЗ", "key", key, "error", err)
+	}
+
+	// Промах после смены версии означает свежую запись, которую реплика
+	// могла ещё не получить. Запись в кэш живёт до TTL, поэтому данные для
+	// неё читаются из основной базы.
+	page, err := s.repo.GetPVZList(repository.ReadFromPrimary(ctx), params)
+	if err != nil {
+		return models.PVZPage{}, err
+	}
+
+	if data, err = json.Marshal(page); err != nil {
+		slog.ErrorContext(ctx, "Ошибка сериализации списка ПВЗ", "error", err)
+		return page, nil
+	}
+	if err = s.pvzListCache.Set(ctx, key, data, s.pvzListTTL); err != nil {
+		slog.WarnContext(ctx, "Ошибка записи в кэш списка ПВЗ", "key", key, "error", err)
+	}
+
+	return page, nil
+}
+
+func (s Service) pvzListKey(ctx context.Context, params models.PVZFilterParams) (string, error) {
+	globalVersion, err := s.pvzListVersion(ctx, pvzListGlobalVersionKey)
+	if err != nil {
+		return "", err
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	tenantVersion, err := s.pvzListVersion(ctx, pvzListTenantVersionKey(tenantID))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:page:%s:%s:%s:%s:%s:%s:%s:%d:%d", pvzListKeyPrefix, globalVersion, tenantID, tenantVersion,
+		formatFilterDate(params.StartDate), formatFilterDate(params.EndDate), params.StartDay, params.EndDay,
+		params.Page, params.Limit), nil
+}
+
+// pvzListVersion читает версию по ключу и заводит новую, если её ещё нет.
+func (s Service) pvzListVersion(ctx context.Context, key string) (string, error) {
+	version, ok, err := s.pvzListVersions.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(version), nil
+	}
+
+	return s.bumpPVZListVersion(ctx, key)
+}
+
+func (s Service) bumpPVZListVersion(ctx context.Context, key string) (string, error) {
+	version := uuid.NewString()
+	if err := s.pvzListVersions.Set(ctx, key, []byte(version), 0); err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// invalidatePVZList сбрасывает кэш списка ПВЗ арендатора из контекста,
+// а для контекста без арендатора — кэш всех арендаторов.
+func (s Service) invalidatePVZList(ctx context.Context) {
+	if s.pvzListCache == nil {
+		return
+	}
+
+	key := pvzListGlobalVersionKey
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		key = pvzListTenantVersionKey(tenantID)
+	}
+
+	if _, err := s.bumpPVZListVersion(ctx, key); err != nil {
+		slog.ErrorContext(ctx, "Не удалось сбросить кэш списка ПВЗ", "key", key, "error", err)
+	}
+}
+
+func formatFilterDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
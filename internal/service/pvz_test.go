@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/cache"
+	"github.com/kstsm/pvz-service/internal/tenant"
 	"github.com/kstsm/pvz-service/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,6 +34,49 @@ func TestCreatePVZ(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestImportPVZ(t *testing.T) {
+	address := "Тверская, 1"
+	items := []models.PVZImportItem{
+		{City: "Москва", Params: models.UpdatePVZRequest{Address: &address}},
+		{City: "Казань"},
+	}
+
+	tests := []struct {
+		name    string
+		repoErr error
+		wantLen int
+	}{
+		{name: "Все ПВЗ заведены", wantLen: 2},
+		{name: "Ошибка отменяет весь импорт", repoErr: errors.New("ПВЗ №2: не удалось завести")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			imported := []models.PVZ{
+				{ID: uuid.New(), City: "Москва", Address: address, Timezone: "Europe/Moscow"},
+				{ID: uuid.New(), City: "Казань", Timezone: "Europe/Moscow"},
+			}
+			if tt.repoErr != nil {
+				imported = nil
+			}
+			mockRepo.On("ImportPVZ", mock.Anything, items).Return(imported, tt.repoErr)
+
+			service := Service{repo: mockRepo}
+			got, err := service.ImportPVZ(context.Background(), items)
+
+			if tt.repoErr != nil {
+				assert.ErrorIs(t, err, tt.repoErr)
+				assert.Empty(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, got, tt.wantLen)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetPVZList(t *testing.T) {
 	mockRepo := new(MockRepo)
 
@@ -38,7 +85,7 @@ func TestGetPVZList(t *testing.T) {
 		EndDate:   nil,
 		Page:      1,
 		Limit:     10,
-	}).Return([]models.PVZWithReceptions{
+	}).Return(models.PVZPage{Items: []models.PVZWithReceptions{
 		{
 			PVZ: models.PVZ{
 				ID:               uuid.New(),
@@ -47,7 +94,7 @@ func TestGetPVZList(t *testing.T) {
 			},
 			Receptions: []models.Reception{},
 		},
-	}, nil)
+	}, HasNext: true}, nil)
 
 	service := Service{repo: mockRepo}
 
@@ -55,12 +102,280 @@ func TestGetPVZList(t *testing.T) {
 		Page:  1,
 		Limit: 10,
 	}
-	pvzList, err := service.GetPVZList(context.Background(), params)
+	page, err := service.GetPVZList(context.Background(), params)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "Moscow", page.Items[0].PVZ.City)
+	assert.True(t, page.HasNext)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIsPVZOpenAt(t *testing.T) {
+	pvz := models.PVZ{
+		Status:   models.PVZStatusActive,
+		Timezone: "Europe/Moscow",
+		WorkingHours: []models.WorkingHours{
+			{Weekday: int(time.Monday), Open: "09:00", Close: "21:00"},
+		},
+	}
+	// 2026-10-19 — понедельник. 06:30 UTC = 09:30 по Москве.
+	monday := time.Date(2026, 10, 19, 6, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		pvz      func() models.PVZ
+		at       time.Time
+		expected bool
+	}{
+		{"в рабочее время", func() models.PVZ { return pvz }, monday, true},
+		{"до открытия по местному времени", func() models.PVZ { return pvz }, monday.Add(-time.Hour), false},
+		{"после закрытия", func() models.PVZ { return pvz }, monday.Add(12 * time.Hour), false},
+		{"выходной день", func() models.PVZ { return pvz }, monday.Add(24 * time.Hour), false},
+		{"временно закрыт", func() models.PVZ {
+			closed := pvz
+			closed.Status = models.PVZStatusTemporarilyClosed
+			return closed
+		}, monday, false},
+		{"другой часовой пояс", func() models.PVZ {
+			vladivostok := pvz
+			vladivostok.Timezone = "Asia/Vladivostok"
+			return vladivostok
+		}, monday, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isPVZOpenAt(tt.pvz(), tt.at))
+		})
+	}
+}
+
+func TestGetPVZListLocalizesTimestamps(t *testing.T) {
+	mockRepo := new(MockRepo)
+	// 21:30 UTC 30 сентября — уже 1 октября по Казани и Москве.
+	receivedAt := time.Date(2026, 9, 30, 21, 30, 0, 0, time.UTC)
+	params := models.PVZFilterParams{StartDay: "2026-10-01", EndDay: "2026-10-01", Page: 1, Limit: 10}
+
+	mockRepo.On("GetPVZList", mock.Anything, params).Return(models.PVZPage{Items: []models.PVZWithReceptions{
+		{
+			PVZ:        models.PVZ{ID: uuid.New(), RegistrationDate: receivedAt, Timezone: "Europe/Moscow"},
+			Receptions: []models.Reception{{ID: uuid.New(), DateTime: receivedAt}},
+			Products:   []models.Product{{ID: uuid.New(), DateTime: receivedAt}},
+		},
+	}}, nil)
+
+	service := Service{repo: mockRepo}
+
+	page, err := service.GetPVZList(context.Background(), params)
+	pvzList := page.Items
 
 	assert.NoError(t, err)
-	assert.NotNil(t, pvzList)
 	assert.Len(t, pvzList, 1)
-	assert.Equal(t, "Moscow", pvzList[0].PVZ.City)
+	assert.Equal(t, "2026-10-01T00:30:00+03:00", pvzList[0].PVZ.RegistrationDate.Format(time.RFC3339))
+	assert.Equal(t, "2026-10-01T00:30:00+03:00", pvzList[0].Receptions[0].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "2026-10-01T00:30:00+03:00", pvzList[0].Products[0].DateTime.Format(time.RFC3339))
+	assert.True(t, receivedAt.Equal(pvzList[0].Receptions[0].DateTime))
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetPVZDailyReport(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockRepo      func(*MockRepo)
+		expectedDays  []models.DailyReceptionStats
+		expectedError error
+	}{
+		{
+			name: "Дни без приёмок заполняются нулями",
+			mockRepo: func(m *MockRepo) {
+				m.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{ID: pvzID, Timezone: "Europe/Moscow"}, nil)
+				m.On("GetReceptionDailyStats", mock.Anything, pvzID, "2026-10-01", "2026-10-03").
+					Return([]models.DailyReceptionStats{{Date: "2026-10-02", Receptions: 2, Products: 7}}, nil)
+			},
+			expectedDays: []models.DailyReceptionStats{
+				{Date: "2026-10-01"},
+				{Date: "2026-10-02", Receptions: 2, Products: 7},
+				{Date: "2026-10-03"},
+			},
+		},
+		{
+			name: "ПВЗ не найден",
+			mockRepo: func(m *MockRepo) {
+				m.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{}, apperrors.ErrPVZNotFound)
+			},
+			expectedError: apperrors.ErrPVZNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			tt.mockRepo(mockRepo)
+			service := Service{repo: mockRepo}
+
+			report, err := service.GetPVZDailyReport(context.Background(), pvzID, "2026-10-01", "2026-10-03", nil)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "Europe/Moscow", report.Timezone)
+			assert.Equal(t, tt.expectedDays, report.Days)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetNearbyPVZ(t *testing.T) {
+	alwaysOpen := make([]models.WorkingHours, 0, 7)
+	for day := 0; day < 7; day++ {
+		alwaysOpen = append(alwaysOpen, models.WorkingHours{Weekday: day, Open: "00:00", Close: "23:59"})
+	}
+	open := models.PVZWithDistance{
+		PVZ:        models.PVZ{ID: uuid.New(), Status: models.PVZStatusActive, Timezone: "UTC", WorkingHours: alwaysOpen},
+		DistanceKm: 1.5,
+	}
+	closed := models.PVZWithDistance{
+		PVZ:        models.PVZ{ID: uuid.New(), Status: models.PVZStatusActive, Timezone: "UTC"},
+		DistanceKm: 0.5,
+	}
+
+	t.Run("без фильтра по времени работы", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		params := models.NearbyPVZParams{Latitude: 55.75, Longitude: 37.61, RadiusKm: 5, Limit: 10}
+		mockRepo.On("GetNearbyPVZ", mock.Anything, params).Return([]models.PVZWithDistance{closed, open}, nil)
+
+		service := Service{repo: mockRepo}
+		pvzList, err := service.GetNearbyPVZ(context.Background(), params)
+
+		assert.NoError(t, err)
+		assert.Len(t, pvzList, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("только открытые сейчас", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		params := models.NearbyPVZParams{Latitude: 55.75, Longitude: 37.61, RadiusKm: 5, Limit: 1, OpenNow: true}
+		unlimited := params
+		unlimited.Limit = 0
+		mockRepo.On("GetNearbyPVZ", mock.Anything, unlimited).Return([]models.PVZWithDistance{closed, open, open}, nil)
+
+		service := Service{repo: mockRepo}
+		pvzList, err := service.GetNearbyPVZ(context.Background(), params)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.PVZWithDistance{open}, pvzList)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAssignEmployee(t *testing.T) {
+	pvzID, userID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name        string
+		pvzErr      error
+		role        string
+		expectedErr error
+	}{
+		{name: "Сотрудник закреплён", role: "employee"},
+		{name: "Пользователь не сотрудник", role: "client", expectedErr: apperrors.ErrUserNotEmployee},
+		{name: "ПВЗ не найден", pvzErr: apperrors.ErrPVZNotFound, expectedErr: apperrors.ErrPVZNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			service := Service{repo: mockRepo}
+
+			mockRepo.On("GetPVZByID", mock.Anything, pvzID).Return(models.PVZ{ID: pvzID}, tt.pvzErr)
+			mockRepo.On("GetUserByID", mock.Anything, userID).Return(models.User{ID: userID, Role: tt.role}, nil).Maybe()
+			if tt.expectedErr == nil {
+				mockRepo.On("AssignEmployee", mock.Anything, pvzID, userID).Return(nil)
+			}
+
+			err := service.AssignEmployee(context.Background(), pvzID, userID)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPVZListCache(t *testing.T) {
+	params := models.PVZFilterParams{Page: 1, Limit: 10}
+	page := models.PVZPage{
+		Items: []models.PVZWithReceptions{
+			{
+				PVZ:        models.PVZ{ID: uuid.New(), City: "Москва"},
+				Receptions: []models.Reception{},
+			},
+		},
+		HasNext: true,
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(s *Service, ctx context.Context)
+		repoCalls int
+	}{
+		{
+			name:      "Повторный запрос берётся из кэша",
+			mutate:    func(s *Service, ctx context.Context) {},
+			repoCalls: 1,
+		},
+		{
+			name: "Создание ПВЗ сбрасывает кэш",
+			mutate: func(s *Service, ctx context.Context) {
+				_, _ = s.CreatePVZ(ctx, "Казань")
+			},
+			repoCalls: 2,
+		},
+		{
+			name: "Задача без арендатора сбрасывает кэш всех арендаторов",
+			mutate: func(s *Service, ctx context.Context) {
+				s.invalidatePVZList(context.Background())
+			},
+			repoCalls: 2,
+		},
+		{
+			name: "Изменение у другого арендатора не сбрасывает кэш",
+			mutate: func(s *Service, ctx context.Context) {
+				s.invalidatePVZList(tenant.WithID(context.Background(), uuid.New()))
+			},
+			repoCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			fromPrimary := mock.MatchedBy(func(ctx context.Context) bool {
+				primary, _ := ctx.Value("readFromPrimary").(bool)
+				return primary
+			})
+			mockRepo.On("GetPVZList", fromPrimary, params).Return(page, nil)
+			mockRepo.On("CreatePVZ", mock.Anything, "Казань").Return(models.PVZ{ID: uuid.New()}, nil).Maybe()
+
+			s := NewService(mockRepo, WithPVZListCache(cache.NewLRU(16), time.Minute))
+			ctx := tenant.WithID(context.Background(), tenant.DefaultID)
+
+			first, err := s.GetPVZList(ctx, params)
+			assert.NoError(t, err)
+			tt.mutate(s, ctx)
+			second, err := s.GetPVZList(ctx, params)
+			assert.NoError(t, err)
+
+			assert.Equal(t, page.Items[0].PVZ.ID, first.Items[0].PVZ.ID)
+			assert.True(t, first.HasNext)
+			assert.Equal(t, first, second)
+			mockRepo.AssertNumberOfCalls(t, "GetPVZList", tt.repoCalls)
+		})
+	}
+}
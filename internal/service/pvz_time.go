@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/models"
+	"log/slog"
+	"time"
+)
+
+// База возвращает моменты времени в часовом поясе сервера. В ответах они
+// переводятся в пояс ПВЗ, чтобы дата и смещение совпадали с местными.
+
+func pvzLocation(pvz models.PVZ) (*time.Location, bool) {
+	return loadPVZLocation(context.Background(), pvz.ID, pvz.Timezone)
+}
+
+func loadPVZLocation(ctx context.Context, pvzID uuid.UUID, timezone string) (*time.Location, bool) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		slog.WarnContext(ctx, "Неизвестный часовой пояс ПВЗ", "pvzId", pvzID, "timezone", timezone)
+		return nil, false
+	}
+
+	return loc, true
+}
+
+// pvzLocations возвращает часовые пояса ПВЗ одним запросом. ПВЗ, пояс
+// которых определить не удалось, в результат не попадают, и их время
+// остаётся в поясе сервера.
+func (s Service) pvzLocations(ctx context.Context, pvzIDs ...uuid.UUID) map[uuid.UUID]*time.Location {
+	locations := make(map[uuid.UUID]*time.Location, len(pvzIDs))
+	if len(pvzIDs) == 0 {
+		return locations
+	}
+
+	timezones, err := s.repo.GetPVZTimezones(ctx, pvzIDs)
+	if err != nil {
+		slog.WarnContext(ctx, "Не удалось получить часовые пояса ПВЗ", "error", err)
+		return locations
+	}
+
+	for pvzID, timezone := range timezones {
+		if loc, ok := loadPVZLocation(ctx, pvzID, timezone); ok {
+			locations[pvzID] = loc
+		}
+	}
+
+	return locations
+}
+
+// pvzLocationByID возвращает часовой пояс ПВЗ или nil, если его не удалось определить.
+func (s Service) pvzLocationByID(ctx context.Context, pvzID uuid.UUID) *time.Location {
+	return s.pvzLocations(ctx, pvzID)[pvzID]
+}
+
+// productLocation возвращает часовой пояс ПВЗ, в который принят товар.
+func (s Service) productLocation(ctx context.Context, productID uuid.UUID) *time.Location {
+	pvzID, err := s.repo.GetProductPVZID(ctx, productID)
+	if err != nil {
+		slog.WarnContext(ctx, "Не удалось определить ПВЗ товара", "productId", productID, "error", err)
+		return nil
+	}
+
+	return s.pvzLocationByID(ctx, pvzID)
+}
+
+func localizeTime(t *time.Time, loc *time.Location) {
+	if t != nil && !t.IsZero() && loc != nil {
+		*t = t.In(loc)
+	}
+}
+
+func localizePVZ(pvz *models.PVZ) {
+	loc, ok := pvzLocation(*pvz)
+	if !ok {
+		return
+	}
+
+	localizeTime(&pvz.RegistrationDate, loc)
+}
+
+func localizeReception(reception *models.Reception, loc *time.Location) {
+	localizeTime(&reception.DateTime, loc)
+	localizeTime(reception.ClosedAt, loc)
+	localizeTime(reception.StaleAt, loc)
+}
+
+func localizeProduct(product *models.Product, loc *time.Location) {
+	localizeTime(&product.DateTime, loc)
+	localizeTime(product.IssuedAt, loc)
+	localizeTime(product.ExpiredAt, loc)
+}
+
+func localizePVZWithReceptions(item *models.PVZWithReceptions) {
+	loc, ok := pvzLocation(item.PVZ)
+	if !ok {
+		return
+	}
+
+	localizeTime(&item.PVZ.RegistrationDate, loc)
+	for i := range item.Receptions {
+		localizeReception(&item.Receptions[i], loc)
+	}
+	for i := range item.Products {
+		localizeProduct(&item.Products[i], loc)
+	}
+}
+
+// localizeReceptions переводит время приёмок разных ПВЗ в пояс каждого из них.
+func (s Service) localizeReceptions(ctx context.Context, receptions []models.Reception) {
+	pvzIDs := make([]uuid.UUID, 0, len(receptions))
+	for _, reception := range receptions {
+		pvzIDs = append(pvzIDs, reception.PVZID)
+	}
+
+	locations := s.pvzLocations(ctx, uniqueIDs(pvzIDs)...)
+	for i := range receptions {
+		localizeReception(&receptions[i], locations[receptions[i].PVZID])
+	}
+}
+
+// localizeParcel переводит время посылки в пояс её ПВЗ. Пока ПВЗ посылки
+// не известен клиенту, время остаётся в поясе сервера.
+func localizeParcel(parcel *models.Parcel) {
+	if parcel.PVZ == nil {
+		return
+	}
+	loc, ok := loadPVZLocation(context.Background(), parcel.PVZ.ID, parcel.PVZ.Timezone)
+	if !ok {
+		return
+	}
+
+	localizeTime(&parcel.LinkedAt, loc)
+	localizeTime(parcel.ReceivedAt, loc)
+	localizeTime(parcel.ReadyAt, loc)
+	localizeTime(parcel.IssuedAt, loc)
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+// allowPVZTimezones отвечает на запрос часовых поясов ПВЗ заданными поясами.
+func allowPVZTimezones(mockRepo *MockRepo, timezones map[uuid.UUID]string) {
+	mockRepo.On("GetPVZTimezones", mock.Anything, mock.Anything).Return(timezones, nil).Maybe()
+}
+
+func TestGetStaleReceptionsLocalized(t *testing.T) {
+	kazanID, vladivostokID := uuid.New(), uuid.New()
+	openedAt := time.Date(2026, 10, 1, 21, 30, 0, 0, time.UTC)
+
+	mockRepo := new(MockRepo)
+	service := NewService(mockRepo, WithMaxReceptionDuration(12*time.Hour))
+	mockRepo.On("GetStaleReceptions", mock.Anything, 12*time.Hour, (*uuid.UUID)(nil)).Return([]models.Reception{
+		{ID: uuid.New(), PVZID: kazanID, DateTime: openedAt},
+		{ID: uuid.New(), PVZID: vladivostokID, DateTime: openedAt},
+		{ID: uuid.New(), PVZID: kazanID, DateTime: openedAt},
+	}, nil)
+	mockRepo.On("GetPVZTimezones", mock.Anything, []uuid.UUID{kazanID, vladivostokID}).Return(map[uuid.UUID]string{
+		kazanID:       "Europe/Moscow",
+		vladivostokID: "Asia/Vladivostok",
+	}, nil).Once()
+
+	receptions, err := service.GetStaleReceptions(context.Background(), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10-02T00:30:00+03:00", receptions[0].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "2026-10-02T07:30:00+10:00", receptions[1].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "2026-10-02T00:30:00+03:00", receptions[2].DateTime.Format(time.RFC3339))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPVZLocationsError(t *testing.T) {
+	pvzID := uuid.New()
+	openedAt := time.Date(2026, 10, 1, 21, 30, 0, 0, time.UTC)
+
+	mockRepo := new(MockRepo)
+	service := Service{repo: mockRepo}
+	mockRepo.On("CreateReception", mock.Anything, pvzID, []models.ManifestItem(nil)).
+		Return(models.Reception{PVZID: pvzID, DateTime: openedAt}, nil)
+	mockRepo.On("GetPVZTimezones", mock.Anything, []uuid.UUID{pvzID}).
+		Return(map[uuid.UUID]string(nil), errors.New("db error"))
+
+	reception, err := service.CreateReception(context.Background(), models.CreateReceptionRequest{PVZID: pvzID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, openedAt, reception.DateTime)
+}
+
+func TestLocalizeParcel(t *testing.T) {
+	receivedAt := time.Date(2026, 10, 1, 21, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		pvz      *models.ParcelPVZ
+		expected string
+	}{
+		{
+			name:     "Время в поясе ПВЗ посылки",
+			pvz:      &models.ParcelPVZ{ID: uuid.New(), Timezone: "Asia/Yekaterinburg"},
+			expected: "2026-10-02T02:30:00+05:00",
+		},
+		{
+			name:     "ПВЗ не известен клиенту",
+			expected: "2026-10-01T21:30:00Z",
+		},
+		{
+			name:     "Неизвестный часовой пояс",
+			pvz:      &models.ParcelPVZ{ID: uuid.New(), Timezone: "Mars/Olympus"},
+			expected: "2026-10-01T21:30:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := receivedAt
+			parcel := models.Parcel{ReceivedAt: &at, PVZ: tt.pvz}
+
+			localizeParcel(&parcel)
+
+			assert.Equal(t, tt.expected, parcel.ReceivedAt.Format(time.RFC3339))
+		})
+	}
+}
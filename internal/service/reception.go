@@ -3,26 +3,249 @@ package service
 import (
 	"context"
 	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/models"
+	"log/slog"
 )
 
-func (s Service) CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	return s.repo.CreateReception(ctx, pvzID)
+func (s Service) CreateReception(ctx context.Context, req models.CreateReceptionRequest) (models.Reception, error) {
+	// Статус ПВЗ проверяет сама вставка приёмки под блокировкой строки ПВЗ,
+	// поэтому параллельное закрытие ПВЗ не проскочит между проверкой и вставкой.
+	reception, err := s.repo.CreateReception(ctx, req.PVZID, hashPickupCodes(req.Manifest))
+	if err != nil {
+		return models.Reception{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizeReception(&reception, s.pvzLocationByID(ctx, reception.PVZID))
+
+	return reception, nil
+}
+
+// hashPickupCodes заменяет коды получения в манифесте их хешами, чтобы коды
+// не попали ни в базу, ни в ответ.
+func hashPickupCodes(manifest []models.ManifestItem) []models.ManifestItem {
+	if manifest == nil {
+		return nil
+	}
+
+	hashed := make([]models.ManifestItem, len(manifest))
+	for i, item := range manifest {
+		if item.PickupCode != "" {
+			item.PickupCodeHash = hashPickupCode(item.PickupCode)
+			item.PickupCode = ""
+		}
+		hashed[i] = item
+	}
+
+	return hashed
+}
+
+func (s Service) AddProductToActiveReception(ctx context.Context, req models.AddProductRequest) (models.Product, error) {
+	if req.Condition == "" {
+		req.Condition = models.ProductConditionOK
+	}
+
+	product, err := s.repo.AddProductToActiveReception(ctx, req)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizeProduct(&product, s.pvzLocationByID(ctx, req.PVZID))
+
+	return product, nil
+}
+
+func (s Service) DeleteLastProductInReception(ctx context.Context, pvzID, employeeID uuid.UUID) error {
+	if err := s.repo.DeleteLastProductInReception(ctx, pvzID, employeeID); err != nil {
+		return err
+	}
+
+	s.invalidatePVZList(ctx)
+
+	return nil
+}
+
+// GetDeletedProducts возвращает товары, удалённые из открытой приёмки.
+func (s Service) GetDeletedProducts(ctx context.Context, receptionID uuid.UUID) ([]models.DeletedProduct, error) {
+	reception, err := s.repo.GetReceptionByID(ctx, receptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reception.Status != models.ReceptionStatusInProgress {
+		return nil, apperrors.ErrReceptionAlreadyClosed
+	}
+
+	deleted, err := s.repo.GetDeletedProducts(ctx, receptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.pvzLocationByID(ctx, reception.PVZID)
+	for i := range deleted {
+		localizeProduct(&deleted[i].Product, loc)
+		localizeTime(&deleted[i].DeletedAt, loc)
+	}
+
+	return deleted, nil
+}
+
+func (s Service) RestoreProduct(ctx context.Context, productID, moderatorID uuid.UUID) (models.Product, error) {
+	product, err := s.repo.RestoreProduct(ctx, productID)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizeProduct(&product, s.productLocation(ctx, productID))
+	slog.InfoContext(ctx, "Товар восстановлен", "productId", productID, "receptionId", product.ReceptionID,
+		"moderatorId", moderatorID)
+
+	return product, nil
+}
+
+func (s Service) GetReception(ctx context.Context, receptionID uuid.UUID, condition string, employeeID *uuid.UUID) (models.ReceptionWithProducts, error) {
+	reception, err := s.repo.GetReceptionByID(ctx, receptionID)
+	if err != nil {
+		return models.ReceptionWithProducts{}, err
+	}
+
+	if err = s.checkEmployeeScope(ctx, reception.PVZID, employeeID); err != nil {
+		return models.ReceptionWithProducts{}, err
+	}
+
+	products, err := s.repo.GetReceptionProducts(ctx, receptionID, condition)
+	if err != nil {
+		return models.ReceptionWithProducts{}, err
+	}
+
+	loc := s.pvzLocationByID(ctx, reception.PVZID)
+	localizeReception(&reception, loc)
+	for i := range products {
+		localizeProduct(&products[i], loc)
+	}
+
+	return models.ReceptionWithProducts{Reception: reception, Products: products}, nil
 }
 
-func (s Service) AddProductToActiveReception(ctx context.Context, productType string, pvzID uuid.UUID) (models.Product, error) {
-	return s.repo.AddProductToActiveReception(ctx, productType, pvzID)
+// CloseLastReception закрывает открытую приёмку ПВЗ. Приёмка с манифестом
+// сверяется с ним, и при расхождениях без acknowledge остаётся открытой.
+func (s Service) CloseLastReception(ctx context.Context, pvzID uuid.UUID, acknowledge bool) (models.Reception, error) {
+	reception, err := s.repo.CloseLastReception(ctx, pvzID, reconcileOnClose(acknowledge))
+	if err != nil {
+		return models.Reception{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizeReception(&reception, s.pvzLocationByID(ctx, pvzID))
+
+	return reception, nil
 }
 
-func (s Service) DeleteLastProductInReception(ctx context.Context, pvzID uuid.UUID) error {
-	return s.repo.DeleteLastProductInReception(ctx, pvzID)
+func (s Service) GetDiscrepancyReport(ctx context.Context, receptionID uuid.UUID, employeeID *uuid.UUID) (models.DiscrepancyReport, error) {
+	report, err := s.repo.GetDiscrepancyReport(ctx, receptionID)
+	if err != nil {
+		return models.DiscrepancyReport{}, err
+	}
+
+	reception, err := s.repo.GetReceptionByID(ctx, receptionID)
+	if err != nil {
+		return models.DiscrepancyReport{}, err
+	}
+
+	if err = s.checkEmployeeScope(ctx, reception.PVZID, employeeID); err != nil {
+		return models.DiscrepancyReport{}, err
+	}
+	localizeTime(&report.CreatedAt, s.pvzLocationByID(ctx, reception.PVZID))
+
+	return report, nil
 }
 
-func (s Service) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	reception, err := s.repo.CloseLastReception(ctx, pvzID)
+func (s Service) ForceCloseReception(ctx context.Context, receptionID uuid.UUID, req models.ForceCloseReceptionRequest, moderatorID uuid.UUID) (models.Reception, error) {
+	status := models.ReceptionStatusClose
+	if req.Abandon {
+		status = models.ReceptionStatusAbandoned
+	}
+
+	reception, err := s.repo.ForceCloseReception(ctx, receptionID, status, req.Reason, moderatorID, reconcileOnForceClose)
 	if err != nil {
 		return models.Reception{}, err
 	}
 
+	s.invalidatePVZList(ctx)
+	localizeReception(&reception, s.pvzLocationByID(ctx, reception.PVZID))
+	slog.InfoContext(ctx, "Приёмка закрыта принудительно", "receptionId", receptionID, "status", status,
+		"moderatorId", moderatorID, "reason", req.Reason)
+
 	return reception, nil
 }
+
+func (s Service) ReopenReception(ctx context.Context, receptionID uuid.UUID, req models.ReopenReceptionRequest, moderatorID uuid.UUID) (models.Reception, error) {
+	if s.reopenGracePeriod <= 0 {
+		return models.Reception{}, apperrors.ErrReopenWindowExpired
+	}
+
+	reception, err := s.repo.ReopenReception(ctx, receptionID, s.reopenGracePeriod, req.Reason, moderatorID)
+	if err != nil {
+		return models.Reception{}, err
+	}
+
+	s.invalidatePVZList(ctx)
+	localizeReception(&reception, s.pvzLocationByID(ctx, reception.PVZID))
+	slog.InfoContext(ctx, "Приёмка переоткрыта", "receptionId", receptionID, "pvzId", reception.PVZID,
+		"moderatorId", moderatorID, "reason", req.Reason)
+
+	return reception, nil
+}
+
+// GetStaleReceptions возвращает зависшие приёмки; сотруднику — только в
+// ПВЗ, за которыми он закреплён.
+func (s Service) GetStaleReceptions(ctx context.Context, employeeID *uuid.UUID) ([]models.Reception, error) {
+	if s.maxReceptionDuration <= 0 {
+		return []models.Reception{}, nil
+	}
+
+	receptions, err := s.repo.GetStaleReceptions(ctx, s.maxReceptionDuration, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.localizeReceptions(ctx, receptions)
+
+	return receptions, nil
+}
+
+// FlagStaleReceptions помечает приёмки, открытые дольше допустимого, и публикует
+// событие по каждой. Возвращает количество помеченных приёмок.
+func (s Service) FlagStaleReceptions(ctx context.Context) (int, error) {
+	if s.maxReceptionDuration <= 0 {
+		return 0, nil
+	}
+
+	stale, err := s.repo.FlagStaleReceptions(ctx, s.maxReceptionDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(stale) > 0 {
+		s.invalidatePVZList(ctx)
+	}
+
+	for _, reception := range stale {
+		slog.WarnContext(ctx, "Приёмка открыта слишком долго", "receptionId", reception.ID, "pvzId", reception.PVZID,
+			"openedAt", reception.DateTime)
+
+		event := models.Event{
+			Type:       models.EventReceptionStale,
+			OccurredAt: *reception.StaleAt,
+			Payload:    reception,
+		}
+		if err = s.notifier.Publish(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "Ошибка публикации события", "type", event.Type, "receptionId", reception.ID, "error", err)
+		}
+	}
+
+	return len(stale), nil
+}
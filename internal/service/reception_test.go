@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/repository"
 	"github.com/kstsm/pvz-service/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -14,6 +16,7 @@ import (
 func TestCreateReception(t *testing.T) {
 	mockRepo := new(MockRepo)
 	service := Service{repo: mockRepo}
+	allowPVZTimezones(mockRepo, nil)
 
 	pvzID := uuid.New()
 	expectedReception := models.Reception{
@@ -23,18 +26,47 @@ func TestCreateReception(t *testing.T) {
 		Status:   "created",
 	}
 
-	mockRepo.On("CreateReception", mock.Anything, pvzID).Return(expectedReception, nil)
+	mockRepo.On("CreateReception", mock.Anything, pvzID, []models.ManifestItem(nil)).Return(expectedReception, nil)
 
-	reception, err := service.CreateReception(context.Background(), pvzID)
+	reception, err := service.CreateReception(context.Background(), models.CreateReceptionRequest{PVZID: pvzID})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedReception, reception)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateReceptionRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+	}{
+		{name: "ПВЗ не активен", repoErr: apperrors.ErrPVZNotActive},
+		{name: "ПВЗ не найден", repoErr: apperrors.ErrPVZNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			service := Service{repo: mockRepo}
+
+			pvzID := uuid.New()
+			mockRepo.On("CreateReception", mock.Anything, pvzID, []models.ManifestItem(nil)).
+				Return(models.Reception{}, tt.repoErr)
+
+			reception, err := service.CreateReception(context.Background(), models.CreateReceptionRequest{PVZID: pvzID})
+
+			assert.ErrorIs(t, err, tt.repoErr)
+			assert.Equal(t, models.Reception{}, reception)
+			mockRepo.AssertNotCalled(t, "GetPVZByID", mock.Anything, pvzID)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestAddProductToActiveReception(t *testing.T) {
 	mockRepo := new(MockRepo)
 	service := Service{repo: mockRepo}
+	allowPVZTimezones(mockRepo, nil)
 
 	pvzID := uuid.New()
 	productType := "product_type"
@@ -45,9 +77,11 @@ func TestAddProductToActiveReception(t *testing.T) {
 		ReceptionID: pvzID,
 	}
 
-	mockRepo.On("AddProductToActiveReception", mock.Anything, productType, pvzID).Return(expectedProduct, nil)
+	req := models.AddProductRequest{Type: productType, PVZID: pvzID}
+	repoReq := models.AddProductRequest{Type: productType, PVZID: pvzID, Condition: models.ProductConditionOK}
+	mockRepo.On("AddProductToActiveReception", mock.Anything, repoReq).Return(expectedProduct, nil)
 
-	product, err := service.AddProductToActiveReception(context.Background(), productType, pvzID)
+	product, err := service.AddProductToActiveReception(context.Background(), req)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedProduct, product)
@@ -59,18 +93,57 @@ func TestDeleteLastProductInReception(t *testing.T) {
 	service := Service{repo: mockRepo}
 
 	pvzID := uuid.New()
+	employeeID := uuid.New()
 
-	mockRepo.On("DeleteLastProductInReception", mock.Anything, pvzID).Return(nil)
+	mockRepo.On("DeleteLastProductInReception", mock.Anything, pvzID, employeeID).Return(nil)
 
-	err := service.DeleteLastProductInReception(context.Background(), pvzID)
+	err := service.DeleteLastProductInReception(context.Background(), pvzID, employeeID)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetDeletedProducts(t *testing.T) {
+	receptionID := uuid.New()
+
+	t.Run("Открытая приёмка", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := Service{repo: mockRepo}
+		allowPVZTimezones(mockRepo, nil)
+
+		deleted := []models.DeletedProduct{{
+			Product:   models.Product{ID: uuid.New(), ReceptionID: receptionID, Type: "обувь"},
+			DeletedAt: time.Now(),
+		}}
+		mockRepo.On("GetReceptionByID", mock.Anything, receptionID).
+			Return(models.Reception{ID: receptionID, Status: models.ReceptionStatusInProgress}, nil)
+		mockRepo.On("GetDeletedProducts", mock.Anything, receptionID).Return(deleted, nil)
+
+		products, err := service.GetDeletedProducts(context.Background(), receptionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, deleted, products)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Закрытая приёмка", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := Service{repo: mockRepo}
+
+		mockRepo.On("GetReceptionByID", mock.Anything, receptionID).
+			Return(models.Reception{ID: receptionID, Status: models.ReceptionStatusClose}, nil)
+
+		_, err := service.GetDeletedProducts(context.Background(), receptionID)
+
+		assert.ErrorIs(t, err, apperrors.ErrReceptionAlreadyClosed)
+		mockRepo.AssertNotCalled(t, "GetDeletedProducts", mock.Anything, mock.Anything)
+	})
+}
+
 func TestCloseLastReception(t *testing.T) {
 	mockRepo := new(MockRepo)
 	service := Service{repo: mockRepo}
+	allowPVZTimezones(mockRepo, nil)
 
 	pvzID := uuid.New()
 	expectedReception := models.Reception{
@@ -80,9 +153,9 @@ func TestCloseLastReception(t *testing.T) {
 		Status:   "closed",
 	}
 
-	mockRepo.On("CloseLastReception", mock.Anything, pvzID).Return(expectedReception, nil)
+	mockRepo.On("CloseLastReception", mock.Anything, pvzID, mock.AnythingOfType("repository.ReconcileFunc")).Return(expectedReception, nil)
 
-	reception, err := service.CloseLastReception(context.Background(), pvzID)
+	reception, err := service.CloseLastReception(context.Background(), pvzID, false)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedReception, reception)
@@ -95,11 +168,118 @@ func TestCloseLastReceptionError(t *testing.T) {
 
 	pvzID := uuid.New()
 
-	mockRepo.On("CloseLastReception", mock.Anything, pvzID).Return(models.Reception{}, errors.New("db error"))
+	mockRepo.On("CloseLastReception", mock.Anything, pvzID, mock.AnythingOfType("repository.ReconcileFunc")).Return(models.Reception{}, errors.New("db error"))
 
-	reception, err := service.CloseLastReception(context.Background(), pvzID)
+	reception, err := service.CloseLastReception(context.Background(), pvzID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, models.Reception{}, reception)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestForceCloseReception(t *testing.T) {
+	receptionID, moderatorID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		req            models.ForceCloseReceptionRequest
+		expectedStatus string
+	}{
+		{"закрытие", models.ForceCloseReceptionRequest{Reason: "сотрудник забыл закрыть"}, models.ReceptionStatusClose},
+		{"отмена", models.ForceCloseReceptionRequest{Reason: "курьер уехал", Abandon: true}, models.ReceptionStatusAbandoned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			service := Service{repo: mockRepo}
+			allowPVZTimezones(mockRepo, nil)
+
+			var report models.DiscrepancyReport
+			var canClose bool
+			mockRepo.On("ForceCloseReception", mock.Anything, receptionID, tt.expectedStatus, tt.req.Reason, moderatorID,
+				mock.AnythingOfType("repository.ReconcileFunc")).
+				Run(func(args mock.Arguments) {
+					reconcile := args.Get(5).(repository.ReconcileFunc)
+					report, canClose = reconcile([]models.ManifestItem{{Barcode: "A1", Type: "обувь"}}, nil)
+				}).
+				Return(models.Reception{ID: receptionID, Status: tt.expectedStatus}, nil)
+
+			reception, err := service.ForceCloseReception(context.Background(), receptionID, tt.req, moderatorID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, reception.Status)
+			assert.True(t, canClose)
+			assert.True(t, report.Acknowledged)
+			assert.Len(t, report.Missing, 1)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFlagStaleReceptions(t *testing.T) {
+	t.Run("публикация события по каждой приёмке", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		events := &recordingNotifier{}
+		service := NewService(mockRepo, WithNotifier(events), WithMaxReceptionDuration(12*time.Hour))
+
+		staleAt := time.Now()
+		mockRepo.On("FlagStaleReceptions", mock.Anything, 12*time.Hour).Return([]models.Reception{
+			{ID: uuid.New(), StaleAt: &staleAt},
+		}, nil)
+
+		count, err := service.FlagStaleReceptions(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Len(t, events.events, 1)
+		assert.Equal(t, models.EventReceptionStale, events.events[0].Type)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("отслеживание отключено", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := NewService(mockRepo)
+
+		count, err := service.FlagStaleReceptions(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		receptions, err := service.GetStaleReceptions(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, receptions)
+
+		mockRepo.AssertNotCalled(t, "FlagStaleReceptions", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "GetStaleReceptions", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReopenReception(t *testing.T) {
+	receptionID, moderatorID := uuid.New(), uuid.New()
+	req := models.ReopenReceptionRequest{Reason: "закрыли по ошибке"}
+
+	t.Run("переоткрытие в пределах окна", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := NewService(mockRepo, WithReopenGracePeriod(30*time.Minute))
+		allowPVZTimezones(mockRepo, nil)
+
+		mockRepo.On("ReopenReception", mock.Anything, receptionID, 30*time.Minute, req.Reason, moderatorID).
+			Return(models.Reception{ID: receptionID, Status: models.ReceptionStatusInProgress}, nil)
+
+		reception, err := service.ReopenReception(context.Background(), receptionID, req, moderatorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ReceptionStatusInProgress, reception.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("переоткрытие отключено", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		service := NewService(mockRepo)
+
+		_, err := service.ReopenReception(context.Background(), receptionID, req, moderatorID)
+
+		assert.ErrorIs(t, err, apperrors.ErrReopenWindowExpired)
+		mockRepo.AssertNotCalled(t, "ReopenReception", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
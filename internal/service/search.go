@@ -0,0 +1,10 @@
+package service
+
+import (
+	"context"
+	"github.com/kstsm/pvz-service/models"
+)
+
+func (s Service) Search(ctx context.Context, params models.SearchParams) (models.SearchResult, error) {
+	return s.repo.Search(ctx, params)
+}
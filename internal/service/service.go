@@ -3,27 +3,185 @@ package service
 import (
 	"context"
 	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/cache"
+	"github.com/kstsm/pvz-service/internal/mailer"
+	"github.com/kstsm/pvz-service/internal/notifier"
 	"github.com/kstsm/pvz-service/internal/repository"
+	"github.com/kstsm/pvz-service/internal/storage"
 	"github.com/kstsm/pvz-service/models"
+	"io"
+	"sync"
+	"time"
 )
 
 type ServiceI interface {
 	CreatePVZ(ctx context.Context, city string) (models.PVZ, error)
-	CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error)
-	AddProductToActiveReception(ctx context.Context, productType string, pvzID uuid.UUID) (models.Product, error)
-	DeleteLastProductInReception(ctx context.Context, pvzID uuid.UUID) error
-	CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error)
-	GetPVZList(ctx context.Context, params models.PVZFilterParams) ([]models.PVZWithReceptions, error)
+	GetPVZByID(ctx context.Context, pvzID uuid.UUID) (models.PVZ, error)
+	UpdatePVZ(ctx context.Context, pvzID uuid.UUID, req models.UpdatePVZRequest) (models.PVZ, error)
+	DecommissionPVZ(ctx context.Context, pvzID, moderatorID uuid.UUID) error
+	AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error
+	UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error
+	Search(ctx context.Context, params models.SearchParams) (models.SearchResult, error)
+	CreateReception(ctx context.Context, req models.CreateReceptionRequest) (models.Reception, error)
+	AddProductToActiveReception(ctx context.Context, req models.AddProductRequest) (models.Product, error)
+	DeleteLastProductInReception(ctx context.Context, pvzID, employeeID uuid.UUID) error
+	GetDeletedProducts(ctx context.Context, receptionID uuid.UUID) ([]models.DeletedProduct, error)
+	RestoreProduct(ctx context.Context, productID, moderatorID uuid.UUID) (models.Product, error)
+	CloseLastReception(ctx context.Context, pvzID uuid.UUID, acknowledge bool) (models.Reception, error)
+	GetDiscrepancyReport(ctx context.Context, receptionID uuid.UUID, employeeID *uuid.UUID) (models.DiscrepancyReport, error)
+	GetPVZList(ctx context.Context, params models.PVZFilterParams) (models.PVZPage, error)
+	GetNearbyPVZ(ctx context.Context, params models.NearbyPVZParams) ([]models.PVZWithDistance, error)
+	GetPVZDailyReport(ctx context.Context, pvzID uuid.UUID, startDay, endDay string, employeeID *uuid.UUID) (models.PVZDailyReport, error)
+	EnsureDummyUser(ctx context.Context, role string) (uuid.UUID, error)
 	RegisterUser(ctx context.Context, req models.UserRegisterReq) (models.UserRegisterResp, error)
 	LoginUser(ctx context.Context, req models.UserLoginReq) (string, error)
+	VerifyEmail(ctx context.Context, req models.EmailVerificationReq) error
+	ResendEmailVerification(ctx context.Context, req models.ResendVerificationReq) error
+	RequestPasswordReset(ctx context.Context, req models.PasswordResetReq) error
+	ResetPassword(ctx context.Context, req models.PasswordResetConfirmReq) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordReq) error
+	CheckTokenRevoked(ctx context.Context, claims models.TokenClaims) error
+	GetTenant(ctx context.Context, slug string) (models.Tenant, error)
+	LinkParcel(ctx context.Context, clientID uuid.UUID, req models.LinkParcelRequest) (models.Parcel, error)
+	GetClientParcels(ctx context.Context, clientID uuid.UUID) ([]models.Parcel, error)
+	IssueProduct(ctx context.Context, productID uuid.UUID, employeeID uuid.UUID) (models.Product, error)
+	ReturnProduct(ctx context.Context, productID uuid.UUID, employeeID uuid.UUID) (models.Product, error)
+	GetPVZStock(ctx context.Context, pvzID uuid.UUID, condition string, employeeID *uuid.UUID) (models.PVZStock, error)
+	GetReception(ctx context.Context, receptionID uuid.UUID, condition string, employeeID *uuid.UUID) (models.ReceptionWithProducts, error)
+	AddProductAttachments(ctx context.Context, productID uuid.UUID, uploads []models.AttachmentUpload, employeeID uuid.UUID) ([]models.ProductAttachment, error)
+	GetProductAttachments(ctx context.Context, productID uuid.UUID, employeeID *uuid.UUID) ([]models.ProductAttachment, error)
+	OpenProductAttachment(ctx context.Context, productID, attachmentID uuid.UUID, employeeID *uuid.UUID) (models.ProductAttachment, io.ReadCloser, error)
+	GetExpiredProducts(ctx context.Context, pvzID uuid.UUID, employeeID *uuid.UUID) ([]models.Product, error)
+	ForceCloseReception(ctx context.Context, receptionID uuid.UUID, req models.ForceCloseReceptionRequest, moderatorID uuid.UUID) (models.Reception, error)
+	GetStaleReceptions(ctx context.Context, employeeID *uuid.UUID) ([]models.Reception, error)
+	ReopenReception(ctx context.Context, receptionID uuid.UUID, req models.ReopenReceptionRequest, moderatorID uuid.UUID) (models.Reception, error)
 }
 
 type Service struct {
-	repo repository.RepositoryI
+	repo                 repository.RepositoryI
+	notifier             notifier.Notifier
+	storagePeriods       map[string]time.Duration
+	maxReceptionDuration time.Duration
+	reopenGracePeriod    time.Duration
+	blobs                storage.BlobStorage
+	pvzListCache         cache.Cache
+	pvzListVersions      cache.Cache
+	pvzListTTL           time.Duration
+	tokens               *auth.Tokens
+	passwordPolicy       auth.PasswordPolicy
+	mailer               mailer.Mailer
+	verificationTTL      time.Duration
+	passwordResetTTL     time.Duration
+	passwordChanges      cache.Cache
+	passwordChangeTTL    time.Duration
+	background           *sync.WaitGroup
 }
 
-func NewService(repo repository.RepositoryI) *Service {
-	return &Service{
-		repo: repo,
+type Option func(*Service)
+
+func WithNotifier(n notifier.Notifier) Option {
+	return func(s *Service) {
+		s.notifier = n
+	}
+}
+
+// WithStoragePeriods задаёт сроки хранения товаров по типам.
+// Без них задача истечения сроков хранения ничего не делает.
+func WithStoragePeriods(periods map[string]time.Duration) Option {
+	return func(s *Service) {
+		s.storagePeriods = periods
+	}
+}
+
+// WithMaxReceptionDuration задаёт, сколько приёмка может оставаться открытой,
+// прежде чем считается зависшей. Без него зависшие приёмки не отслеживаются.
+func WithMaxReceptionDuration(d time.Duration) Option {
+	return func(s *Service) {
+		s.maxReceptionDuration = d
+	}
+}
+
+// WithReopenGracePeriod задаёт, сколько времени после закрытия приёмку можно
+// переоткрыть. Без него переоткрытие запрещено.
+func WithReopenGracePeriod(d time.Duration) Option {
+	return func(s *Service) {
+		s.reopenGracePeriod = d
+	}
+}
+
+// WithBlobStorage задаёт хранилище фотографий товаров. Без него загрузка
+// вложений недоступна.
+func WithBlobStorage(blobs storage.BlobStorage) Option {
+	return func(s *Service) {
+		s.blobs = blobs
+	}
+}
+
+// WithTokens задаёт выпуск JWT при входе по паролю. Без него вход недоступен.
+func WithTokens(tokens *auth.Tokens) Option {
+	return func(s *Service) {
+		s.tokens = tokens
+	}
+}
+
+// WithPasswordPolicy задаёт требования к паролям при регистрации, сбросе
+// и смене пароля.
+func WithPasswordPolicy(p auth.PasswordPolicy) Option {
+	return func(s *Service) {
+		s.passwordPolicy = p
+	}
+}
+
+// WithMailer задаёт отправку писем с кодами подтверждения email и сброса пароля.
+func WithMailer(m mailer.Mailer) Option {
+	return func(s *Service) {
+		s.mailer = m
+	}
+}
+
+// WithUserTokenTTL задаёт срок действия кодов подтверждения email и сброса пароля.
+func WithUserTokenTTL(verification, passwordReset time.Duration) Option {
+	return func(s *Service) {
+		s.verificationTTL = verification
+		s.passwordResetTTL = passwordReset
+	}
+}
+
+// WithPVZListCache кэширует ответы GetPVZList на ttl. Кэш сбрасывается
+// при любом изменении ПВЗ, приёмок и товаров через сервис. В метрики
+// pvz_list попадают только чтения самих списков, без ключей версий.
+func WithPVZListCache(c cache.Cache, ttl time.Duration) Option {
+	return func(s *Service) {
+		s.pvzListCache = cache.Instrumented("pvz_list", c)
+		s.pvzListVersions = c
+		s.pvzListTTL = ttl
 	}
 }
+
+// WithPasswordChangeCache кэширует на ttl момент смены пароля, по которому
+// CheckTokenRevoked отзывает токены. Смена и сброс пароля через сервис
+// удаляют запись сразу.
+func WithPasswordChangeCache(c cache.Cache, ttl time.Duration) Option {
+	return func(s *Service) {
+		s.passwordChanges = cache.Instrumented("password_change", c)
+		s.passwordChangeTTL = ttl
+	}
+}
+
+func NewService(repo repository.RepositoryI, opts ...Option) *Service {
+	s := &Service{
+		repo:             repo,
+		notifier:         notifier.NewLogNotifier(),
+		passwordPolicy:   auth.DefaultPasswordPolicy,
+		mailer:           mailer.NewLogMailer("no-reply@pvz-service.local"),
+		verificationTTL:  24 * time.Hour,
+		passwordResetTTL: time.Hour,
+		background:       &sync.WaitGroup{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
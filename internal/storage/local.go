@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage хранит файлы в каталоге локальной файловой системы.
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог хранилища %q: %w", root, err)
+	}
+
+	return &LocalStorage{root: root}, nil
+}
+
+// Put записывает файл во временный файл и переименовывает его, чтобы читатели
+// никогда не видели недописанное содержимое.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("не удалось создать каталог для %q: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("не удалось создать временный файл для %q: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("не удалось записать %q: %w", key, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("не удалось записать %q: %w", key, err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("не удалось сохранить %q: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("не удалось открыть %q: %w", key, err)
+	}
+
+	return file, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("не удалось удалить %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// path переводит ключ в путь внутри корневого каталога и отклоняет ключи,
+// которые выходят за его пределы.
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("недопустимый ключ файла: %q", key)
+	}
+
+	return filepath.Join(s.root, cleaned), nil
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	t.Run("запись и чтение", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "product/photo.jpg", strings.NewReader("содержимое")))
+
+		r, err := s.Get(ctx, "product/photo.jpg")
+		require.NoError(t, err)
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "содержимое", string(data))
+	})
+
+	t.Run("удаление", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "product/removed.jpg", strings.NewReader("x")))
+		require.NoError(t, s.Delete(ctx, "product/removed.jpg"))
+
+		_, err := s.Get(ctx, "product/removed.jpg")
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+
+		assert.NoError(t, s.Delete(ctx, "product/removed.jpg"))
+	})
+
+	t.Run("ключ вне каталога хранилища", func(t *testing.T) {
+		for _, key := range []string{"", "../escape.jpg", "/etc/passwd", "a/../../escape.jpg"} {
+			assert.Error(t, s.Put(ctx, key, strings.NewReader("x")), key)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("файл не найден в хранилище")
+
+// BlobStorage хранит бинарные файлы (фотографии товаров и т.п.) по ключу.
+// Ключ — относительный путь вида "<productId>/<attachmentId>.jpg".
+type BlobStorage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
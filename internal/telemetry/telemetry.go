@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"github.com/kstsm/pvz-service/config"
+	"github.com/kstsm/pvz-service/internal/buildinfo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation — имя, под которым сервис регистрирует свои трассировщики.
+const instrumentation = "github.com/kstsm/pvz-service"
+
+// Tracer возвращает трассировщик сервиса из глобального провайдера. До вызова
+// Setup провайдер пустой и спаны никуда не отправляются.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Setup настраивает глобальный провайдер трассировки и распространение
+// контекста W3C Trace Context. Возвращённая функция выгружает накопленные
+// спаны и должна быть вызвана при завершении.
+func Setup(ctx context.Context, cfg config.Tracing) (func(context.Context) error, error) {
+	// Заголовки traceparent принимаются и передаются дальше даже без экспорта,
+	// чтобы не разрывать трассировку вызывающего сервиса.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать экспортёр трассировки %s: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(buildinfo.Get().Version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось описать ресурс трассировки: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
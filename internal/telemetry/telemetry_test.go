@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestQueryTracer(t *testing.T) {
+	tests := []struct {
+		name       string
+		sql        string
+		end        pgx.TraceQueryEndData
+		wantName   string
+		wantStatus codes.Code
+	}{
+		{
+			name:       "Успешный запрос",
+			sql:        "\n\t\tSELECT id FROM receptions WHERE pvz_id = $1 FOR UPDATE",
+			end:        pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")},
+			wantName:   "db SELECT",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "Ошибка запроса",
+			sql:        "insert into products (type) values ($1)",
+			end:        pgx.TraceQueryEndData{Err: errors.New("нарушено ограничение")},
+			wantName:   "db INSERT",
+			wantStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := recordSpans(t)
+			tracer := QueryTracer{}
+
+			ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: tt.sql})
+			tracer.TraceQueryEnd(ctx, nil, tt.end)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tt.wantName, spans[0].Name())
+			assert.Equal(t, tt.wantStatus, spans[0].Status().Code)
+			assert.Contains(t, spans[0].Attributes(), attribute.String("db.query.text", tt.sql))
+		})
+	}
+}
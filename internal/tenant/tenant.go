@@ -0,0 +1,66 @@
+// Package tenant переносит арендатора (оператора маркетплейса) из запроса
+// в сессию Postgres, где его проверяют политики row-level security.
+package tenant
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+)
+
+// DefaultID — арендатор, к которому относятся данные, созданные до появления
+// арендаторов, и токены без tenant_id.
+var DefaultID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+const DefaultSlug = "default"
+
+// WithID кладёт арендатора в контекст. Все запросы к базе с этим контекстом
+// видят только данные этого арендатора.
+func WithID(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, "tenantID", tenantID)
+}
+
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	tenantID, ok := ctx.Value("tenantID").(uuid.UUID)
+	if !ok || tenantID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+// WithSystem помечает контекст фоновых задач, которые обрабатывают данные
+// всех арендаторов сразу. Для них политики row-level security не действуют.
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, "tenantSystem", true)
+}
+
+func IsSystem(ctx context.Context) bool {
+	system, _ := ctx.Value("tenantSystem").(bool)
+	return system
+}
+
+// BeforeAcquire выставляет в сессии соединения арендатора из контекста.
+// Подключается как pgxpool.Config.BeforeAcquire, поэтому срабатывает при
+// каждом запросе через пул. Контекст без арендатора и без WithSystem не
+// видит ни одной строки.
+func BeforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	var tenantID string
+	if id, ok := FromContext(ctx); ok {
+		tenantID = id.String()
+	}
+
+	bypass := "off"
+	if IsSystem(ctx) {
+		bypass = "on"
+	}
+
+	_, err := conn.Exec(ctx, `SELECT set_config('app.tenant_id', $1, false), set_config('app.bypass_rls', $2, false)`,
+		tenantID, bypass)
+	if err != nil {
+		slog.Error("Не удалось установить арендатора для соединения", "tenantId", tenantID, "error", err)
+		return false
+	}
+
+	return true
+}
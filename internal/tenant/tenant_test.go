@@ -0,0 +1,35 @@
+package tenant
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	tenantID := uuid.New()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantID   uuid.UUID
+		wantOK   bool
+		isSystem bool
+	}{
+		{name: "Арендатор задан", ctx: WithID(context.Background(), tenantID), wantID: tenantID, wantOK: true},
+		{name: "Арендатор не задан", ctx: context.Background()},
+		{name: "Пустой идентификатор", ctx: WithID(context.Background(), uuid.Nil)},
+		{name: "Фоновая задача", ctx: WithSystem(context.Background()), isSystem: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := FromContext(tt.ctx)
+
+			assert.Equal(t, tt.wantID, id)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.isSystem, IsSystem(tt.ctx))
+		})
+	}
+}
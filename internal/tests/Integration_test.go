@@ -1,8 +1,11 @@
 package tests
 
 import (
+	"errors"
+	"github.com/kstsm/pvz-service/internal/apperrors"
 	"github.com/kstsm/pvz-service/internal/repository"
 	"github.com/kstsm/pvz-service/internal/service"
+	"github.com/kstsm/pvz-service/models"
 	"testing"
 )
 
@@ -24,7 +27,7 @@ func TestReceptionIntegration(t *testing.T) {
 	}
 	t.Logf("ПВЗ создан: ID=%d", pvz.ID)
 
-	reception, err := svc.CreateReception(ctx, pvz.ID)
+	reception, err := svc.CreateReception(ctx, models.CreateReceptionRequest{PVZID: pvz.ID})
 	if err != nil {
 		t.Fatalf("Ошибка при создании приёмки: %v", err)
 	}
@@ -32,7 +35,7 @@ func TestReceptionIntegration(t *testing.T) {
 
 	for i := 0; i < 50; i++ {
 		productType := "электроника"
-		product, err := svc.AddProductToActiveReception(ctx, productType, pvz.ID)
+		product, err := svc.AddProductToActiveReception(ctx, models.AddProductRequest{Type: productType, PVZID: pvz.ID})
 		if err != nil {
 			t.Fatalf("Ошибка при добавлении товара #%d: %v", i+1, err)
 		}
@@ -42,7 +45,7 @@ func TestReceptionIntegration(t *testing.T) {
 	}
 	t.Logf("50 товаров добавлены к приёмке ID=%d", reception.ID)
 
-	closedReception, err := svc.CloseLastReception(ctx, pvz.ID)
+	closedReception, err := svc.CloseLastReception(ctx, pvz.ID, false)
 	if err != nil {
 		t.Fatalf("Ошибка при закрытии приёмки: %v", err)
 	}
@@ -54,3 +57,116 @@ func TestReceptionIntegration(t *testing.T) {
 	}
 	t.Log("Статус приёмки успешно проверен")
 }
+
+func TestReceptionManifestIntegration(t *testing.T) {
+	ts, ctx, pool := SetupTestServer(t)
+	defer ts.Close()
+	t.Cleanup(func() {
+		pool.Close()
+	})
+
+	svc := service.NewService(repository.NewRepository(pool))
+
+	pvz, err := svc.CreatePVZ(ctx, "Казань")
+	if err != nil {
+		t.Fatalf("Ошибка при создании ПВЗ: %v", err)
+	}
+
+	reception, err := svc.CreateReception(ctx, models.CreateReceptionRequest{
+		PVZID: pvz.ID,
+		Manifest: []models.ManifestItem{
+			{Barcode: "MANIFEST-0001", Type: "обувь"},
+			{Barcode: "MANIFEST-0002", Type: "одежда"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при создании приёмки с манифестом: %v", err)
+	}
+
+	for _, req := range []models.AddProductRequest{
+		{Type: "электроника", PVZID: pvz.ID, Barcode: "MANIFEST-0001"},
+		{Type: "одежда", PVZID: pvz.ID, Barcode: "MANIFEST-0003"},
+	} {
+		if _, err = svc.AddProductToActiveReception(ctx, req); err != nil {
+			t.Fatalf("Ошибка при добавлении товара %s: %v", req.Barcode, err)
+		}
+	}
+
+	if _, err = svc.CloseLastReception(ctx, pvz.ID, false); !errors.Is(err, apperrors.ErrReceptionHasDiscrepancies) {
+		t.Fatalf("Ожидалась ошибка о расхождениях, получено: %v", err)
+	}
+
+	report, err := svc.GetDiscrepancyReport(ctx, reception.ID, nil)
+	if err != nil {
+		t.Fatalf("Ошибка при получении отчёта о расхождениях: %v", err)
+	}
+	if len(report.Missing) != 1 || len(report.Extra) != 1 || len(report.Mismatched) != 1 || report.Acknowledged {
+		t.Fatalf("Некорректный отчёт о расхождениях: %+v", report)
+	}
+
+	closedReception, err := svc.CloseLastReception(ctx, pvz.ID, true)
+	if err != nil {
+		t.Fatalf("Ошибка при закрытии приёмки с подтверждением: %v", err)
+	}
+	if closedReception.Status != models.ReceptionStatusClose {
+		t.Fatalf("Некорректный статус приёмки: %q", closedReception.Status)
+	}
+
+	report, err = svc.GetDiscrepancyReport(ctx, reception.ID, nil)
+	if err != nil || !report.Acknowledged {
+		t.Fatalf("Отчёт о расхождениях не подтверждён: %+v, %v", report, err)
+	}
+}
+
+func TestParcelRelinkIntegration(t *testing.T) {
+	ts, ctx, pool := SetupTestServer(t)
+	defer ts.Close()
+	t.Cleanup(func() {
+		pool.Close()
+	})
+
+	svc := service.NewService(repository.NewRepository(pool))
+
+	clientID, err := svc.EnsureDummyUser(ctx, "client")
+	if err != nil {
+		t.Fatalf("Ошибка при создании клиента: %v", err)
+	}
+
+	pvz, err := svc.CreatePVZ(ctx, "Казань")
+	if err != nil {
+		t.Fatalf("Ошибка при создании ПВЗ: %v", err)
+	}
+
+	_, err = svc.CreateReception(ctx, models.CreateReceptionRequest{
+		PVZID:    pvz.ID,
+		Manifest: []models.ManifestItem{{Barcode: "RELINK-0001", Type: "обувь", PickupCode: "483920"}},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при создании приёмки с манифестом: %v", err)
+	}
+	if _, err = svc.AddProductToActiveReception(ctx, models.AddProductRequest{Type: "обувь", PVZID: pvz.ID, Barcode: "RELINK-0001"}); err != nil {
+		t.Fatalf("Ошибка при добавлении товара: %v", err)
+	}
+	if _, err = svc.CloseLastReception(ctx, pvz.ID, false); err != nil {
+		t.Fatalf("Ошибка при закрытии приёмки: %v", err)
+	}
+
+	mistyped, err := svc.LinkParcel(ctx, clientID, models.LinkParcelRequest{Barcode: "RELINK-0001", PickupCode: "000000"})
+	if err != nil {
+		t.Fatalf("Ошибка при привязке посылки с неверным кодом: %v", err)
+	}
+	if mistyped.PVZ != nil || mistyped.Status != models.ParcelStatusExpected {
+		t.Fatalf("Посылка с неверным кодом не должна показывать ПВЗ: %+v", mistyped)
+	}
+
+	relinked, err := svc.LinkParcel(ctx, clientID, models.LinkParcelRequest{Barcode: "RELINK-0001", PickupCode: "483920"})
+	if err != nil {
+		t.Fatalf("Ошибка при повторной привязке посылки с верным кодом: %v", err)
+	}
+	if relinked.ID != mistyped.ID {
+		t.Fatalf("Повторная привязка создала новую посылку: %v, ожидалась %v", relinked.ID, mistyped.ID)
+	}
+	if relinked.PVZ == nil || relinked.PVZ.ID != pvz.ID || relinked.Status != models.ParcelStatusReadyForPickup {
+		t.Fatalf("После привязки с верным кодом посылка должна быть готова к выдаче в ПВЗ: %+v", relinked)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"errors"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/repository"
+	"github.com/kstsm/pvz-service/internal/service"
+	"github.com/kstsm/pvz-service/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrentReceptionCreateClose(t *testing.T) {
+	ts, ctx, pool := SetupTestServer(t)
+	defer ts.Close()
+
+	svc := service.NewService(repository.NewRepository(pool))
+
+	pvz, err := svc.CreatePVZ(ctx, "Москва")
+	if err != nil {
+		t.Fatalf("Ошибка при создании ПВЗ: %v", err)
+	}
+
+	const workers = 32
+	const iterations = 25
+
+	t.Run("одновременное создание", func(t *testing.T) {
+		var created atomic.Int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+
+				_, err := svc.CreateReception(ctx, models.CreateReceptionRequest{PVZID: pvz.ID})
+				switch {
+				case err == nil:
+					created.Add(1)
+				case !errors.Is(err, apperrors.ErrReceptionAlreadyInProgress):
+					t.Errorf("Неожиданная ошибка при создании приёмки: %v", err)
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if created.Load() != 1 {
+			t.Fatalf("Должна быть создана ровно одна приёмка, создано: %d", created.Load())
+		}
+
+		if _, err = svc.CloseLastReception(ctx, pvz.ID, false); err != nil {
+			t.Fatalf("Ошибка при закрытии приёмки: %v", err)
+		}
+	})
+
+	t.Run("создание и закрытие вперемешку", func(t *testing.T) {
+		var created, closed atomic.Int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				<-start
+
+				for j := 0; j < iterations; j++ {
+					if (worker+j)%2 == 0 {
+						_, err := svc.CreateReception(ctx, models.CreateReceptionRequest{PVZID: pvz.ID})
+						switch {
+						case err == nil:
+							created.Add(1)
+						case !errors.Is(err, apperrors.ErrReceptionAlreadyInProgress):
+							t.Errorf("Неожиданная ошибка при создании приёмки: %v", err)
+						}
+						continue
+					}
+
+					_, err := svc.CloseLastReception(ctx, pvz.ID, false)
+					switch {
+					case err == nil:
+						closed.Add(1)
+					case !errors.Is(err, apperrors.ErrReceptionAlreadyClosed):
+						t.Errorf("Неожиданная ошибка при закрытии приёмки: %v", err)
+					}
+				}
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+
+		var open int
+		err := pool.QueryRow(ctx,
+			`SELECT count(*) FROM receptions WHERE pvz_id = $1 AND status = 'in_progress'`, pvz.ID).Scan(&open)
+		if err != nil {
+			t.Fatalf("Ошибка при подсчёте открытых приёмок: %v", err)
+		}
+
+		if open > 1 {
+			t.Fatalf("В ПВЗ одновременно открыто %d приёмок", open)
+		}
+		if int(created.Load()-closed.Load()) != open {
+			t.Fatalf("Создано %d, закрыто %d, но открыто %d приёмок", created.Load(), closed.Load(), open)
+		}
+		t.Logf("Создано приёмок: %d, закрыто: %d", created.Load(), closed.Load())
+	})
+}
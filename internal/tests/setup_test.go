@@ -3,46 +3,80 @@ package tests
 import (
 	"context"
 	"fmt"
-	"github.com/gookit/slog"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kstsm/pvz-service/database"
+	"github.com/kstsm/pvz-service/internal/auth"
 	"github.com/kstsm/pvz-service/internal/handler"
 	"github.com/kstsm/pvz-service/internal/repository"
 	"github.com/kstsm/pvz-service/internal/service"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/migrations"
 	"log"
+	"log/slog"
 	"net/http/httptest"
 	"testing"
 )
 
+// InitTestPostgres применяет миграции под владельцем тестовой базы и
+// возвращает пул под ролью приложения, как у сервера: владелец-суперпользователь
+// обходит row-level security, и тесты изоляции арендаторов ничего бы не проверяли.
 func InitTestPostgres(ctx context.Context) *pgxpool.Pool {
-	user := "test_user"
-	pass := "test_pass"
+	owner, ownerPass := "test_user", "test_pass"
+	appUser, appPass := "test_app", "test_app_pass"
+
+	ownerPool := connectTestPostgres(ctx, owner, ownerPass)
+	defer ownerPool.Close()
+
+	if _, err := database.Migrate(tenant.WithSystem(ctx), ownerPool, migrations.FS); err != nil {
+		log.Fatalf("Не удалось применить миграции: %v", err)
+	}
+	if err := database.EnsureAppLogin(ctx, ownerPool, appUser, appPass); err != nil {
+		log.Fatalf("Не удалось подготовить роль приложения: %v", err)
+	}
+
+	pool := connectTestPostgres(ctx, appUser, appPass)
+	if err := database.CheckAppLogin(ctx, pool); err != nil {
+		log.Fatalf("Тесты должны работать под ролью приложения: %v", err)
+	}
+
+	slog.Info("Успешное подключение к тестовой базе данных")
+	return pool
+}
+
+func connectTestPostgres(ctx context.Context, user, pass string) *pgxpool.Pool {
 	host := "localhost"
 	port := "5433"
 	db := "test_db"
 
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", user, pass, host, port, db)
 
-	slog.Info("Подключение к тестовой базе данных", "host", host, "port", port, "db", db)
+	slog.Info("Подключение к тестовой базе данных", "host", host, "port", port, "db", db, "user", user)
 
-	pool, err := pgxpool.New(ctx, dsn)
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		log.Fatalf("Некорректные параметры подключения к базе данных: %v", err)
+	}
+	poolConfig.BeforeAcquire = tenant.BeforeAcquire
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
 	}
 
-	slog.Info("Успешное подключение к тестовой базе данных")
 	return pool
 }
 
 func SetupTestServer(t *testing.T) (*httptest.Server, context.Context, *pgxpool.Pool) {
-	ctx := context.Background()
+	ctx := tenant.WithID(context.Background(), tenant.DefaultID)
 	conn := InitTestPostgres(ctx)
 	t.Cleanup(func() {
 		conn.Close()
 	})
 
+	tokens := auth.NewTokens("test-secret")
 	repo := repository.NewRepository(conn)
-	svc := service.NewService(repo)
-	router := handler.NewRouterForTests(ctx, svc)
+	svc := service.NewService(repo, service.WithTokens(tokens))
+	router := handler.NewRouterForTests(svc, tokens)
 	ts := httptest.NewServer(router)
 
 	return ts, ctx, conn
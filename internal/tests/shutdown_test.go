@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/auth"
+	"github.com/kstsm/pvz-service/internal/handler"
+	"github.com/kstsm/pvz-service/internal/health"
+	"github.com/kstsm/pvz-service/internal/lifecycle"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	return ln.Addr().String()
+}
+
+// TestGracefulShutdownCompletesInFlightRequests проверяет, что при завершении
+// сервер сначала перестаёт быть готовым, затем перестаёт принимать соединения,
+// но запрос, начатый до сигнала, получает полный ответ.
+func TestGracefulShutdownCompletesInFlightRequests(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	svc := new(handler.MockService)
+	svc.On("GetPVZList", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			close(entered)
+			<-release
+		}).
+		Return(models.PVZPage{}, nil)
+
+	tokens := auth.NewTokens("test-secret")
+	token, err := tokens.Generate(context.Background(), uuid.New(), "moderator", tenant.DefaultID)
+	require.NoError(t, err)
+
+	readiness := health.NewReadiness()
+	addr := freeAddr(t)
+	srv := &http.Server{Addr: addr, Handler: handler.NewHandler(svc, tokens, readiness).NewRouter()}
+
+	app := lifecycle.New(100 * time.Millisecond)
+	app.OnShutdown(readiness.Drain)
+	app.Add(lifecycle.HTTPServer(srv, 5*time.Second, app.Fail))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.Run(ctx) }()
+
+	baseURL := fmt.Sprintf("http://%s", addr)
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(baseURL + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond, "сервер не запустился")
+
+	inFlight := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, baseURL+"/pvz", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			inFlight <- 0
+			return
+		}
+		resp.Body.Close()
+		inFlight <- resp.StatusCode
+	}()
+	<-entered
+
+	cancel()
+
+	// Во время паузы на снятие трафика сервер ещё принимает запросы, но не готов.
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(baseURL + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusServiceUnavailable
+	}, time.Second, 5*time.Millisecond, "/readyz не сообщил о завершении")
+
+	// После паузы сервер закрывает порт, но ждёт начатый запрос.
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return true
+		}
+		conn.Close()
+		return false
+	}, 2*time.Second, 5*time.Millisecond, "сервер не перестал принимать соединения")
+
+	select {
+	case <-runErr:
+		t.Fatal("сервер остановился, не дождавшись запроса")
+	default:
+	}
+
+	close(release)
+
+	assert.Equal(t, http.StatusOK, <-inFlight, "начатый запрос должен завершиться успешно")
+	select {
+	case err := <-runErr:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("сервер не остановился после завершения запроса")
+	}
+}
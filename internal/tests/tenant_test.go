@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/kstsm/pvz-service/internal/apperrors"
+	"github.com/kstsm/pvz-service/internal/repository"
+	"github.com/kstsm/pvz-service/internal/service"
+	"github.com/kstsm/pvz-service/internal/tenant"
+	"github.com/kstsm/pvz-service/models"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	ts, ctx, pool := SetupTestServer(t)
+	defer ts.Close()
+
+	svc := service.NewService(repository.NewRepository(pool))
+
+	var other models.Tenant
+	err := pool.QueryRow(tenant.WithSystem(context.Background()), `
+		INSERT INTO tenants (slug, name) VALUES ('isolation-test', 'Второй оператор')
+		ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id, slug, name`).Scan(&other.ID, &other.Slug, &other.Name)
+	if err != nil {
+		t.Fatalf("Ошибка при создании арендатора: %v", err)
+	}
+	otherCtx := tenant.WithID(context.Background(), other.ID)
+
+	pvz, err := svc.CreatePVZ(ctx, "Москва")
+	if err != nil {
+		t.Fatalf("Ошибка при создании ПВЗ: %v", err)
+	}
+
+	if _, err = svc.GetPVZByID(otherCtx, pvz.ID); !errors.Is(err, apperrors.ErrPVZNotFound) {
+		t.Fatalf("Чужой ПВЗ не должен быть виден: получена ошибка %v", err)
+	}
+
+	_, err = svc.CreateReception(otherCtx, models.CreateReceptionRequest{PVZID: pvz.ID})
+	if !errors.Is(err, apperrors.ErrPVZNotFound) {
+		t.Fatalf("Приёмка в чужом ПВЗ не должна создаваться: получена ошибка %v", err)
+	}
+
+	if _, err = svc.GetPVZByID(context.Background(), pvz.ID); !errors.Is(err, apperrors.ErrPVZNotFound) {
+		t.Fatalf("Без арендатора ПВЗ не должен быть виден: получена ошибка %v", err)
+	}
+}
+
+func TestTenantIsolationHTTP(t *testing.T) {
+	ts, _, pool := SetupTestServer(t)
+	defer ts.Close()
+
+	svc := service.NewService(repository.NewRepository(pool))
+
+	var otherID uuid.UUID
+	err := pool.QueryRow(tenant.WithSystem(context.Background()), `
+		INSERT INTO tenants (slug, name) VALUES ('isolation-http-test', 'Третий оператор')
+		ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id`).Scan(&otherID)
+	if err != nil {
+		t.Fatalf("Ошибка при создании арендатора: %v", err)
+	}
+
+	otherPVZ, err := svc.CreatePVZ(tenant.WithID(context.Background(), otherID), "Казань")
+	if err != nil {
+		t.Fatalf("Ошибка при создании ПВЗ: %v", err)
+	}
+
+	resp, err := http.Post(ts.URL+"/v2/dummyLogin", "application/json", strings.NewReader(`{"role":"moderator"}`))
+	if err != nil {
+		t.Fatalf("Ошибка при получении токена: %v", err)
+	}
+	defer resp.Body.Close()
+	var login models.DummyLoginResponse
+	if err = json.NewDecoder(resp.Body).Decode(&login); err != nil {
+		t.Fatalf("Ошибка при разборе токена: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v2/pvz/"+otherPVZ.ID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+login.Token)
+	pvzResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Ошибка при запросе ПВЗ: %v", err)
+	}
+	defer pvzResp.Body.Close()
+
+	if pvzResp.StatusCode != http.StatusNotFound {
+		t.Fatalf("ПВЗ другого арендатора должен отвечать 404, получен %d", pvzResp.StatusCode)
+	}
+}
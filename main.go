@@ -1,8 +1,14 @@
 package main
 
-import "github.com/kstsm/pvz-service/cmd"
+import (
+	"fmt"
+	"github.com/kstsm/pvz-service/cmd"
+	"os"
+)
 
 func main() {
-	cmd.Run()
-
+	if err := cmd.Execute(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,9 @@
+-- Адрес, координаты, часовой пояс, график работы и статус ПВЗ.
+ALTER TABLE pvz
+    ADD COLUMN address       VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN latitude      DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude     DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD COLUMN timezone      VARCHAR(64)  NOT NULL DEFAULT 'Europe/Moscow',
+    ADD COLUMN working_hours JSONB        NOT NULL DEFAULT '[]',
+    ADD COLUMN status        VARCHAR(50)  NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'temporarily_closed', 'decommissioned'));
@@ -0,0 +1,2 @@
+-- Поиск ближайших ПВЗ.
+CREATE INDEX idx_pvz_coordinates ON pvz (latitude, longitude);
@@ -0,0 +1,16 @@
+-- Посылки клиентов и штрихкоды товаров, по которым они отслеживаются.
+ALTER TABLE receptions ADD COLUMN closed_at TIMESTAMPTZ;
+
+ALTER TABLE products ADD COLUMN barcode VARCHAR(64);
+
+CREATE INDEX idx_products_barcode ON products (barcode) WHERE barcode IS NOT NULL;
+
+CREATE TABLE parcels
+(
+    id        UUID PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    client_id UUID        NOT NULL REFERENCES users (id),
+    barcode   VARCHAR(64) NOT NULL UNIQUE,
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_parcels_client_id ON parcels (client_id);
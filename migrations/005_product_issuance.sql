@@ -0,0 +1,8 @@
+-- Выдача товаров клиентам и возврат курьеру.
+ALTER TABLE products
+    ADD COLUMN status    VARCHAR(50) NOT NULL DEFAULT 'received'
+        CHECK (status IN ('received', 'issued', 'returned')),
+    ADD COLUMN issued_at TIMESTAMPTZ,
+    ADD COLUMN issued_by UUID;
+
+CREATE INDEX idx_products_reception_status ON products (reception_id, status);
@@ -0,0 +1,5 @@
+-- Истечение срока хранения невостребованных товаров.
+ALTER TABLE products DROP CONSTRAINT products_status_check;
+ALTER TABLE products
+    ADD CONSTRAINT products_status_check CHECK (status IN ('received', 'issued', 'returned', 'expired')),
+    ADD COLUMN expired_at TIMESTAMPTZ;
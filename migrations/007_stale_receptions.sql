@@ -0,0 +1,7 @@
+-- Зависшие приёмки и их принудительное закрытие.
+ALTER TABLE receptions DROP CONSTRAINT receptions_status_check;
+ALTER TABLE receptions
+    ADD CONSTRAINT receptions_status_check CHECK (status IN ('in_progress', 'close', 'abandoned')),
+    ADD COLUMN stale_at     TIMESTAMPTZ,
+    ADD COLUMN close_reason TEXT,
+    ADD COLUMN closed_by    UUID;
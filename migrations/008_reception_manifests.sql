@@ -0,0 +1,18 @@
+-- Манифесты курьеров и расхождения с ними при закрытии приёмки.
+CREATE TABLE reception_manifest_items
+(
+    reception_id UUID        NOT NULL REFERENCES receptions (id),
+    barcode      VARCHAR(64) NOT NULL,
+    type         VARCHAR(50) NOT NULL CHECK (type IN ('электроника', 'одежда', 'обувь')),
+    PRIMARY KEY (reception_id, barcode)
+);
+
+CREATE TABLE reception_discrepancies
+(
+    reception_id UUID PRIMARY KEY REFERENCES receptions (id),
+    missing      JSONB       NOT NULL DEFAULT '[]',
+    extra        JSONB       NOT NULL DEFAULT '[]',
+    mismatched   JSONB       NOT NULL DEFAULT '[]',
+    acknowledged BOOLEAN     NOT NULL DEFAULT false,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
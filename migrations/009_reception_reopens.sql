@@ -0,0 +1,12 @@
+-- Журнал переоткрытия закрытых приёмок.
+CREATE TABLE reception_reopens
+(
+    id           UUID PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    reception_id UUID        NOT NULL REFERENCES receptions (id),
+    reopened_by  UUID,
+    reason       TEXT        NOT NULL,
+    closed_at    TIMESTAMPTZ NOT NULL,
+    reopened_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_reception_reopens_reception_id ON reception_reopens (reception_id);
@@ -0,0 +1,2 @@
+-- В ПВЗ может быть открыта только одна приёмка.
+CREATE UNIQUE INDEX uniq_receptions_pvz_in_progress ON receptions (pvz_id) WHERE status = 'in_progress';
@@ -0,0 +1,20 @@
+-- Состояние товара при приёмке и фотографии.
+ALTER TABLE products
+    ADD COLUMN condition VARCHAR(20) NOT NULL DEFAULT 'ok' CHECK (condition IN ('ok', 'damaged', 'opened')),
+    ADD COLUMN note      TEXT;
+
+CREATE TABLE product_attachments
+(
+    id           UUID PRIMARY KEY      DEFAULT uuid_generate_v4(),
+    product_id   UUID         NOT NULL REFERENCES products (id),
+    storage_key  VARCHAR(255) NOT NULL,
+    file_name    VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes   BIGINT       NOT NULL,
+    uploaded_by  UUID,
+    uploaded_at  TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_product_attachments_product_id ON product_attachments (product_id);
+
+CREATE INDEX idx_products_damaged ON products (reception_id) WHERE condition != 'ok';
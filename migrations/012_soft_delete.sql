@@ -0,0 +1,10 @@
+-- Мягкое удаление товаров и ПВЗ.
+ALTER TABLE pvz
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by UUID;
+
+ALTER TABLE products
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by UUID;
+
+CREATE INDEX idx_products_deleted ON products (reception_id) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,21 @@
+-- Полнотекстовый поиск по ПВЗ, приёмкам и товарам и закрепление сотрудников за ПВЗ.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE pvz
+    ADD COLUMN search_text   TEXT GENERATED ALWAYS AS (COALESCE(city, '') || ' ' || address) STORED,
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('russian', COALESCE(city, '') || ' ' || address)) STORED;
+
+CREATE INDEX idx_products_barcode_trgm ON products USING gin (barcode gin_trgm_ops);
+CREATE INDEX idx_pvz_search_vector ON pvz USING gin (search_vector);
+CREATE INDEX idx_pvz_search_text_trgm ON pvz USING gin (search_text gin_trgm_ops);
+CREATE INDEX idx_receptions_id_text ON receptions ((id::text) text_pattern_ops);
+
+CREATE TABLE pvz_employees
+(
+    pvz_id      UUID        NOT NULL REFERENCES pvz (id),
+    user_id     UUID        NOT NULL REFERENCES users (id),
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (pvz_id, user_id)
+);
+
+CREATE INDEX idx_pvz_employees_user_id ON pvz_employees (user_id);
@@ -0,0 +1,113 @@
+-- Арендаторы. Существующие данные переходят к арендатору по умолчанию.
+CREATE TABLE tenants
+(
+    id         UUID PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    slug       VARCHAR(64) UNIQUE   NOT NULL,
+    name       VARCHAR(255)         NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO tenants (id, slug, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'Оператор по умолчанию');
+
+-- Арендатор запроса и признак фоновой задачи выставляются приложением
+-- в настройках сессии перед каждым запросом.
+CREATE FUNCTION current_tenant_id() RETURNS UUID
+    LANGUAGE sql STABLE
+AS $$ SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid $$;
+
+CREATE FUNCTION rls_bypassed() RETURNS BOOLEAN
+    LANGUAGE sql STABLE
+AS $$ SELECT COALESCE(current_setting('app.bypass_rls', true), '') = 'on' $$;
+
+ALTER TABLE pvz ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE receptions ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE products ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE users ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE parcels ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+
+ALTER TABLE pvz ALTER COLUMN tenant_id SET DEFAULT current_tenant_id();
+ALTER TABLE receptions ALTER COLUMN tenant_id SET DEFAULT current_tenant_id();
+ALTER TABLE products ALTER COLUMN tenant_id SET DEFAULT current_tenant_id();
+ALTER TABLE users ALTER COLUMN tenant_id SET DEFAULT current_tenant_id();
+ALTER TABLE parcels ALTER COLUMN tenant_id SET DEFAULT current_tenant_id();
+
+-- Связанные строки принадлежат одному арендатору.
+ALTER TABLE pvz ADD UNIQUE (id, tenant_id);
+
+ALTER TABLE receptions DROP CONSTRAINT receptions_pvz_id_fkey;
+ALTER TABLE receptions
+    ADD UNIQUE (id, tenant_id),
+    ADD FOREIGN KEY (pvz_id, tenant_id) REFERENCES pvz (id, tenant_id);
+
+ALTER TABLE products DROP CONSTRAINT products_reception_id_fkey;
+ALTER TABLE products ADD FOREIGN KEY (reception_id, tenant_id) REFERENCES receptions (id, tenant_id);
+
+-- Email и штрихкод посылки уникальны в пределах арендатора.
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+ALTER TABLE users ADD UNIQUE (tenant_id, email);
+
+ALTER TABLE parcels DROP CONSTRAINT parcels_barcode_key;
+ALTER TABLE parcels ADD UNIQUE (tenant_id, barcode);
+
+-- Row-level security: строки арендаторов видны только в сессии с тем же
+-- app.tenant_id. FORCE распространяет политики и на владельца таблиц,
+-- под которым работает приложение.
+ALTER TABLE pvz ENABLE ROW LEVEL SECURITY;
+ALTER TABLE pvz FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON pvz USING (rls_bypassed() OR tenant_id = current_tenant_id());
+
+ALTER TABLE receptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE receptions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON receptions USING (rls_bypassed() OR tenant_id = current_tenant_id());
+
+ALTER TABLE products ENABLE ROW LEVEL SECURITY;
+ALTER TABLE products FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON products USING (rls_bypassed() OR tenant_id = current_tenant_id());
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users USING (rls_bypassed() OR tenant_id = current_tenant_id());
+
+ALTER TABLE parcels ENABLE ROW LEVEL SECURITY;
+ALTER TABLE parcels FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON parcels USING (rls_bypassed() OR tenant_id = current_tenant_id());
+
+-- Дочерние таблицы видны через родительскую строку, к которой уже применена политика.
+ALTER TABLE product_attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE product_attachments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON product_attachments
+    USING (EXISTS (SELECT 1 FROM products p WHERE p.id = product_id));
+
+ALTER TABLE reception_reopens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE reception_reopens FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON reception_reopens
+    USING (EXISTS (SELECT 1 FROM receptions r WHERE r.id = reception_id));
+
+ALTER TABLE reception_manifest_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE reception_manifest_items FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON reception_manifest_items
+    USING (EXISTS (SELECT 1 FROM receptions r WHERE r.id = reception_id));
+
+ALTER TABLE reception_discrepancies ENABLE ROW LEVEL SECURITY;
+ALTER TABLE reception_discrepancies FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON reception_discrepancies
+    USING (EXISTS (SELECT 1 FROM receptions r WHERE r.id = reception_id));
+
+ALTER TABLE pvz_employees ENABLE ROW LEVEL SECURITY;
+ALTER TABLE pvz_employees FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON pvz_employees
+    USING (EXISTS (SELECT 1 FROM pvz WHERE pvz.id = pvz_id));
+
+-- resource_tenant_id возвращает арендатора строки в обход политик. Используется
+-- только для журналирования попыток доступа к данным другого арендатора.
+CREATE FUNCTION resource_tenant_id(resource TEXT, resource_id UUID) RETURNS UUID
+    LANGUAGE sql STABLE
+    SET app.bypass_rls = 'on'
+AS $$
+    SELECT tenant_id FROM pvz WHERE resource = 'pvz' AND id = resource_id
+    UNION ALL
+    SELECT tenant_id FROM receptions WHERE resource = 'reception' AND id = resource_id
+    UNION ALL
+    SELECT tenant_id FROM products WHERE resource = 'product' AND id = resource_id
+$$;
@@ -0,0 +1,24 @@
+-- Подтверждение email и одноразовые коды для подтверждения и сброса пароля.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- Пользователи, созданные до появления подтверждения, сохраняют доступ.
+UPDATE users SET email_verified_at = now();
+
+CREATE TABLE user_tokens
+(
+    id         UUID PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    tenant_id  UUID        NOT NULL DEFAULT current_tenant_id() REFERENCES tenants (id),
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    VARCHAR(32) NOT NULL CHECK (purpose IN ('email_verification', 'password_reset')),
+    -- Хранится только SHA-256 кода: утечка таблицы не даёт действующих ссылок.
+    token_hash CHAR(64)    NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_user_tokens_user_id ON user_tokens (user_id, purpose) WHERE used_at IS NULL;
+
+ALTER TABLE user_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_tokens FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON user_tokens USING (rls_bypassed() OR tenant_id = current_tenant_id());
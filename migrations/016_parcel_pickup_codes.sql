@@ -0,0 +1,12 @@
+-- Код получения подтверждает, что клиент — получатель посылки. Отправитель
+-- сообщает его клиенту и передаёт в манифесте курьера; хранится только SHA-256.
+ALTER TABLE reception_manifest_items ADD COLUMN pickup_code_hash CHAR(64);
+
+-- Посылки, привязанные без кода, остаются в списке клиента, но ПВЗ и приёмку
+-- не показывают, пока клиент не привяжет их заново с кодом.
+ALTER TABLE parcels ADD COLUMN pickup_code_hash CHAR(64);
+
+-- Штрихкод больше не занимается первым привязавшим: его могут привязать
+-- несколько клиентов, а состояние увидит только тот, чей код совпал.
+ALTER TABLE parcels DROP CONSTRAINT parcels_tenant_id_barcode_key;
+ALTER TABLE parcels ADD UNIQUE (client_id, barcode);
@@ -0,0 +1,23 @@
+-- Роль приложения. Суперпользователь и роли с BYPASSRLS не подчиняются
+-- row-level security даже при FORCE, поэтому сервер подключается отдельной
+-- ролью входа из группы pvz_app: у неё есть права только на данные. Роль
+-- входа с паролем из POSTGRES_USER и POSTGRES_PASSWORD создаёт команда migrate.
+DO
+$$
+    BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pvz_app') THEN
+            CREATE ROLE pvz_app NOLOGIN NOSUPERUSER NOBYPASSRLS;
+        END IF;
+    END
+$$;
+
+GRANT USAGE ON SCHEMA public TO pvz_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO pvz_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO pvz_app;
+
+-- Таблицы и последовательности из следующих миграций получают те же права.
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO pvz_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO pvz_app;
+
+-- Список миграций приложение только читает для /readyz.
+REVOKE INSERT, UPDATE, DELETE ON schema_migrations FROM pvz_app;
@@ -0,0 +1,3 @@
+-- Момент последней смены пароля. Токены, выпущенные раньше, отклоняются:
+-- смена или сброс пароля завершает все прежние сессии пользователя.
+ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMPTZ;
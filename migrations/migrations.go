@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции в бинарник, чтобы команда
+// migrate не зависела от рабочего каталога.
+package migrations
+
+import "embed"
+
+// FS содержит файлы миграций; они применяются в порядке имён.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,11 +1,15 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"time"
+)
 
 type UserRegisterReq struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Role     string `json:"role"`
+	Tenant   string `json:"tenant,omitempty"`
 }
 
 type UserRegisterResp struct {
@@ -17,6 +21,33 @@ type UserRegisterResp struct {
 type UserLoginReq struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Tenant   string `json:"tenant,omitempty"`
+}
+
+type User struct {
+	ID              uuid.UUID  `json:"id"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+	// PasswordChangedAt — момент последней смены пароля; токены, выпущенные
+	// раньше, отозваны.
+	PasswordChangedAt *time.Time `json:"-"`
+}
+
+type TokenClaims struct {
+	UserID   uuid.UUID
+	Role     string
+	TenantID uuid.UUID
+	// IssuedAt — момент выпуска токена; нулевой у токенов, выпущенных до появления поля iat.
+	IssuedAt time.Time
+}
+
+// Tenant — оператор маркетплейса, которому принадлежат ПВЗ, пользователи и приёмки.
+type Tenant struct {
+	ID   uuid.UUID `json:"id"`
+	Slug string    `json:"slug"`
+	Name string    `json:"name"`
 }
 
 type UserLoginResp struct {
@@ -25,9 +56,54 @@ type UserLoginResp struct {
 }
 
 type DummyLoginRequest struct {
-	Role string `json:"role"`
+	Role   string `json:"role"`
+	Tenant string `json:"tenant,omitempty"`
 }
 
 type DummyLoginResponse struct {
 	Token string `json:"token"`
 }
+
+type LoginResponse struct {
+	Token string `json:"token"`
+}
+
+// Назначения одноразовых кодов, которые отправляются пользователю письмом.
+const (
+	UserTokenEmailVerification = "email_verification"
+	UserTokenPasswordReset     = "password_reset"
+)
+
+// UserToken — одноразовый код пользователя. Хранится только хеш кода.
+type UserToken struct {
+	UserID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type EmailVerificationReq struct {
+	Token  string `json:"token"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type ResendVerificationReq struct {
+	Email  string `json:"email"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type PasswordResetReq struct {
+	Email  string `json:"email"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type PasswordResetConfirmReq struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+	Tenant   string `json:"tenant,omitempty"`
+}
+
+type ChangePasswordReq struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+const (
+	EventProductExpired = "product.expired"
+	EventReceptionStale = "reception.stale"
+)
+
+type Event struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Payload    any       `json:"payload"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	ParcelStatusExpected       = "expected"
+	ParcelStatusReceiving      = "receiving"
+	ParcelStatusReadyForPickup = "ready_for_pickup"
+	ParcelStatusIssued         = "issued"
+	ParcelStatusReturned       = "returned_to_sender"
+	ParcelStatusExpired        = "storage_expired"
+)
+
+// LinkParcelRequest — привязка посылки клиентом. PickupCode должен совпасть
+// с кодом из манифеста курьера, иначе ПВЗ и приёмка посылки не показываются.
+type LinkParcelRequest struct {
+	Barcode    string `json:"barcode"`
+	PickupCode string `json:"pickupCode"`
+}
+
+type Parcel struct {
+	ID          uuid.UUID  `json:"id"`
+	Barcode     string     `json:"barcode"`
+	LinkedAt    time.Time  `json:"linkedAt"`
+	Status      string     `json:"status"`
+	ProductID   *uuid.UUID `json:"productId,omitempty"`
+	ProductType *string    `json:"productType,omitempty"`
+	ReceivedAt  *time.Time `json:"receivedAt,omitempty"`
+	ReceptionID *uuid.UUID `json:"receptionId,omitempty"`
+	ReadyAt     *time.Time `json:"readyAt,omitempty"`
+	IssuedAt    *time.Time `json:"issuedAt,omitempty"`
+	PVZ         *ParcelPVZ `json:"pvz,omitempty"`
+
+	ProductStatus   *string `json:"-"`
+	ReceptionStatus *string `json:"-"`
+}
+
+type ParcelPVZ struct {
+	ID       uuid.UUID `json:"id"`
+	City     string    `json:"city"`
+	Address  string    `json:"address"`
+	Timezone string    `json:"timezone"`
+}
@@ -5,10 +5,50 @@ import (
 	"time"
 )
 
+// DateLayout — формат календарной даты без времени в параметрах и отчётах.
+const DateLayout = "2006-01-02"
+
+const (
+	PVZStatusActive            = "active"
+	PVZStatusTemporarilyClosed = "temporarily_closed"
+	PVZStatusDecommissioned    = "decommissioned"
+)
+
 type PVZ struct {
-	ID               uuid.UUID `json:"id"`
-	RegistrationDate time.Time `json:"registrationDate"`
-	City             string    `json:"city"`
+	ID               uuid.UUID      `json:"id"`
+	RegistrationDate time.Time      `json:"registrationDate"`
+	City             string         `json:"city"`
+	Address          string         `json:"address"`
+	Latitude         *float64       `json:"latitude"`
+	Longitude        *float64       `json:"longitude"`
+	Timezone         string         `json:"timezone"`
+	WorkingHours     []WorkingHours `json:"workingHours"`
+	Status           string         `json:"status"`
+}
+
+// WorkingHours описывает часы работы ПВЗ в один день недели.
+// Weekday: 0 — воскресенье, 6 — суббота (как в time.Weekday).
+// Open и Close задаются в формате "15:04" по местному времени ПВЗ.
+type WorkingHours struct {
+	Weekday int    `json:"weekday"`
+	Open    string `json:"open"`
+	Close   string `json:"close"`
+}
+
+type UpdatePVZRequest struct {
+	Address      *string         `json:"address"`
+	Latitude     *float64        `json:"latitude"`
+	Longitude    *float64        `json:"longitude"`
+	Timezone     *string         `json:"timezone"`
+	WorkingHours *[]WorkingHours `json:"workingHours"`
+	Status       *string         `json:"status"`
+}
+
+// PVZImportItem — ПВЗ из файла импорта: город и параметры, которые
+// задаются сразу после заведения.
+type PVZImportItem struct {
+	City   string
+	Params UpdatePVZRequest
 }
 
 type PVZWithReceptions struct {
@@ -17,9 +57,72 @@ type PVZWithReceptions struct {
 	Products   []Product   `json:"products"`
 }
 
+// PVZFilterParams фильтрует приёмки по моменту (StartDate, EndDate) или по
+// календарным дням StartDay, EndDay в формате DateLayout. Дни отсчитываются
+// по местному времени каждого ПВЗ, EndDay включается целиком.
 type PVZFilterParams struct {
 	StartDate *time.Time `json:"startDate"`
 	EndDate   *time.Time `json:"endDate"`
+	StartDay  string     `json:"startDay,omitempty"`
+	EndDay    string     `json:"endDay,omitempty"`
 	Page      int        `json:"page"`
 	Limit     int        `json:"limit"`
 }
+
+// PVZPage — страница списка ПВЗ. HasNext сообщает, есть ли следующая страница.
+type PVZPage struct {
+	Items   []PVZWithReceptions `json:"items"`
+	HasNext bool                `json:"hasNext"`
+}
+
+// Pagination описывает страницу списка в ответах API v2. Count — число
+// элементов на этой странице, HasNext — есть ли следующая.
+type Pagination struct {
+	Page    int  `json:"page"`
+	Limit   int  `json:"limit"`
+	Count   int  `json:"count"`
+	HasNext bool `json:"hasNext"`
+}
+
+// ListResponse — список без постраничной выдачи в ответах API v2.
+// Total — число элементов в Items.
+type ListResponse[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+// PVZListResponse — ответ GET /v2/pvz.
+type PVZListResponse struct {
+	Items      []PVZWithReceptions `json:"items"`
+	Pagination Pagination          `json:"pagination"`
+}
+
+type NearbyPVZParams struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	RadiusKm  float64 `json:"radius"`
+	Limit     int     `json:"limit"`
+	OpenNow   bool    `json:"openNow"`
+}
+
+type PVZWithDistance struct {
+	PVZ
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// DailyReceptionStats — число приёмок и принятых товаров за один местный день ПВЗ.
+type DailyReceptionStats struct {
+	Date       string `json:"date"`
+	Receptions int    `json:"receptions"`
+	Products   int    `json:"products"`
+}
+
+// PVZDailyReport — приёмки ПВЗ по дням его часового пояса за период
+// от StartDate до EndDate включительно. Дни без приёмок тоже входят в отчёт.
+type PVZDailyReport struct {
+	PVZID     uuid.UUID             `json:"pvzId"`
+	Timezone  string                `json:"timezone"`
+	StartDate string                `json:"startDate"`
+	EndDate   string                `json:"endDate"`
+	Days      []DailyReceptionStats `json:"days"`
+}
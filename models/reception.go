@@ -2,24 +2,165 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
+const (
+	ReceptionStatusInProgress = "in_progress"
+	ReceptionStatusClose      = "close"
+	ReceptionStatusAbandoned  = "abandoned"
+)
+
+const (
+	ProductConditionOK      = "ok"
+	ProductConditionDamaged = "damaged"
+	ProductConditionOpened  = "opened"
+)
+
+const (
+	ProductStatusReceived = "received"
+	ProductStatusIssued   = "issued"
+	ProductStatusReturned = "returned"
+	ProductStatusExpired  = "expired"
+)
+
+// AddProductRequest — приёмка товара. Condition по умолчанию ok;
+// Note — свободное описание повреждений или вскрытия.
 type AddProductRequest struct {
-	Type  string    `json:"type"`
-	PVZID uuid.UUID `json:"pvzId"`
+	Type      string    `json:"type"`
+	PVZID     uuid.UUID `json:"pvzId"`
+	Barcode   string    `json:"barcode,omitempty"`
+	Condition string    `json:"condition,omitempty"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// ManifestItem — позиция манифеста, который курьер передаёт вместе с поставкой.
+// PickupCode — код получения, который отправитель сообщил клиенту. Сервис
+// хранит только PickupCodeHash и в ответах код не возвращает.
+type ManifestItem struct {
+	Barcode        string `json:"barcode"`
+	Type           string `json:"type"`
+	PickupCode     string `json:"pickupCode,omitempty"`
+	PickupCodeHash string `json:"-"`
+}
+
+type CreateReceptionRequest struct {
+	PVZID    uuid.UUID      `json:"pvzId"`
+	Manifest []ManifestItem `json:"manifest,omitempty"`
 }
 
 type Reception struct {
-	ID       uuid.UUID `json:"id"`
-	DateTime time.Time `json:"dateTime"`
-	PVZID    uuid.UUID `json:"pvzId"`
-	Status   string    `json:"status"`
+	ID          uuid.UUID  `json:"id"`
+	DateTime    time.Time  `json:"dateTime"`
+	PVZID       uuid.UUID  `json:"pvzId"`
+	Status      string     `json:"status"`
+	ClosedAt    *time.Time `json:"closedAt,omitempty"`
+	StaleAt     *time.Time `json:"staleAt,omitempty"`
+	CloseReason *string    `json:"closeReason,omitempty"`
+	ClosedBy    *uuid.UUID `json:"closedBy,omitempty"`
+
+	Manifest []ManifestItem `json:"manifest,omitempty"`
+}
+
+// TypeMismatch — товар из манифеста, принятый с другим типом.
+type TypeMismatch struct {
+	Barcode      string `json:"barcode"`
+	ExpectedType string `json:"expectedType"`
+	ActualType   string `json:"actualType"`
+}
+
+// DiscrepancyReport — результат сверки принятых товаров с манифестом приёмки.
+// Товары без штрихкода не могут быть сопоставлены с манифестом и попадают в Extra.
+type DiscrepancyReport struct {
+	ReceptionID  uuid.UUID      `json:"receptionId"`
+	Missing      []ManifestItem `json:"missing"`
+	Extra        []ManifestItem `json:"extra"`
+	Mismatched   []TypeMismatch `json:"mismatched"`
+	Acknowledged bool           `json:"acknowledged"`
+	CreatedAt    time.Time      `json:"createdAt"`
+}
+
+// ForceCloseReceptionRequest — принудительное закрытие зависшей приёмки модератором.
+// При Abandon приёмка получает статус abandoned, и её товары не становятся доступны к выдаче.
+type ForceCloseReceptionRequest struct {
+	Reason  string `json:"reason"`
+	Abandon bool   `json:"abandon"`
+}
+
+// ReopenReceptionRequest — переоткрытие ошибочно закрытой приёмки модератором.
+type ReopenReceptionRequest struct {
+	Reason string `json:"reason"`
 }
 
 type Product struct {
-	ID          uuid.UUID `json:"id"`
-	DateTime    time.Time `json:"dateTime"`
-	Type        string    `json:"type"`
-	ReceptionID uuid.UUID `json:"receptionId"`
+	ID          uuid.UUID  `json:"id"`
+	DateTime    time.Time  `json:"dateTime"`
+	Type        string     `json:"type"`
+	ReceptionID uuid.UUID  `json:"receptionId"`
+	Barcode     string     `json:"barcode,omitempty"`
+	Status      string     `json:"status"`
+	Condition   string     `json:"condition"`
+	Note        string     `json:"note,omitempty"`
+	IssuedAt    *time.Time `json:"issuedAt,omitempty"`
+	ExpiredAt   *time.Time `json:"expiredAt,omitempty"`
+}
+
+// DeletedProduct — товар, удалённый из открытой приёмки. Его можно восстановить,
+// пока приёмка не закрыта.
+type DeletedProduct struct {
+	Product
+	DeletedAt time.Time  `json:"deletedAt"`
+	DeletedBy *uuid.UUID `json:"deletedBy,omitempty"`
+}
+
+type ReceptionWithProducts struct {
+	Reception Reception `json:"reception"`
+	Products  []Product `json:"products"`
+}
+
+// ProductAttachment — метаданные фотографии товара. Сам файл лежит в
+// хранилище по ключу StorageKey.
+type ProductAttachment struct {
+	ID          uuid.UUID  `json:"id"`
+	ProductID   uuid.UUID  `json:"productId"`
+	StorageKey  string     `json:"-"`
+	FileName    string     `json:"fileName"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	UploadedBy  *uuid.UUID `json:"uploadedBy,omitempty"`
+	UploadedAt  time.Time  `json:"uploadedAt"`
+}
+
+// AttachmentUpload — файл, загружаемый к товару.
+type AttachmentUpload struct {
+	FileName    string
+	ContentType string
+	Size        int64
+	Content     io.Reader
+}
+
+type ExpiredProduct struct {
+	Product
+	PVZID uuid.UUID `json:"pvzId"`
+}
+
+type PVZStock struct {
+	PVZID        uuid.UUID      `json:"pvzId"`
+	Total        int            `json:"total"`
+	CountsByType map[string]int `json:"countsByType"`
+	Damaged      int            `json:"damaged"`
+	Opened       int            `json:"opened"`
+	Products     []Product      `json:"products"`
+}
+
+// PVZStockResponse — ответ GET /v2/pvz/{pvzId}/stock: товары лежат в Items,
+// как и в остальных списках API v2.
+type PVZStockResponse struct {
+	PVZID        uuid.UUID      `json:"pvzId"`
+	Items        []Product      `json:"items"`
+	Total        int            `json:"total"`
+	CountsByType map[string]int `json:"countsByType"`
+	Damaged      int            `json:"damaged"`
+	Opened       int            `json:"opened"`
 }
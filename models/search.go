@@ -0,0 +1,55 @@
+package models
+
+import "github.com/google/uuid"
+
+const (
+	SearchKindPVZ       = "pvz"
+	SearchKindReception = "reception"
+	SearchKindProduct   = "product"
+)
+
+// SearchParams — параметры поиска по ПВЗ, приёмкам и товарам.
+// Пустые фильтры не ограничивают выдачу. Если задан EmployeeID, ищется
+// только по ПВЗ, за которыми закреплён этот сотрудник.
+type SearchParams struct {
+	Query           string
+	Kind            string
+	City            string
+	ProductType     string
+	ReceptionStatus string
+	Limit           int
+	EmployeeID      *uuid.UUID
+}
+
+type SearchHit struct {
+	Kind            string    `json:"kind"`
+	ID              uuid.UUID `json:"id"`
+	PVZID           uuid.UUID `json:"pvzId"`
+	Title           string    `json:"title"`
+	City            string    `json:"city,omitempty"`
+	ProductType     string    `json:"productType,omitempty"`
+	ReceptionStatus string    `json:"receptionStatus,omitempty"`
+	Rank            float64   `json:"rank"`
+}
+
+// SearchFacets — количество найденного по значениям каждого фасета.
+type SearchFacets struct {
+	Kind            map[string]int `json:"kind"`
+	City            map[string]int `json:"city"`
+	ProductType     map[string]int `json:"productType"`
+	ReceptionStatus map[string]int `json:"receptionStatus"`
+}
+
+type SearchResult struct {
+	Total  int          `json:"total"`
+	Hits   []SearchHit  `json:"hits"`
+	Facets SearchFacets `json:"facets"`
+}
+
+// SearchResponse — ответ GET /v2/search: найденное лежит в Items, как и в
+// остальных списках API v2.
+type SearchResponse struct {
+	Items  []SearchHit  `json:"items"`
+	Total  int          `json:"total"`
+	Facets SearchFacets `json:"facets"`
+}